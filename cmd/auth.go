@@ -2,13 +2,19 @@ package cmd
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/chriskievit/dex-cli/internal/auth"
+	"github.com/chriskievit/dex-cli/internal/azdo"
 	"github.com/chriskievit/dex-cli/internal/config"
+	"github.com/chriskievit/dex-cli/internal/git"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
@@ -19,25 +25,53 @@ var authCmd = &cobra.Command{
 	Long:  "Manage Azure DevOps authentication credentials stored securely in your system keychain",
 }
 
+var (
+	tokenStdin  bool
+	tokenExpiry string
+	entraLogin  bool
+)
+
 var loginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Login to Azure DevOps",
-	Long:  "Store your Azure DevOps Personal Access Token (PAT) securely in the system keychain",
-	RunE:  runLogin,
+	Long: `Store your Azure DevOps Personal Access Token (PAT) securely in the system keychain.
+
+The PAT is prompted for without echo. For scripts, pipe it in with --token-stdin:
+
+  echo "$PAT" | dex --org myorg auth login --token-stdin
+
+To use a token without storing it, set DEX_TOKEN or AZURE_DEVOPS_EXT_PAT instead.
+
+Record the token's expiration date with --expires (a date such as 2026-12-31, or a
+number of days such as 30d) to be warned before it runs out. When prompting
+interactively, the expiration date is asked for as well.
+
+With --entra, sign in with a Microsoft Entra ID account instead of a PAT, using the
+device code flow: open the link shown in a browser and enter the code. Access tokens
+are refreshed automatically. Set entra_authority in the config to sign in to a
+specific tenant (https://login.microsoftonline.com/<tenant>), and entra_client_id to
+use your own application registration.`,
+	RunE: runLogin,
 }
 
 var logoutCmd = &cobra.Command{
 	Use:   "logout",
 	Short: "Logout from Azure DevOps",
-	Long:  "Remove your Azure DevOps credentials from the system keychain",
+	Long:  "Remove your Azure DevOps credentials (PAT and Entra ID sign-in) from the credential store",
 	RunE:  runLogout,
 }
 
+var verifyStatus bool
+
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Check authentication status",
-	Long:  "Check if you are authenticated with Azure DevOps",
-	RunE:  runStatus,
+	Long: `Check if you are authenticated with Azure DevOps.
+
+With --verify, the token is checked against the API: the authenticated user is shown
+and each capability dex uses (work items, code, pull requests and build) is probed with
+a read-only request, reporting any missing PAT scope.`,
+	RunE: runStatus,
 }
 
 func init() {
@@ -45,115 +79,544 @@ func init() {
 	authCmd.AddCommand(loginCmd)
 	authCmd.AddCommand(logoutCmd)
 	authCmd.AddCommand(statusCmd)
+
+	loginCmd.Flags().BoolVar(&tokenStdin, "token-stdin", false, "Read the token from standard input")
+	loginCmd.Flags().StringVar(&tokenExpiry, "expires", "", "Token expiration date (YYYY-MM-DD) or days until it expires (e.g. 30d)")
+	loginCmd.Flags().BoolVar(&entraLogin, "entra", false, "Sign in with Microsoft Entra ID (device code) instead of a PAT")
+	statusCmd.Flags().BoolVar(&verifyStatus, "verify", false, "Verify the token and its capabilities against the API")
 }
 
 func runLogin(cmd *cobra.Command, args []string) error {
+	if entraLogin && (tokenStdin || tokenExpiry != "") {
+		return fmt.Errorf("--entra cannot be combined with --token-stdin or --expires")
+	}
+
+	ctx := commandContext(cmd)
 	reader := bufio.NewReader(os.Stdin)
 
-	// Get organization
+	// Load config to resolve the selected profile and the server the organization lives on
+	cwd, _ := os.Getwd()
+	resolved, err := loadConfig(ctx, cwd)
+	if err != nil {
+		return err
+	}
+
+	// A profile determines its own organization; otherwise only --org skips the prompt
 	org := organization
+	if org == "" && resolved.Profile != "" {
+		org = resolved.Organization
+	}
 	if org == "" {
-		fmt.Print("Azure DevOps Organization: ")
-		input, err := reader.ReadString('\n')
-		if err != nil {
-			return fmt.Errorf("failed to read organization: %w", err)
+		suggested := ""
+		if cwd != "" {
+			if remote, err := git.GetAzureDevOpsRemote(ctx, cwd); err == nil {
+				suggested = remote.Organization
+			}
+		}
+
+		switch {
+		case tokenStdin:
+			// Standard input carries the token, so the organization cannot be prompted for
+			org = resolved.Organization
+			if org == "" {
+				org = suggested
+			}
+			if org == "" {
+				return fmt.Errorf("organization is required with --token-stdin. Use --org flag")
+			}
+		default:
+			if suggested != "" {
+				fmt.Printf("Azure DevOps Organization [%s]: ", suggested)
+			} else {
+				fmt.Print("Azure DevOps Organization: ")
+			}
+			input, err := reader.ReadString('\n')
+			if err != nil {
+				return fmt.Errorf("failed to read organization: %w", err)
+			}
+			org = strings.TrimSpace(input)
+			if org == "" {
+				org = suggested
+			}
 		}
-		org = strings.TrimSpace(input)
 	}
 
 	if org == "" {
 		return fmt.Errorf("organization is required")
 	}
 
-	// Get PAT (hidden input)
-	fmt.Print("Personal Access Token (PAT): ")
-	tokenBytes, err := term.ReadPassword(int(syscall.Stdin))
-	fmt.Println() // New line after password input
+	server := resolveServerURL(resolved.Config)
+	org = azdo.NormalizeOrganization(server, org)
+
+	if entraLogin {
+		return loginEntra(ctx, resolved, server, org)
+	}
+
+	// Parse the expiry up front so a typo does not waste the token prompt
+	var expires time.Time
+	if tokenExpiry != "" {
+		if expires, err = parseExpiry(tokenExpiry, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	token, err := readToken(cmd)
+	if err != nil {
+		return err
+	}
+
+	// Confirm the token works for the organization before storing it
+	client, err := newAPIClient(resolved.Config, server, org, token)
+	if err != nil {
+		return err
+	}
+	connection, err := client.GetConnectionData(ctx)
+	if err != nil {
+		return fmt.Errorf("token validation failed for organization %s: %w", org, err)
+	}
+
+	// Store token in the configured credential store
+	store, err := credentialStore(resolved.Config)
+	if err != nil {
+		return err
+	}
+	// Logging in with a profile (re)binds it to org, so the token is the profile's
+	account := auth.Account{Organization: org, Profile: resolved.Profile, Server: server}
+	if err := auth.StoreToken(store, account, token); err != nil {
+		if _, ok := store.(auth.KeyringStore); ok {
+			return fmt.Errorf("failed to store credentials: %w\nIf no keychain is available, run 'dex config set credential-store file' to use an encrypted file instead", err)
+		}
+		return fmt.Errorf("failed to store credentials: %w", err)
+	}
+
+	// Ask for the expiry when prompting interactively; it cannot be discovered with a PAT
+	if tokenExpiry == "" && !tokenStdin {
+		if expires, err = promptExpiry(reader); err != nil {
+			return err
+		}
+	}
+	metadata := auth.TokenMetadata{StoredAt: time.Now(), ExpiresAt: expires}
+	if err := tokenMetadata().Set(account, metadata); err != nil {
+		return err
+	}
+
+	// A stored Entra ID sign-in would take precedence over the new PAT
+	if err := auth.DeleteEntraToken(store, account); err != nil && !errors.Is(err, auth.ErrNoCredentials) {
+		return err
+	}
+
+	// Remember the organization (and server, when given explicitly) in the profile or global config
+	if err := saveLoginTarget(resolved.Profile, org); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Successfully authenticated with organization: %s\n", org)
+	fmt.Printf("  User: %s\n", formatIdentity(&connection.AuthenticatedUser))
+	if resolved.Profile != "" {
+		fmt.Printf("  Profile: %s\n", resolved.Profile)
+	}
+	if server != "" {
+		fmt.Printf("  Server: %s\n", azdo.NormalizeServerURL(server))
+	}
+	if metadata.HasExpiry() {
+		fmt.Printf("  Token expires: %s\n", formatExpiry(metadata, time.Now()))
+	}
+	fmt.Printf("Your credentials are stored securely in the %s\n", store.Name())
+
+	return nil
+}
+
+// loginEntra signs in to org with a Microsoft Entra ID account using the device code flow
+// and stores the resulting refresh token in place of any PAT
+func loginEntra(ctx context.Context, resolved *config.Resolved, server, org string) error {
+	entra := auth.NewEntraClient(resolved.EntraAuthority, resolved.EntraClientID)
+	code, err := entra.RequestDeviceCode(ctx)
+	if err != nil {
+		return err
+	}
+	if code.Message != "" {
+		fmt.Println(code.Message)
+	} else {
+		fmt.Printf("To sign in, open %s and enter the code %s\n", code.VerificationURI, code.UserCode)
+	}
+	fmt.Println("Waiting for sign-in...")
+
+	token, err := entra.PollToken(ctx, code)
+	if err != nil {
+		return fmt.Errorf("Entra ID sign-in failed: %w", err)
+	}
+
+	store, err := credentialStore(resolved.Config)
+	if err != nil {
+		return err
+	}
+	account := auth.Account{Organization: org, Profile: resolved.Profile, Server: server}
+	if err := auth.StoreEntraToken(store, account, token); err != nil {
+		return fmt.Errorf("failed to store credentials: %w", err)
+	}
+
+	// Confirm Azure DevOps accepts the account, and forget the sign-in if it does not
+	tokens := auth.NewEntraTokenSource(store, account, token)
+	client, err := newBearerAPIClient(resolved.Config, server, org, tokens)
+	if err != nil {
+		return err
+	}
+	connection, err := client.GetConnectionData(ctx)
+	if err != nil {
+		if deleteErr := auth.DeleteEntraToken(store, account); deleteErr != nil && debug {
+			fmt.Printf("Note: %v\n", deleteErr)
+		}
+		return fmt.Errorf("token validation failed for organization %s: %w", org, err)
+	}
+
+	// The sign-in replaces any PAT stored for the account
+	if err := store.Delete(account); err != nil && !errors.Is(err, auth.ErrNoCredentials) {
+		return err
+	}
+	if err := tokenMetadata().Delete(account); err != nil {
+		return err
+	}
+
+	if err := saveLoginTarget(resolved.Profile, org); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Successfully signed in to organization: %s\n", org)
+	fmt.Printf("  User: %s\n", formatIdentity(&connection.AuthenticatedUser))
+	if resolved.Profile != "" {
+		fmt.Printf("  Profile: %s\n", resolved.Profile)
+	}
+	if server != "" {
+		fmt.Printf("  Server: %s\n", azdo.NormalizeServerURL(server))
+	}
+	fmt.Printf("Your refresh token is stored securely in the %s\n", store.Name())
+
+	return nil
+}
+
+// formatIdentity formats an identity as "Name <account>"
+func formatIdentity(identity *azdo.Identity) string {
+	name, account := identity.DisplayName(), identity.Account()
+	switch {
+	case account == "" || account == name:
+		return name
+	case name == "":
+		return account
+	}
+	return fmt.Sprintf("%s <%s>", name, account)
+}
+
+// verifyToken checks the client's credentials against the API and probes every capability
+// dex uses. Failed checks are reported, not returned; only a cancelled context is an error.
+func verifyToken(ctx context.Context, client *azdo.Client, proj string) error {
+	fmt.Println("\nVerifying token...")
+	connection, err := client.GetConnectionData(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to read token: %w", err)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		fmt.Printf("✗ Token is not valid: %s\n", describeError(err))
+		return nil
+	}
+	fmt.Printf("✓ Token is valid for %s\n", formatIdentity(&connection.AuthenticatedUser))
+
+	if proj != "" {
+		fmt.Printf("Capabilities (project %s):\n", proj)
+	} else {
+		fmt.Println("Capabilities:")
+	}
+	for _, capability := range azdo.Capabilities {
+		if capability.NeedsProject && proj == "" {
+			fmt.Printf("  - %-14s skipped (no project configured; use --project)\n", capability.Name)
+			continue
+		}
+
+		err := client.CheckCapability(ctx, proj, capability)
+		switch {
+		case err == nil:
+			fmt.Printf("  ✓ %s\n", capability.Name)
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case azdo.IsUnauthorized(err) || azdo.IsForbidden(err):
+			scope := ""
+			if apiErr, ok := azdo.AsAPIError(err); ok && apiErr.RequiredScope() != "" {
+				scope = ": missing PAT scope " + apiErr.RequiredScope()
+			}
+			fmt.Printf("  ✗ %s (access denied%s)\n", capability.Name, scope)
+		default:
+			fmt.Printf("  ✗ %s (%s)\n", capability.Name, describeError(err))
+		}
+	}
+
+	return nil
+}
+
+// promptExpiry asks for the token's expiration date, which may be left blank
+func promptExpiry(reader *bufio.Reader) (time.Time, error) {
+	for {
+		fmt.Print("Token expiration date (YYYY-MM-DD or e.g. 30d, blank if unknown): ")
+		input, err := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if input == "" {
+			if err != nil && err != io.EOF {
+				return time.Time{}, fmt.Errorf("failed to read expiration date: %w", err)
+			}
+			return time.Time{}, nil
+		}
+
+		expires, parseErr := parseExpiry(input, time.Now())
+		if parseErr == nil {
+			return expires, nil
+		}
+		fmt.Println(parseErr)
+		if err != nil {
+			return time.Time{}, parseErr
+		}
+	}
+}
+
+// describeExpiry describes when the stored token for account expires
+func describeExpiry(account auth.Account) string {
+	metadata, ok, err := tokenMetadata().Get(account)
+	if err != nil || !ok || !metadata.HasExpiry() {
+		return "unknown (record it with 'dex auth login --expires')"
+	}
+	return formatExpiry(metadata, time.Now())
+}
+
+// formatExpiry formats a token's expiry with the days remaining
+func formatExpiry(metadata auth.TokenMetadata, now time.Time) string {
+	date := metadata.ExpiresAt.Format(expiryDateLayout)
+	days := metadata.DaysRemaining(now)
+	if days < 0 {
+		return fmt.Sprintf("%s (expired)", date)
+	}
+	return fmt.Sprintf("%s (%s remaining)", date, formatDays(days))
+}
+
+// readToken reads the PAT from standard input with --token-stdin, and otherwise
+// prompts for it without echo, which requires a terminal
+func readToken(cmd *cobra.Command) (string, error) {
+	var tokenBytes []byte
+	if tokenStdin {
+		data, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return "", fmt.Errorf("failed to read token from standard input: %w", err)
+		}
+		tokenBytes = data
+	} else {
+		fd := int(syscall.Stdin)
+		if !term.IsTerminal(fd) {
+			return "", fmt.Errorf("cannot prompt for a token without a terminal. Use --token-stdin, or set %s to skip login", auth.TokenEnvVar)
+		}
+
+		// Get PAT (hidden input)
+		fmt.Print("Personal Access Token (PAT): ")
+		data, err := term.ReadPassword(fd)
+		fmt.Println() // New line after password input
+		if err != nil {
+			return "", fmt.Errorf("failed to read token: %w", err)
+		}
+		tokenBytes = data
 	}
 
 	token := strings.TrimSpace(string(tokenBytes))
 	if token == "" {
-		return fmt.Errorf("token is required")
+		return "", fmt.Errorf("token is required")
 	}
+	return token, nil
+}
 
-	// Store token in keychain
-	if err := auth.StoreToken(org, token); err != nil {
-		return fmt.Errorf("failed to store credentials: %w", err)
+// saveLoginTarget stores the organization logged in to, and the --server flag when given,
+// in the named profile, or in the global config when no profile is in use
+func saveLoginTarget(profile, org string) error {
+	if profile != "" {
+		profiles, err := config.LoadProfiles()
+		if err != nil {
+			return err
+		}
+		p := profiles[profile]
+		p.Organization = org
+		if serverURL != "" {
+			p.ServerURL = azdo.NormalizeServerURL(serverURL)
+		}
+		profiles[profile] = p
+		return config.SaveProfiles(profiles)
 	}
 
-	// Update config with organization
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
-
 	cfg.Organization = org
+	if serverURL != "" {
+		cfg.ServerURL = azdo.NormalizeServerURL(serverURL)
+	}
 	if err := config.Save(cfg); err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
-
-	fmt.Printf("✓ Successfully authenticated with organization: %s\n", org)
-	fmt.Println("Your credentials are stored securely in the system keychain")
-
 	return nil
 }
 
 func runLogout(cmd *cobra.Command, args []string) error {
-	cfg, err := config.Load()
+	// Repository config files may select a different organization
+	cwd, _ := os.Getwd()
+	cfg, err := loadConfig(commandContext(cmd), cwd)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
-	org := organization
-	if org == "" {
-		org = cfg.Organization
-	}
+	org := cfg.Organization
 
 	if org == "" {
 		return fmt.Errorf("no organization configured. Use --org flag or login first")
 	}
+	org = azdo.NormalizeOrganization(resolveServerURL(cfg.Config), org)
 
-	if err := auth.DeleteToken(org); err != nil {
+	store, err := credentialStore(cfg.Config)
+	if err != nil {
+		return err
+	}
+	account := credentialAccount(cfg.Config, org)
+	entraErr := auth.DeleteEntraToken(store, account)
+	if entraErr != nil && !errors.Is(entraErr, auth.ErrNoCredentials) {
+		return fmt.Errorf("failed to logout: %w", entraErr)
+	}
+	// Only report missing credentials when there was no Entra ID sign-in either
+	if err := auth.DeleteToken(store, account); err != nil && (entraErr != nil || !errors.Is(err, auth.ErrNoCredentials)) {
 		return fmt.Errorf("failed to logout: %w", err)
 	}
 
-	fmt.Printf("✓ Successfully logged out from organization: %s\n", org)
+	if err := tokenMetadata().Delete(account); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Successfully logged out from %s\n", account)
 
 	return nil
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
-	cfg, err := config.Load()
+	// Repository config files may select a different organization
+	cwd, _ := os.Getwd()
+	cfg, err := loadConfig(commandContext(cmd), cwd)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
-	org := organization
-	if org == "" {
-		org = cfg.Organization
+	store, err := credentialStore(cfg.Config)
+	if err != nil {
+		return err
 	}
 
+	org := cfg.Organization
+
 	if org == "" {
 		fmt.Println("✗ Not authenticated")
 		fmt.Println("Run 'dex-cli auth login' to authenticate")
-		return nil
+	} else {
+		server := resolveServerURL(cfg.Config)
+		org = azdo.NormalizeOrganization(server, org)
+		account := credentialAccount(cfg.Config, org)
+
+		source, err := credentialSource(store, account)
+		if err != nil {
+			fmt.Printf("✗ Not authenticated with organization: %s\n", org)
+			if !errors.Is(err, auth.ErrNoCredentials) {
+				fmt.Printf("  %v\n", err)
+			}
+			fmt.Println("Run 'dex-cli auth login' to authenticate")
+		} else {
+			fmt.Printf("✓ Authenticated with organization: %s\n", org)
+			fmt.Printf("  Token source: %s\n", source)
+			if source == store.Name() {
+				fmt.Printf("  Token expires: %s\n", describeExpiry(account))
+			}
+			if cfg.Profile != "" {
+				fmt.Printf("  Profile: %s\n", cfg.Profile)
+			}
+			if server != "" {
+				fmt.Printf("  Server: %s\n", azdo.NormalizeServerURL(server))
+			}
+			if cfg.Project != "" {
+				fmt.Printf("  Default project: %s\n", cfg.Project)
+			}
+			if cfg.Repository != "" {
+				fmt.Printf("  Default repository: %s\n", cfg.Repository)
+			}
+
+			if verifyStatus {
+				client, err := authenticatedClient(cfg.Config, server, org)
+				if err != nil {
+					return err
+				}
+				t := resolveTarget(commandContext(cmd), cfg.Config, cwd)
+				if err := verifyToken(commandContext(cmd), client, t.Project); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return printProfileStatus(store, cfg.Profile)
+}
+
+// credentialSource describes the credentials authenticatedClient uses for account:
+// a token from the environment, then an Entra ID sign-in, then a stored PAT
+func credentialSource(store auth.CredentialStore, account auth.Account) (string, error) {
+	if cred, err := auth.EnvironmentChain().Credential(account); err == nil {
+		return cred.Source, nil
 	}
 
-	_, err = auth.GetToken(org, debug)
+	tokens, err := auth.LoadEntraTokenSource(store, account)
+	if err == nil {
+		return tokens.Name(), nil
+	}
+	if !errors.Is(err, auth.ErrNoCredentials) {
+		return "", err
+	}
+
+	cred, err := auth.DefaultChain(store).Credential(account)
 	if err != nil {
-		fmt.Printf("✗ Not authenticated with organization: %s\n", org)
-		fmt.Println("Run 'dex-cli auth login' to authenticate")
-		return nil
+		return "", err
 	}
+	return cred.Source, nil
+}
 
-	fmt.Printf("✓ Authenticated with organization: %s\n", org)
-	if cfg.Project != "" {
-		fmt.Printf("  Default project: %s\n", cfg.Project)
+// printProfileStatus lists every profile with whether credentials are available for it
+func printProfileStatus(store auth.CredentialStore, active string) error {
+	profiles, err := config.LoadProfiles()
+	if err != nil {
+		return err
 	}
-	if cfg.Repository != "" {
-		fmt.Printf("  Default repository: %s\n", cfg.Repository)
+	if len(profiles) == 0 {
+		return nil
+	}
+
+	fmt.Println("\nProfiles:")
+	for _, name := range config.ProfileNames(profiles) {
+		p := profiles[name]
+
+		marker := " "
+		if name == active {
+			marker = "*"
+		}
+
+		state := "✗ no organization"
+		if p.Organization != "" {
+			account := auth.Account{Organization: p.Organization, Profile: name, Server: p.ServerURL}
+			source, err := credentialSource(store, account)
+			if err != nil {
+				state = "✗ not authenticated"
+			} else {
+				state = "✓ authenticated (" + source + ")"
+				if source == store.Name() {
+					state += ", expires " + describeExpiry(account)
+				}
+			}
+		}
+
+		fmt.Printf("%s %-20s %-25s %s\n", marker, name, formatValue(p.Organization), state)
 	}
 
 	return nil
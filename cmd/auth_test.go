@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chriskievit/dex-cli/internal/auth"
+	"github.com/chriskievit/dex-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupAuthTest isolates the config and returns the in-memory credential store in use
+func setupAuthTest(t *testing.T) auth.CredentialStore {
+	t.Helper()
+
+	setupProfileTest(t)
+
+	oldTokenStdin, oldTokenExpiry, oldEntraLogin := tokenStdin, tokenExpiry, entraLogin
+	t.Cleanup(func() {
+		tokenStdin, tokenExpiry, entraLogin = oldTokenStdin, oldTokenExpiry, oldEntraLogin
+		loginCmd.SetIn(nil)
+	})
+
+	t.Setenv(auth.TokenEnvVar, "")
+	t.Setenv(auth.AzureDevOpsTokenEnvVar, "")
+
+	return credentialStoreOverride
+}
+
+// newAuthTestServer serves connectionData for validToken, as a PAT or a bearer token,
+// and rejects every other token
+func newAuthTestServer(t *testing.T, validToken string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, password, _ := r.BasicAuth()
+		if password != validToken && r.Header.Get("Authorization") != "Bearer "+validToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/myorg/_apis/connectionData", "/contoso/_apis/connectionData":
+			w.Write([]byte(`{"authenticatedUser": {"id": "8c8c7d32", "providerDisplayName": "Jane Doe",
+				"properties": {"Account": {"$type": "System.String", "$value": "jane@contoso.com"}}}}`))
+		case "/myorg/_apis/git/repositories":
+			w.Write([]byte(`{"value": []}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestReadToken_Stdin(t *testing.T) {
+	setupAuthTest(t)
+	tokenStdin = true
+
+	loginCmd.SetIn(strings.NewReader("  my-personal-access-token-value\n"))
+	token, err := readToken(loginCmd)
+	require.NoError(t, err)
+	assert.Equal(t, "my-personal-access-token-value", token)
+
+	loginCmd.SetIn(strings.NewReader("\n"))
+	_, err = readToken(loginCmd)
+	assert.Error(t, err)
+}
+
+func TestRunLogin_TokenStdin(t *testing.T) {
+	store := setupAuthTest(t)
+	tokenStdin = true
+	organization = "myorg"
+	serverURL = newAuthTestServer(t, "my-personal-access-token-value").URL
+
+	// Tokens the server rejects are not stored
+	loginCmd.SetIn(strings.NewReader("rejected-personal-access-token\n"))
+	err := runLogin(loginCmd, []string{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "token validation failed")
+	_, err = store.Token(auth.Account{Organization: "myorg", Server: serverURL})
+	assert.ErrorIs(t, err, auth.ErrNoCredentials)
+
+	loginCmd.SetIn(strings.NewReader("my-personal-access-token-value\n"))
+	err = runLogin(loginCmd, []string{})
+	require.NoError(t, err)
+
+	token, err := store.Token(auth.Account{Organization: "myorg", Server: serverURL})
+	require.NoError(t, err)
+	assert.Equal(t, "my-personal-access-token-value", token)
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "myorg", cfg.Organization)
+
+	require.NoError(t, runStatus(statusCmd, []string{}))
+
+	oldVerify := verifyStatus
+	defer func() { verifyStatus = oldVerify }()
+	verifyStatus = true
+	require.NoError(t, runStatus(statusCmd, []string{}))
+
+	require.NoError(t, runLogout(logoutCmd, []string{}))
+	_, err = store.Token(auth.Account{Organization: "myorg", Server: serverURL})
+	assert.ErrorIs(t, err, auth.ErrNoCredentials)
+
+	// Logging out twice reports the missing credentials
+	assert.Error(t, runLogout(logoutCmd, []string{}))
+}
+
+func TestRunLogin_Profile(t *testing.T) {
+	store := setupAuthTest(t)
+	require.NoError(t, config.SaveProfiles(map[string]config.Profile{"client-a": {Organization: "contoso"}}))
+	tokenStdin = true
+	profileName = "client-a"
+	serverURL = newAuthTestServer(t, "profile-personal-access-token").URL
+
+	loginCmd.SetIn(strings.NewReader("profile-personal-access-token\n"))
+	err := runLogin(loginCmd, []string{})
+	require.NoError(t, err)
+
+	// The token is stored under the profile, not the organization
+	token, err := store.Token(auth.Account{Organization: "contoso", Profile: "client-a"})
+	require.NoError(t, err)
+	assert.Equal(t, "profile-personal-access-token", token)
+	_, err = store.Token(auth.Account{Organization: "contoso"})
+	assert.ErrorIs(t, err, auth.ErrNoCredentials)
+
+	cfg, err := loadConfig(commandContext(loginCmd), "")
+	require.NoError(t, err)
+	resolved, err := getToken(cfg.Config, cfg.Organization)
+	require.NoError(t, err)
+	assert.Equal(t, "profile-personal-access-token", resolved)
+}
+
+func TestGetToken_EnvironmentOverridesStore(t *testing.T) {
+	store := setupAuthTest(t)
+	require.NoError(t, store.Store(auth.Account{Organization: "myorg"}, "stored-token"))
+
+	token, err := getToken(&config.Config{}, "myorg")
+	require.NoError(t, err)
+	assert.Equal(t, "stored-token", token)
+
+	t.Setenv(auth.AzureDevOpsTokenEnvVar, "env-token")
+	token, err = getToken(&config.Config{}, "myorg")
+	require.NoError(t, err)
+	assert.Equal(t, "env-token", token)
+}
+
+func TestRunLogin_Expires(t *testing.T) {
+	setupAuthTest(t)
+	tokenStdin = true
+	organization = "myorg"
+	serverURL = newAuthTestServer(t, "my-personal-access-token-value").URL
+	account := auth.Account{Organization: "myorg", Server: serverURL}
+
+	tokenExpiry = "yesterday"
+	loginCmd.SetIn(strings.NewReader("my-personal-access-token-value\n"))
+	require.Error(t, runLogin(loginCmd, []string{}))
+
+	tokenExpiry = "30d"
+	loginCmd.SetIn(strings.NewReader("my-personal-access-token-value\n"))
+	require.NoError(t, runLogin(loginCmd, []string{}))
+
+	metadata, ok, err := tokenMetadata().Get(account)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, 29, metadata.DaysRemaining(time.Now()))
+
+	require.NoError(t, runStatus(statusCmd, []string{}))
+
+	require.NoError(t, runLogout(logoutCmd, []string{}))
+	_, ok, err = tokenMetadata().Get(account)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestParseExpiry(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "days", value: "30d", want: now.AddDate(0, 0, 30)},
+		{name: "date is inclusive", value: "2026-04-01", want: time.Date(2026, 4, 1, 23, 59, 59, 0, time.UTC)},
+		{name: "today", value: "2026-03-10", want: time.Date(2026, 3, 10, 23, 59, 59, 0, time.UTC)},
+		{name: "past date", value: "2026-03-09", wantErr: true},
+		{name: "zero days", value: "0d", wantErr: true},
+		{name: "invalid", value: "next month", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseExpiry(tt.value, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "got %s, want %s", got, tt.want)
+		})
+	}
+}
+
+func TestWarnTokenExpiry(t *testing.T) {
+	setupProfileTest(t)
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	account := auth.Account{Organization: "myorg"}
+
+	tests := []struct {
+		name    string
+		expires time.Time
+		window  int
+		want    string
+	}{
+		{name: "outside default window", expires: now.AddDate(0, 0, 20)},
+		{name: "inside default window", expires: now.AddDate(0, 0, 5), want: "expires in 5 days (2026-03-15)"},
+		{name: "inside configured window", expires: now.AddDate(0, 0, 20), window: 30, want: "expires in 20 days"},
+		{name: "expired", expires: now.AddDate(0, 0, -2), want: "expired on 2026-03-08"},
+		{name: "disabled", expires: now.AddDate(0, 0, -2), window: -1},
+		{name: "unknown expiry"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tokenMetadata().Set(account, auth.TokenMetadata{StoredAt: now, ExpiresAt: tt.expires}))
+
+			var out bytes.Buffer
+			warnTokenExpiry(&out, &config.Config{ExpiryWarningDays: tt.window}, account, now)
+			if tt.want == "" {
+				assert.Empty(t, out.String())
+			} else {
+				assert.Contains(t, out.String(), tt.want)
+			}
+		})
+	}
+}
+
+// newEntraTestAuthority approves every device code immediately, issuing accessToken
+func newEntraTestAuthority(t *testing.T, accessToken string) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tenant/oauth2/v2.0/devicecode", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"device_code": "device-123", "user_code": "ABCD-EFGH",
+			"verification_uri": "https://microsoft.com/devicelogin", "expires_in": 900, "interval": 5}`))
+	})
+	mux.HandleFunc("/tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"access_token": %q, "refresh_token": "refresh-token", "expires_in": 3600}`, accessToken)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRunLogin_Entra(t *testing.T) {
+	store := setupAuthTest(t)
+	organization = "myorg"
+	serverURL = newAuthTestServer(t, "entra-access-token").URL
+	t.Setenv(config.EnvVar("entra_authority"), newEntraTestAuthority(t, "entra-access-token").URL+"/tenant")
+	account := auth.Account{Organization: "myorg", Server: serverURL}
+
+	// A PAT stored earlier is replaced by the sign-in
+	require.NoError(t, auth.StoreToken(store, account, "my-personal-access-token-value"))
+
+	entraLogin = true
+	tokenStdin = true
+	assert.Error(t, runLogin(loginCmd, []string{}))
+	tokenStdin = false
+	require.NoError(t, runLogin(loginCmd, []string{}))
+
+	_, err := store.Token(account)
+	assert.ErrorIs(t, err, auth.ErrNoCredentials)
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "myorg", cfg.Organization)
+
+	client, err := authenticatedClient(cfg, serverURL, "myorg")
+	require.NoError(t, err)
+	connection, err := client.GetConnectionData(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", connection.AuthenticatedUser.DisplayName())
+
+	source, err := credentialSource(store, account)
+	require.NoError(t, err)
+	assert.Equal(t, "Entra ID sign-in (in-memory store)", source)
+
+	// A token in the environment takes precedence over the sign-in
+	t.Setenv(auth.TokenEnvVar, "env-token-value-that-is-long-enough")
+	source, err = credentialSource(store, account)
+	require.NoError(t, err)
+	assert.Equal(t, "environment variable DEX_TOKEN", source)
+	t.Setenv(auth.TokenEnvVar, "")
+
+	require.NoError(t, runStatus(statusCmd, []string{}))
+
+	require.NoError(t, runLogout(logoutCmd, []string{}))
+	_, err = credentialSource(store, account)
+	assert.ErrorIs(t, err, auth.ErrNoCredentials)
+	assert.Error(t, runLogout(logoutCmd, []string{}))
+}
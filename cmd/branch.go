@@ -5,14 +5,16 @@ import (
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
 
-	"github.com/chriskievit/dex-cli/internal/auth"
 	"github.com/chriskievit/dex-cli/internal/azdo"
-	"github.com/chriskievit/dex-cli/internal/config"
 	"github.com/chriskievit/dex-cli/internal/git"
 	"github.com/spf13/cobra"
 )
 
+// defaultBranchFormat is the branch naming convention used when branch_format is not configured
+const defaultBranchFormat = "{type}/{id}/{description}"
+
 var (
 	fromBranch string
 )
@@ -28,6 +30,9 @@ var createBranchCmd = &cobra.Command{
 	Short: "Create a new branch linked to a work item",
 	Long: `Create a new Git branch following the naming convention: {work-item-type}/{work-item-id}/{description}
 
+The convention can be changed with branch_format in the config, for example
+"{type}/{id}-{description}" in a repository's .dex.yaml.
+
 Example:
   dex-cli branch create 12345 add-login-feature
   dex-cli branch create 12345 fix-bug --from develop
@@ -45,6 +50,8 @@ func init() {
 }
 
 func runCreateBranch(cmd *cobra.Command, args []string) error {
+	ctx := commandContext(cmd)
+
 	workItemIDStr := args[0]
 	description := args[1]
 
@@ -65,40 +72,38 @@ func runCreateBranch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get current directory: %w", err)
 	}
 
-	if !git.IsGitRepository(cwd) {
+	if !git.IsGitRepository(ctx, cwd) {
 		return fmt.Errorf("not a git repository. Please run this command from within a git repository")
 	}
 
 	// Load config
-	cfg, err := config.Load()
+	cfg, err := loadConfig(ctx, cwd)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
-	org := organization
-	if org == "" {
-		org = cfg.Organization
-	}
+	t := resolveTarget(ctx, cfg.Config, cwd)
+	server, org := t.Server, t.Organization
 	if org == "" {
 		return fmt.Errorf("organization not configured. Use --org flag or run 'dex-cli auth login'")
 	}
 
-	// Get authentication token
-	token, err := auth.GetToken(org, debug)
+	// Create an Azure DevOps client with the credentials in effect
+	client, err := authenticatedClient(cfg.Config, server, org)
 	if err != nil {
 		return err
 	}
 
-	// Create Azure DevOps client
-	client := azdo.NewClient(org, token, debug)
-
 	// Fetch work item to get the type
 	if debug {
 		fmt.Printf("Fetching work item %d...\n", workItemID)
 	}
 
-	workItem, err := client.GetWorkItem(workItemID)
+	workItem, err := client.GetWorkItem(ctx, workItemID)
 	if err != nil {
+		if azdo.IsNotFound(err) {
+			return fmt.Errorf("work item #%d does not exist: %w", workItemID, err)
+		}
 		return fmt.Errorf("failed to fetch work item: %w", err)
 	}
 
@@ -109,11 +114,14 @@ func runCreateBranch(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Work Item: %s #%d - %s\n", workItemType, workItemID, workItemTitle)
 	}
 
-	// Generate branch name: {type}/{id}/{description}
-	branchName := fmt.Sprintf("%s/%d/%s", workItemType, workItemID, description)
+	// Generate branch name following the configured convention
+	branchName, err := formatBranchName(cfg.BranchFormat, workItemType, workItemID, description)
+	if err != nil {
+		return err
+	}
 
 	// Check if branch already exists
-	exists, err := git.BranchExists(cwd, branchName)
+	exists, err := git.BranchExists(ctx, cwd, branchName)
 	if err != nil {
 		return fmt.Errorf("failed to check if branch exists: %w", err)
 	}
@@ -124,7 +132,7 @@ func runCreateBranch(cmd *cobra.Command, args []string) error {
 	// Determine base branch
 	baseBranch := fromBranch
 	if baseBranch == "" {
-		baseBranch, err = git.GetDefaultBranch(cwd)
+		baseBranch, err = git.GetDefaultBranch(ctx, cwd)
 		if err != nil {
 			return fmt.Errorf("failed to determine default branch: %w", err)
 		}
@@ -132,7 +140,7 @@ func runCreateBranch(cmd *cobra.Command, args []string) error {
 
 	// Create the branch
 	fmt.Printf("Creating branch: %s (from %s)\n", branchName, baseBranch)
-	if err := git.CreateBranch(cwd, branchName, baseBranch); err != nil {
+	if err := git.CreateBranch(ctx, cwd, branchName, baseBranch); err != nil {
 		return fmt.Errorf("failed to create branch: %w", err)
 	}
 
@@ -148,3 +156,45 @@ func isValidDescription(desc string) bool {
 	matched, _ := regexp.MatchString(`^[a-z0-9]+(-[a-z0-9]+)*$`, desc)
 	return matched
 }
+
+// formatBranchName builds a branch name from a branch format with the {type}, {id}
+// and {description} placeholders. An empty format uses defaultBranchFormat.
+func formatBranchName(format, workItemType string, id int, description string) (string, error) {
+	if format == "" {
+		format = defaultBranchFormat
+	}
+	if !strings.Contains(format, "{id}") {
+		return "", fmt.Errorf("invalid branch_format %q: must contain {id}", format)
+	}
+
+	replacer := strings.NewReplacer(
+		"{type}", workItemType,
+		"{id}", strconv.Itoa(id),
+		"{description}", description,
+	)
+	return replacer.Replace(format), nil
+}
+
+// workItemIDFromBranch extracts the work item ID from a branch name created with
+// the given branch format, falling back to the default convention. It returns 0
+// when the branch does not match.
+func workItemIDFromBranch(branchName, format string) int {
+	if format != "" && format != defaultBranchFormat && strings.Contains(format, "{id}") {
+		pattern := regexp.QuoteMeta(format)
+		pattern = strings.NewReplacer(
+			regexp.QuoteMeta("{type}"), `[^/]+`,
+			regexp.QuoteMeta("{id}"), `(\d+)`,
+			regexp.QuoteMeta("{description}"), `.+`,
+		).Replace(pattern)
+
+		if re, err := regexp.Compile("^" + pattern + "$"); err == nil {
+			if matches := re.FindStringSubmatch(branchName); len(matches) > 1 {
+				if id, err := strconv.Atoi(matches[1]); err == nil {
+					return id
+				}
+			}
+		}
+	}
+
+	return extractWorkItemFromBranch(branchName)
+}
@@ -106,3 +106,86 @@ func TestIsValidDescription(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatBranchName(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "default format",
+			format:   "",
+			expected: "Bug/12345/fix-login",
+		},
+		{
+			name:     "custom format",
+			format:   "users/jdoe/{id}-{description}",
+			expected: "users/jdoe/12345-fix-login",
+		},
+		{
+			name:        "format without id",
+			format:      "{type}/{description}",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := formatBranchName(tt.format, "Bug", 12345, "fix-login")
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestWorkItemIDFromBranch(t *testing.T) {
+	tests := []struct {
+		name     string
+		branch   string
+		format   string
+		expected int
+	}{
+		{
+			name:     "default format",
+			branch:   "feature/12345/add-login",
+			format:   "",
+			expected: 12345,
+		},
+		{
+			name:     "custom format",
+			branch:   "users/jdoe/12345-add-login",
+			format:   "users/jdoe/{id}-{description}",
+			expected: 12345,
+		},
+		{
+			name:     "custom format with type",
+			branch:   "User Story.42.add-login",
+			format:   "{type}.{id}.{description}",
+			expected: 42,
+		},
+		{
+			name:     "custom format falls back to default convention",
+			branch:   "bug/99/fix-crash",
+			format:   "users/jdoe/{id}-{description}",
+			expected: 99,
+		},
+		{
+			name:     "no match",
+			branch:   "main",
+			format:   "users/jdoe/{id}-{description}",
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, workItemIDFromBranch(tt.branch, tt.format))
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/chriskievit/dex-cli/internal/config"
+	"github.com/chriskievit/dex-cli/internal/git"
+)
+
+// newAPIClient creates an Azure DevOps client configured from the global flags and config
+func newAPIClient(cfg *config.Config, server, org, token string) (*azdo.Client, error) {
+	policy, err := resolveRetryPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	client := azdo.NewClient(server, org, token, logger)
+	client.SetRetryPolicy(policy)
+
+	return client, nil
+}
+
+// newBearerAPIClient creates an Azure DevOps client that authenticates with access tokens from tokens
+func newBearerAPIClient(cfg *config.Config, server, org string, tokens azdo.TokenSource) (*azdo.Client, error) {
+	client, err := newAPIClient(cfg, server, org, "")
+	if err != nil {
+		return nil, err
+	}
+	client.SetTokenSource(tokens)
+
+	return client, nil
+}
+
+// resolveRetryPolicy builds the retry policy from the --retries/--retry-max-delay
+// flags, falling back to the config file and then the built-in defaults
+func resolveRetryPolicy(cfg *config.Config) (azdo.RetryPolicy, error) {
+	policy := azdo.DefaultRetryPolicy()
+
+	if cfg.RetryMaxAttempts < 0 {
+		return policy, fmt.Errorf("invalid retry_max_attempts in config: %d", cfg.RetryMaxAttempts)
+	}
+	if cfg.RetryMaxAttempts > 0 {
+		policy.MaxAttempts = cfg.RetryMaxAttempts
+	}
+	if cfg.RetryMaxDelay != "" {
+		delay, err := time.ParseDuration(cfg.RetryMaxDelay)
+		if err != nil || delay < 0 {
+			return policy, fmt.Errorf("invalid retry_max_delay in config: %q", cfg.RetryMaxDelay)
+		}
+		policy.MaxDelay = delay
+	}
+
+	if retryMaxAttempts < 0 {
+		return policy, fmt.Errorf("--retries cannot be negative")
+	}
+	if retryMaxAttempts > 0 {
+		policy.MaxAttempts = retryMaxAttempts
+	}
+	if retryMaxDelay < 0 {
+		return policy, fmt.Errorf("--retry-max-delay cannot be negative")
+	}
+	if retryMaxDelay > 0 {
+		policy.MaxDelay = retryMaxDelay
+	}
+
+	return policy, nil
+}
+
+// loadConfig resolves the layered configuration for the git repository containing dir
+// (global, profile, repo, repo-local and environment layers) and applies the --org, --project and
+// --server flags on top of it. dir may be empty when not running inside a repository.
+func loadConfig(ctx context.Context, dir string) (*config.Resolved, error) {
+	repoRoot := ""
+	if dir != "" {
+		if root, err := git.GetRepositoryRoot(ctx, dir); err == nil {
+			repoRoot = root
+		}
+	}
+
+	resolved, err := config.Resolve(ctx, repoRoot, profileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	for _, warning := range resolved.Warnings {
+		fmt.Fprintf(os.Stderr, "⚠ Warning: %s\n", warning)
+	}
+
+	flags := []struct {
+		key   string
+		value string
+		flag  string
+	}{
+		{"organization", organization, "--org"},
+		{"project", project, "--project"},
+		{"server_url", serverURL, "--server"},
+	}
+	for _, f := range flags {
+		if f.value == "" {
+			continue
+		}
+		if err := resolved.Set(f.key, f.value, config.Origin{Source: config.SourceFlag, Location: f.flag}); err != nil {
+			return nil, err
+		}
+	}
+
+	return resolved, nil
+}
+
+// target is the Azure DevOps location a command operates on
+type target struct {
+	Server       string
+	Organization string
+	Project      string
+	Repository   string
+}
+
+// resolveTarget determines the server, organization, project and repository from the
+// global flags and config. Values that are still empty are taken from the origin remote
+// of the git repository in dir, when it points to Azure DevOps.
+func resolveTarget(ctx context.Context, cfg *config.Config, dir string) target {
+	t := target{
+		Server:       resolveServerURL(cfg),
+		Organization: organization,
+		Project:      project,
+		Repository:   cfg.Repository,
+	}
+	if t.Organization == "" {
+		t.Organization = cfg.Organization
+	}
+	if t.Project == "" {
+		t.Project = cfg.Project
+	}
+
+	if dir != "" && (t.Organization == "" || t.Project == "" || t.Repository == "") {
+		if remote, err := git.GetAzureDevOpsRemote(ctx, dir); err == nil {
+			applyRemoteDefaults(&t, remote)
+		}
+	}
+
+	if t.Organization != "" {
+		t.Organization = azdo.NormalizeOrganization(t.Server, t.Organization)
+	}
+	return t
+}
+
+// applyRemoteDefaults fills empty target values from a parsed git remote. The project
+// is only used when the remote belongs to the target organization, and the repository
+// only when it also belongs to the target project, since repository names are per project.
+func applyRemoteDefaults(t *target, remote *git.RemoteInfo) {
+	if t.Organization == "" {
+		t.Organization = remote.Organization
+		if t.Server == "" && remote.ServerURL != azdo.DefaultServerURL {
+			t.Server = remote.ServerURL
+		}
+		if debug {
+			fmt.Printf("Using organization from git remote: %s\n", remote.Organization)
+		}
+	}
+
+	if !strings.EqualFold(azdo.NormalizeOrganization(t.Server, t.Organization), remote.Organization) {
+		return
+	}
+
+	if t.Project == "" {
+		t.Project = remote.Project
+		if debug {
+			fmt.Printf("Using project from git remote: %s\n", remote.Project)
+		}
+	}
+	if t.Repository == "" && strings.EqualFold(t.Project, remote.Project) {
+		t.Repository = remote.Repository
+		if debug {
+			fmt.Printf("Using repository from git remote: %s\n", remote.Repository)
+		}
+	}
+}
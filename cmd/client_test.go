@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/chriskievit/dex-cli/internal/config"
+	"github.com/chriskievit/dex-cli/internal/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveRetryPolicy(t *testing.T) {
+	oldAttempts, oldDelay := retryMaxAttempts, retryMaxDelay
+	defer func() { retryMaxAttempts, retryMaxDelay = oldAttempts, oldDelay }()
+
+	retryMaxAttempts, retryMaxDelay = 0, 0
+
+	// Defaults apply when nothing is configured
+	policy, err := resolveRetryPolicy(&config.Config{})
+	require.NoError(t, err)
+	assert.Equal(t, azdo.DefaultRetryPolicy(), policy)
+
+	// Config values override defaults
+	policy, err = resolveRetryPolicy(&config.Config{RetryMaxAttempts: 6, RetryMaxDelay: "1m"})
+	require.NoError(t, err)
+	assert.Equal(t, 6, policy.MaxAttempts)
+	assert.Equal(t, time.Minute, policy.MaxDelay)
+
+	// Flags override config
+	retryMaxAttempts, retryMaxDelay = 2, 5*time.Second
+	policy, err = resolveRetryPolicy(&config.Config{RetryMaxAttempts: 6, RetryMaxDelay: "1m"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, policy.MaxAttempts)
+	assert.Equal(t, 5*time.Second, policy.MaxDelay)
+
+	// Invalid config values are reported
+	retryMaxAttempts, retryMaxDelay = 0, 0
+	_, err = resolveRetryPolicy(&config.Config{RetryMaxDelay: "soon"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "retry_max_delay")
+}
+
+func TestResolveTarget(t *testing.T) {
+	oldOrg, oldProject, oldServer := organization, project, serverURL
+	defer func() { organization, project, serverURL = oldOrg, oldProject, oldServer }()
+	organization, project, serverURL = "", "", ""
+
+	repoDir, cleanup := testhelpers.SetupTempGitRepo(t)
+	defer cleanup()
+
+	cmd := exec.Command("git", "remote", "add", "origin", "https://myorg@dev.azure.com/myorg/My%20Project/_git/myrepo")
+	cmd.Dir = repoDir
+	require.NoError(t, cmd.Run())
+
+	ctx := context.Background()
+
+	t.Run("defaults from git remote", func(t *testing.T) {
+		resolved := resolveTarget(ctx, &config.Config{}, repoDir)
+		assert.Equal(t, "myorg", resolved.Organization)
+		assert.Equal(t, "My Project", resolved.Project)
+		assert.Equal(t, "myrepo", resolved.Repository)
+		assert.Equal(t, "", resolved.Server)
+	})
+
+	t.Run("config takes precedence over git remote", func(t *testing.T) {
+		resolved := resolveTarget(ctx, &config.Config{Organization: "myorg", Project: "configproject"}, repoDir)
+		assert.Equal(t, "myorg", resolved.Organization)
+		assert.Equal(t, "configproject", resolved.Project)
+		// The remote's repository is in another project
+		assert.Equal(t, "", resolved.Repository)
+	})
+
+	t.Run("repository of the configured project from git remote", func(t *testing.T) {
+		resolved := resolveTarget(ctx, &config.Config{Organization: "myorg", Project: "my project"}, repoDir)
+		assert.Equal(t, "my project", resolved.Project)
+		assert.Equal(t, "myrepo", resolved.Repository)
+	})
+
+	t.Run("flags take precedence over config", func(t *testing.T) {
+		organization, project = "myorg", "flagproject"
+		defer func() { organization, project = "", "" }()
+
+		resolved := resolveTarget(ctx, &config.Config{Organization: "configorg", Project: "configproject"}, repoDir)
+		assert.Equal(t, "myorg", resolved.Organization)
+		assert.Equal(t, "flagproject", resolved.Project)
+		assert.Equal(t, "", resolved.Repository)
+	})
+
+	t.Run("remote of another organization is ignored", func(t *testing.T) {
+		resolved := resolveTarget(ctx, &config.Config{Organization: "otherorg"}, repoDir)
+		assert.Equal(t, "otherorg", resolved.Organization)
+		assert.Equal(t, "", resolved.Project)
+		assert.Equal(t, "", resolved.Repository)
+	})
+
+	t.Run("outside a git repository", func(t *testing.T) {
+		resolved := resolveTarget(ctx, &config.Config{}, t.TempDir())
+		assert.Equal(t, target{}, resolved)
+	})
+}
+
+func TestResolveTarget_AzureDevOpsServerRemote(t *testing.T) {
+	oldOrg, oldProject, oldServer := organization, project, serverURL
+	defer func() { organization, project, serverURL = oldOrg, oldProject, oldServer }()
+	organization, project, serverURL = "", "", ""
+
+	repoDir, cleanup := testhelpers.SetupTempGitRepo(t)
+	defer cleanup()
+
+	cmd := exec.Command("git", "remote", "add", "origin", "https://tfs.corp/tfs/DefaultCollection/proj/_git/repo")
+	cmd.Dir = repoDir
+	require.NoError(t, cmd.Run())
+
+	resolved := resolveTarget(context.Background(), &config.Config{}, repoDir)
+	assert.Equal(t, "https://tfs.corp/tfs", resolved.Server)
+	assert.Equal(t, "DefaultCollection", resolved.Organization)
+	assert.Equal(t, "proj", resolved.Project)
+	assert.Equal(t, "repo", resolved.Repository)
+}
+
+func TestLoadConfig(t *testing.T) {
+	oldOrg, oldProject, oldServer := organization, project, serverURL
+	defer func() { organization, project, serverURL = oldOrg, oldProject, oldServer }()
+	organization, project, serverURL = "", "", ""
+
+	originalConfigDir := config.GetConfigDir()
+	defer config.SetConfigDir(originalConfigDir)
+	config.SetConfigDir(filepath.Join(t.TempDir(), ".dex-cli"))
+	for _, key := range config.Keys() {
+		t.Setenv(config.EnvVar(key), "")
+	}
+
+	repoDir, cleanup := testhelpers.SetupTempGitRepo(t)
+	defer cleanup()
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, config.RepoConfigFile),
+		[]byte("project: repoproject\ntarget_branch: develop\n"), 0644))
+
+	subDir := filepath.Join(repoDir, "src")
+	require.NoError(t, os.MkdirAll(subDir, 0755))
+
+	ctx := context.Background()
+
+	cfg, err := loadConfig(ctx, subDir)
+	require.NoError(t, err)
+	assert.Equal(t, "repoproject", cfg.Project)
+	assert.Equal(t, "develop", cfg.TargetBranch)
+	assert.Equal(t, config.SourceRepo, cfg.Origin("project").Source)
+
+	project = "flagproject"
+	cfg, err = loadConfig(ctx, subDir)
+	require.NoError(t, err)
+	assert.Equal(t, "flagproject", cfg.Project)
+	assert.Equal(t, config.Origin{Source: config.SourceFlag, Location: "--project"}, cfg.Origin("project"))
+}
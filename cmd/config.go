@@ -2,11 +2,17 @@ package cmd
 
 import (
 	"fmt"
+	"net/url"
+	"os"
+	"strconv"
 
+	"github.com/chriskievit/dex-cli/internal/azdo"
 	"github.com/chriskievit/dex-cli/internal/config"
 	"github.com/spf13/cobra"
 )
 
+var showOrigin bool
+
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Manage configuration",
@@ -16,8 +22,21 @@ var configCmd = &cobra.Command{
 var showConfigCmd = &cobra.Command{
 	Use:   "show",
 	Short: "Show current configuration",
-	Long:  "Display the current configuration values from ~/.dex-cli/config.yaml",
-	RunE:  runShowConfig,
+	Long: `Display the effective configuration.
+
+Values are layered in this order of precedence (highest first):
+  1. command-line flags (--org, --project, --server)
+  2. DEX_* environment variables (e.g. DEX_PROJECT, DEX_TARGET_BRANCH)
+  3. .dex.local.yaml at the git repository root (personal, not committed)
+  4. .dex.yaml at the git repository root (team defaults, committed)
+  5. the selected profile (see 'dex config profile')
+  6. ~/.dex-cli/config.yaml (global)
+
+The repository files cannot set server_url, profile, credential_store or entra_*,
+and .dex.local.yaml is ignored when it is committed. Organization, project and
+repository fall back to the git remote.
+Use --origin to show where each value came from.`,
+	RunE: runShowConfig,
 }
 
 var setConfigCmd = &cobra.Command{
@@ -45,11 +64,39 @@ var setRepoCmd = &cobra.Command{
 var setReviewerCmd = &cobra.Command{
 	Use:   "reviewer [value]",
 	Short: "Set the default reviewer configuration value",
-	Long:  "Set the default reviewer for pull requests in the configuration",
+	Long:  "Set the default reviewers for pull requests in the configuration, as a comma-separated list",
 	Args:  cobra.ExactArgs(1),
 	RunE:  runSetReviewer,
 }
 
+var setServerCmd = &cobra.Command{
+	Use:   "server [value]",
+	Short: "Set the server URL configuration value",
+	Long: `Set the Azure DevOps server URL in the configuration.
+
+Use this for Azure DevOps Server (on-premises) installations, where the
+collection takes the place of the organization. For example, for the collection
+https://tfs.corp/DefaultCollection set the server to https://tfs.corp and the
+organization to DefaultCollection.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSetServer,
+}
+
+var setCredentialStoreCmd = &cobra.Command{
+	Use:   "credential-store [keyring|file]",
+	Short: "Set where credentials are stored",
+	Long: `Set where 'dex auth login' stores tokens.
+
+  keyring  the system keychain (default)
+  file     a passphrase-encrypted file in ~/.dex-cli, for machines without a keychain
+           such as Linux servers and WSL. The passphrase is read from
+           DEX_CREDENTIAL_PASSPHRASE or prompted for.
+
+Tokens are not moved between stores; run 'dex auth login' again after switching.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSetCredentialStore,
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(showConfigCmd)
@@ -57,25 +104,92 @@ func init() {
 	setConfigCmd.AddCommand(setProjectCmd)
 	setConfigCmd.AddCommand(setRepoCmd)
 	setConfigCmd.AddCommand(setReviewerCmd)
+	setConfigCmd.AddCommand(setServerCmd)
+	setConfigCmd.AddCommand(setCredentialStoreCmd)
+
+	showConfigCmd.Flags().BoolVar(&showOrigin, "origin", false, "Show where each value came from")
+}
+
+// configField is a configuration key shown by 'config show'
+type configField struct {
+	key   string
+	label string
+	value string
 }
 
 func runShowConfig(cmd *cobra.Command, args []string) error {
-	cfg, err := config.Load()
+	ctx := commandContext(cmd)
+
+	// The working directory is only used to find repository config files and the git remote
+	cwd, _ := os.Getwd()
+
+	cfg, err := loadConfig(ctx, cwd)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
+	}
+
+	fields := []configField{
+		{"profile", "Profile", cfg.Profile},
+		{"organization", "Organization", cfg.Organization},
+		{"project", "Project", cfg.Project},
+		{"repository", "Repository", cfg.Repository},
+		{"default_reviewer", "Default Reviewer", cfg.DefaultReviewer},
+		{"required_reviewers", "Required Reviewers", cfg.RequiredReviewers},
+		{"server_url", "Server URL", cfg.ServerURL},
+		{"target_branch", "Target Branch", cfg.TargetBranch},
+		{"branch_format", "Branch Format", cfg.BranchFormat},
+		{"title_format", "PR Title Format", cfg.TitleFormat},
+		{"retry_max_attempts", "Retry Attempts", formatInt(cfg.RetryMaxAttempts)},
+		{"retry_max_delay", "Retry Max Delay", cfg.RetryMaxDelay},
+		{"credential_store", "Credential Store", cfg.CredentialStore},
+		{"expiry_warning_days", "Expiry Warning Days", formatInt(cfg.ExpiryWarningDays)},
+		{"entra_authority", "Entra Authority", cfg.EntraAuthority},
+		{"entra_client_id", "Entra Client ID", cfg.EntraClientID},
+	}
+
+	// Values that are not configured anywhere may still be detected from the git remote
+	if cwd != "" {
+		t := resolveTarget(ctx, cfg.Config, cwd)
+		remote := config.Origin{Source: config.SourceRemote, Location: "origin"}
+		detected := map[string]string{
+			"organization": t.Organization,
+			"project":      t.Project,
+			"repository":   t.Repository,
+		}
+		for i, f := range fields {
+			if value := detected[f.key]; f.value == "" && value != "" {
+				fields[i].value = value
+				cfg.Origins[f.key] = remote
+			}
+		}
 	}
 
 	fmt.Println("Current Configuration")
 	fmt.Println("─────────────────────────────────────────")
-	fmt.Printf("Organization:     %s\n", formatValue(cfg.Organization))
-	fmt.Printf("Project:          %s\n", formatValue(cfg.Project))
-	fmt.Printf("Repository:       %s\n", formatValue(cfg.Repository))
-	fmt.Printf("Default Reviewer: %s\n", formatValue(cfg.DefaultReviewer))
-	fmt.Printf("\nConfig File: %s\n", config.GetConfigDir()+"/config.yaml")
+	for _, f := range fields {
+		line := fmt.Sprintf("%-18s%s", f.label+":", formatValue(f.value))
+		if showOrigin && f.value != "" {
+			line = fmt.Sprintf("%-50s [%s]", line, cfg.Origin(f.key))
+		}
+		fmt.Println(line)
+	}
+
+	fmt.Println("\nConfig Files:")
+	for _, file := range cfg.Files {
+		fmt.Printf("  %s\n", file)
+	}
 
 	return nil
 }
 
+// formatInt formats a numeric config value, treating zero as not set
+func formatInt(value int) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.Itoa(value)
+}
+
 func formatValue(value string) string {
 	if value == "" {
 		return "(not set)"
@@ -145,3 +259,51 @@ func runSetReviewer(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Default reviewer set to: %s\n", value)
 	return nil
 }
+
+func runSetServer(cmd *cobra.Command, args []string) error {
+	value := args[0]
+	if value == "" {
+		return fmt.Errorf("server value cannot be empty")
+	}
+
+	parsed, err := url.Parse(azdo.NormalizeServerURL(value))
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("invalid server URL: %s", value)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	cfg.ServerURL = azdo.NormalizeServerURL(value)
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("Server URL set to: %s\n", cfg.ServerURL)
+	return nil
+}
+
+func runSetCredentialStore(cmd *cobra.Command, args []string) error {
+	value := args[0]
+	if value != "keyring" && value != "file" {
+		return fmt.Errorf("invalid credential store %q: use \"keyring\" or \"file\"", value)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	cfg.CredentialStore = value
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("Credential store set to: %s\n", value)
+	fmt.Println("Run 'dex auth login' to store your token there")
+	return nil
+}
@@ -237,3 +237,87 @@ func TestRunSetReviewer_EmptyValue(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "cannot be empty")
 }
+
+func TestRunSetServer(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, ".dex-cli")
+
+	// Save original config dir and restore after test
+	originalConfigDir := config.GetConfigDir()
+	defer config.SetConfigDir(originalConfigDir)
+
+	config.SetConfigDir(configDir)
+
+	// Initialize config
+	_, err := config.Load()
+	require.NoError(t, err)
+
+	// Execute command
+	err = runSetServer(setServerCmd, []string{"https://tfs.corp/"})
+	require.NoError(t, err)
+
+	// Verify config was saved with the trailing slash removed
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "https://tfs.corp", cfg.ServerURL)
+}
+
+func TestRunSetServer_InvalidValue(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, ".dex-cli")
+
+	// Save original config dir and restore after test
+	originalConfigDir := config.GetConfigDir()
+	defer config.SetConfigDir(originalConfigDir)
+
+	config.SetConfigDir(configDir)
+
+	// Initialize config
+	_, err := config.Load()
+	require.NoError(t, err)
+
+	err = runSetServer(setServerCmd, []string{""})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot be empty")
+
+	err = runSetServer(setServerCmd, []string{"https://"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid server URL")
+}
+
+func TestRunSetCredentialStore(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, ".dex-cli")
+
+	// Save original config dir and restore after test
+	originalConfigDir := config.GetConfigDir()
+	defer config.SetConfigDir(originalConfigDir)
+
+	config.SetConfigDir(configDir)
+
+	// Initialize config
+	_, err := config.Load()
+	require.NoError(t, err)
+
+	err = runSetCredentialStore(setCredentialStoreCmd, []string{"vault"})
+	assert.Error(t, err)
+
+	err = runSetCredentialStore(setCredentialStoreCmd, []string{"file"})
+	require.NoError(t, err)
+	defer runSetCredentialStore(setCredentialStoreCmd, []string{"keyring"})
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "file", cfg.CredentialStore)
+
+	oldOverride := credentialStoreOverride
+	credentialStoreOverride = nil
+	defer func() { credentialStoreOverride = oldOverride }()
+
+	store, err := credentialStore(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "encrypted file "+filepath.Join(configDir, "credentials.enc"), store.Name())
+
+	_, err = credentialStore(&config.Config{CredentialStore: "vault"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/chriskievit/dex-cli/internal/auth"
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/chriskievit/dex-cli/internal/config"
+	"golang.org/x/term"
+)
+
+const (
+	// credentialsFileName is the encrypted file in the config directory used by the "file" store
+	credentialsFileName = "credentials.enc"
+	// tokenMetadataFileName holds the non-secret expiry dates of stored tokens
+	tokenMetadataFileName = "tokens.json"
+
+	// defaultExpiryWarningDays is used when expiry_warning_days is not configured
+	defaultExpiryWarningDays = 7
+	// expiryDateLayout is the date format of token expiry dates
+	expiryDateLayout = "2006-01-02"
+)
+
+// credentialStoreOverride replaces the configured credential store when set.
+// Tests use it to install an in-memory store.
+var credentialStoreOverride auth.CredentialStore
+
+// credentialStore returns the store selected by credential_store in the config
+func credentialStore(cfg *config.Config) (auth.CredentialStore, error) {
+	if credentialStoreOverride != nil {
+		return credentialStoreOverride, nil
+	}
+
+	switch cfg.CredentialStore {
+	case "", "keyring":
+		return auth.KeyringStore{}, nil
+	case "file":
+		return auth.NewFileStore(filepath.Join(config.GetConfigDir(), credentialsFileName), readPassphrase), nil
+	default:
+		return nil, fmt.Errorf("invalid credential_store %q: use \"keyring\" or \"file\"", cfg.CredentialStore)
+	}
+}
+
+// readPassphrase returns the passphrase of the encrypted credentials file from
+// DEX_CREDENTIAL_PASSPHRASE, or prompts for it when running in a terminal
+func readPassphrase() (string, error) {
+	if passphrase := os.Getenv(auth.PassphraseEnvVar); passphrase != "" {
+		return passphrase, nil
+	}
+
+	fd := int(syscall.Stdin)
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("set %s to unlock the encrypted credentials file", auth.PassphraseEnvVar)
+	}
+
+	fmt.Fprint(os.Stderr, "Credentials file passphrase: ")
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return string(passphrase), nil
+}
+
+// credentialAccount returns the credential entry holding the token for org: the selected
+// profile's own entry when a profile is in use and org is on the profile's organization
+// and server, otherwise the organization's on that server. A profile's token is never sent elsewhere,
+// e.g. to an organization chosen with --org or by a repository's config.
+func credentialAccount(cfg *config.Config, org string) auth.Account {
+	account := auth.Account{Organization: org, Server: resolveServerURL(cfg)}
+	if cfg.Profile != "" && profileCovers(cfg, org) {
+		account.Profile = cfg.Profile
+	}
+	return account
+}
+
+// profileCovers reports whether the selected profile's credentials apply to org on the
+// server in effect. A profile without an organization has not logged in anywhere yet.
+func profileCovers(cfg *config.Config, org string) bool {
+	server := azdo.NormalizeServerURL(resolveServerURL(cfg))
+	if !strings.EqualFold(server, azdo.NormalizeServerURL(cfg.ProfileServerURL)) {
+		return false
+	}
+	return cfg.ProfileOrganization == "" ||
+		strings.EqualFold(azdo.NormalizeOrganization(server, cfg.ProfileOrganization), azdo.NormalizeOrganization(server, org))
+}
+
+// getToken returns the token to use for org, warning when a stored token is about to expire
+func getToken(cfg *config.Config, org string) (string, error) {
+	store, err := credentialStore(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	account := credentialAccount(cfg, org)
+	cred, err := auth.DefaultChain(store).Credential(account)
+	if err != nil {
+		return "", err
+	}
+
+	// Expiry dates are only known for tokens stored by 'dex auth login'
+	if cred.Source == store.Name() {
+		warnTokenExpiry(os.Stderr, cfg, account, time.Now())
+	}
+
+	return cred.Token, nil
+}
+
+// authenticatedClient creates an Azure DevOps client for org with the credentials in effect:
+// a token from the environment, then a stored Entra ID sign-in, then a stored PAT
+func authenticatedClient(cfg *config.Config, server, org string) (*azdo.Client, error) {
+	tokens, err := entraTokenSource(cfg, org)
+	if err == nil {
+		return newBearerAPIClient(cfg, server, org, tokens)
+	}
+	if !errors.Is(err, auth.ErrNoCredentials) {
+		return nil, err
+	}
+
+	token, err := getToken(cfg, org)
+	if err != nil {
+		return nil, err
+	}
+	return newAPIClient(cfg, server, org, token)
+}
+
+// entraTokenSource returns the stored Entra ID sign-in for org. It returns an error wrapping
+// ErrNoCredentials when there is none, or when a token in the environment takes precedence.
+func entraTokenSource(cfg *config.Config, org string) (*auth.EntraTokenSource, error) {
+	account := credentialAccount(cfg, org)
+	if _, err := auth.EnvironmentChain().Credential(account); err == nil {
+		return nil, auth.ErrNoCredentials
+	}
+
+	store, err := credentialStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return auth.LoadEntraTokenSource(store, account)
+}
+
+// tokenMetadata returns the file holding the expiry dates of stored tokens
+func tokenMetadata() *auth.MetadataFile {
+	return auth.NewMetadataFile(filepath.Join(config.GetConfigDir(), tokenMetadataFileName))
+}
+
+// warnTokenExpiry prints a warning to w when the token for account expires within
+// the configured warning window or has already expired
+func warnTokenExpiry(w io.Writer, cfg *config.Config, account auth.Account, now time.Time) {
+	window := cfg.ExpiryWarningDays
+	if window == 0 {
+		window = defaultExpiryWarningDays
+	}
+	if window < 0 {
+		return
+	}
+
+	metadata, ok, err := tokenMetadata().Get(account)
+	if err != nil || !ok || !metadata.HasExpiry() {
+		return
+	}
+
+	days := metadata.DaysRemaining(now)
+	expires := metadata.ExpiresAt.Format(expiryDateLayout)
+	switch {
+	case days < 0:
+		fmt.Fprintf(w, "⚠ Warning: the token for %s expired on %s. Run 'dex auth login' to renew it\n", account, expires)
+	case days <= window:
+		fmt.Fprintf(w, "⚠ Warning: the token for %s expires in %s (%s). Run 'dex auth login' with a new token before then\n",
+			account, formatDays(days), expires)
+	}
+}
+
+// formatDays formats a number of days remaining, e.g. "3 days" or "less than a day"
+func formatDays(days int) string {
+	switch days {
+	case 0:
+		return "less than a day"
+	case 1:
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
+// parseExpiry parses a token expiry given as a date (2026-12-31) or as a number
+// of days from now (30d). Dates are taken as the end of that day in local time.
+func parseExpiry(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return time.Time{}, fmt.Errorf("invalid token expiry %q: use a date (YYYY-MM-DD) or a number of days (e.g. 30d)", value)
+		}
+		return now.AddDate(0, 0, n), nil
+	}
+
+	date, err := time.ParseInLocation(expiryDateLayout, value, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid token expiry %q: use a date (YYYY-MM-DD) or a number of days (e.g. 30d)", value)
+	}
+	expires := date.AddDate(0, 0, 1).Add(-time.Second)
+	if expires.Before(now) {
+		return time.Time{}, fmt.Errorf("token expiry %s is in the past", value)
+	}
+	return expires, nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/chriskievit/dex-cli/internal/git"
+)
+
+// editText opens text in the editor git uses in dir and returns the edited text, with
+// trailing whitespace removed
+func editText(ctx context.Context, dir, text string) (string, error) {
+	editor, err := git.Editor(ctx, dir)
+	if err != nil {
+		return "", err
+	}
+	if editor == "" {
+		return "", fmt.Errorf("no editor configured. Set core.editor in git or the EDITOR environment variable")
+	}
+
+	file, err := os.CreateTemp("", "dex-description-*.md")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(text)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	// Editors are configured as shell commands, e.g. "code --wait", as git runs them
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		fields := strings.Fields(editor)
+		cmd = exec.CommandContext(ctx, fields[0], append(fields[1:], file.Name())...)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", editor+` "$@"`, editor, file.Name())
+	}
+	cmd.Dir = dir
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited text: %w", err)
+	}
+	return strings.TrimRight(string(edited), " \t\r\n"), nil
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+)
+
+// describeError formats an error for the terminal, adding actionable hints
+// for Azure DevOps API failures
+func describeError(err error) string {
+	if errors.Is(err, context.Canceled) {
+		return "interrupted"
+	}
+	if errors.Is(err, context.DeadlineExceeded) && timeout > 0 {
+		return fmt.Sprintf("%v\n\nThe command did not finish within %s. Increase --timeout to allow more time.", err, timeout)
+	}
+
+	apiErr, ok := azdo.AsAPIError(err)
+	if !ok {
+		return err.Error()
+	}
+
+	var b strings.Builder
+	b.WriteString(err.Error())
+
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized:
+		b.WriteString("\n\nAzure DevOps rejected your credentials. Your Personal Access Token may be invalid or expired.")
+		b.WriteString("\nRun 'dex auth login' to store a new token.")
+	case http.StatusForbidden:
+		b.WriteString("\n\nYou do not have permission to perform this operation.")
+		if scope := apiErr.RequiredScope(); scope != "" {
+			fmt.Fprintf(&b, "\nMake sure your Personal Access Token has the '%s' scope, then run 'dex auth login'.", scope)
+		}
+	case http.StatusNotFound:
+		b.WriteString("\n\nThe requested resource was not found, or you do not have access to it.")
+		b.WriteString("\nCheck the organization, project and repository names with 'dex config show'.")
+	case http.StatusTooManyRequests:
+		b.WriteString("\n\nAzure DevOps is throttling your requests. Wait a moment and try again,")
+		b.WriteString("\nor allow more attempts with --retries and --retry-max-delay.")
+	}
+
+	if apiErr.ActivityID != "" {
+		fmt.Fprintf(&b, "\nActivity ID: %s", apiErr.ActivityID)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribeError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		contains []string
+	}{
+		{
+			name:     "interrupted",
+			err:      fmt.Errorf("failed to fetch work item: %w", context.Canceled),
+			contains: []string{"interrupted"},
+		},
+		{
+			name:     "plain error",
+			err:      fmt.Errorf("not a git repository"),
+			contains: []string{"not a git repository"},
+		},
+		{
+			name: "unauthorized suggests login",
+			err: fmt.Errorf("failed to fetch work item: %w", &azdo.APIError{
+				StatusCode: http.StatusUnauthorized,
+			}),
+			contains: []string{"failed to fetch work item", "dex auth login"},
+		},
+		{
+			name: "forbidden names the missing scope",
+			err: fmt.Errorf("failed to create pull request: %w", &azdo.APIError{
+				StatusCode: http.StatusForbidden,
+				Method:     http.MethodPost,
+				URL:        "https://dev.azure.com/org/proj/_apis/git/repositories/r/pullrequests",
+				Message:    "TF401027: You need the Git 'Contribute' permission.",
+				ActivityID: "abc-123",
+			}),
+			contains: []string{"TF401027", "'Code (Read & Write)' scope", "Activity ID: abc-123"},
+		},
+		{
+			name: "not found",
+			err: &azdo.APIError{
+				StatusCode: http.StatusNotFound,
+			},
+			contains: []string{"not found", "dex config show"},
+		},
+		{
+			name: "throttled",
+			err: &azdo.APIError{
+				StatusCode: http.StatusTooManyRequests,
+			},
+			contains: []string{"throttling", "--retries"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := describeError(tt.err)
+			for _, s := range tt.contains {
+				assert.Contains(t, message, s)
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/chriskievit/dex-cli/internal/auth"
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/chriskievit/dex-cli/internal/config"
+	"github.com/chriskievit/dex-cli/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// azureDevOpsHosts are the hosts of Azure DevOps Services, which dex provides git
+// credentials for over HTTPS in addition to the configured server
+var azureDevOpsHosts = []string{"dev.azure.com", "*.visualstudio.com"}
+
+// visualStudioURL matches the legacy {org}.visualstudio.com URLs in git config
+const visualStudioURL = "https://*.visualstudio.com"
+
+var unsetupGit bool
+
+var gitCredentialCmd = &cobra.Command{
+	Use:   "git-credential <get|store|erase>",
+	Short: "Git credential helper backed by the dex credentials",
+	Long: `Implement the git credential helper protocol, so git can push and fetch over HTTPS
+with the token dex stores, or the Entra ID sign-in. Git runs this command itself once
+it is registered with 'dex auth setup-git'.
+
+Only HTTPS requests for dev.azure.com and *.visualstudio.com, and requests for the
+configured server with its own scheme and port, are answered; git asks its other
+helpers for anything else.`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"get", "store", "erase"},
+	RunE:      runGitCredential,
+}
+
+var setupGitCmd = &cobra.Command{
+	Use:   "setup-git",
+	Short: "Use dex as the git credential helper for Azure DevOps",
+	Long: `Register 'dex auth git-credential' as the credential helper for dev.azure.com,
+*.visualstudio.com and the configured server in your global git config. Afterwards 'git push' and 'git fetch' over
+HTTPS use the credentials from 'dex auth login'. Use --unset to remove it again.`,
+	Args: cobra.NoArgs,
+	RunE: runSetupGit,
+}
+
+func init() {
+	authCmd.AddCommand(gitCredentialCmd)
+	authCmd.AddCommand(setupGitCmd)
+
+	setupGitCmd.Flags().BoolVar(&unsetupGit, "unset", false, "Remove dex as the credential helper")
+}
+
+func runGitCredential(cmd *cobra.Command, args []string) error {
+	operation := args[0]
+	if operation != "get" && operation != "store" && operation != "erase" {
+		// Unknown operations must be ignored for compatibility with newer git versions
+		return nil
+	}
+
+	request, err := git.ReadCredential(cmd.InOrStdin())
+	if err != nil {
+		return err
+	}
+	// git runs helpers in the repository, so its config and remote apply
+	ctx := commandContext(cmd)
+	cwd, _ := os.Getwd()
+	cfg, err := loadConfig(ctx, cwd)
+	if err != nil {
+		return err
+	}
+
+	server := credentialServer(request, resolveServerURL(cfg.Config))
+	if server == "" || (serverURL != "" && !strings.EqualFold(azdo.NormalizeServerURL(serverURL), server)) {
+		return nil
+	}
+	// Use the credentials of the server git asks for, which may be dev.azure.com while
+	// another server is configured
+	credCfg := *cfg.Config
+	credCfg.ServerURL = server
+
+	org := credentialOrganization(request, server)
+	if org == "" {
+		org = resolveTarget(ctx, &credCfg, cwd).Organization
+	}
+	if org == "" {
+		return fmt.Errorf("cannot determine the Azure DevOps organization for %s. Set credential.useHttpPath or use --org", request.Host)
+	}
+	org = azdo.NormalizeOrganization(server, org)
+
+	// credentialAccount only uses the profile's credentials for the profile's own organization
+	switch operation {
+	case "get":
+		return gitCredentialGet(ctx, cmd, &credCfg, org, request)
+	case "store":
+		return gitCredentialStore(&credCfg, org, request)
+	default:
+		return gitCredentialErase(&credCfg, org, request)
+	}
+}
+
+// gitCredentialGet answers git with the token for org. Nothing is written when dex has
+// no credentials, so git falls back to its other helpers or prompts.
+func gitCredentialGet(ctx context.Context, cmd *cobra.Command, cfg *config.Config, org string, request *git.Credential) error {
+	var password string
+	tokens, err := entraTokenSource(cfg, org)
+	switch {
+	case err == nil:
+		// Azure DevOps accepts Entra ID access tokens as the password of Basic authentication
+		if password, err = tokens.AccessToken(ctx); err != nil {
+			return err
+		}
+	case errors.Is(err, auth.ErrNoCredentials):
+		if password, err = getToken(cfg, org); err != nil {
+			if errors.Is(err, auth.ErrNoCredentials) {
+				return nil
+			}
+			return err
+		}
+	default:
+		return err
+	}
+
+	// Azure DevOps ignores the username, but git requires one
+	username := request.Username
+	if username == "" {
+		username = org
+	}
+
+	response := &git.Credential{Username: username, Password: password}
+	return response.Write(cmd.OutOrStdout())
+}
+
+// gitCredentialStore saves a PAT that git obtained elsewhere, e.g. by prompting, unless
+// it is the token dex already provides or an Entra ID sign-in is in use
+func gitCredentialStore(cfg *config.Config, org string, request *git.Credential) error {
+	if request.Password == "" {
+		return nil
+	}
+	if _, err := entraTokenSource(cfg, org); !errors.Is(err, auth.ErrNoCredentials) {
+		return err
+	}
+
+	store, err := credentialStore(cfg)
+	if err != nil {
+		return err
+	}
+	account := credentialAccount(cfg, org)
+	if current, err := store.Token(account); err == nil && current == request.Password {
+		return nil
+	}
+
+	if err := auth.StoreToken(store, account, request.Password); err != nil {
+		return err
+	}
+	// The expiry recorded for the previous token no longer applies
+	return tokenMetadata().Delete(account)
+}
+
+// gitCredentialErase removes the stored PAT after git reports that it was rejected.
+// A different token than the one stored, or an Entra ID access token, is left alone.
+func gitCredentialErase(cfg *config.Config, org string, request *git.Credential) error {
+	store, err := credentialStore(cfg)
+	if err != nil {
+		return err
+	}
+	account := credentialAccount(cfg, org)
+
+	current, err := store.Token(account)
+	if err != nil {
+		if errors.Is(err, auth.ErrNoCredentials) {
+			return nil
+		}
+		return err
+	}
+	if request.Password != "" && current != request.Password {
+		return nil
+	}
+
+	if err := auth.DeleteToken(store, account); err != nil {
+		return err
+	}
+	return tokenMetadata().Delete(account)
+}
+
+// credentialServer returns the normalized URL of the server whose credentials answer a
+// request: Azure DevOps Services over HTTPS, or the configured server with the same
+// scheme, host and port. It returns "" for any other request, so a token never goes out
+// over plain HTTP unless the configured server itself uses it.
+func credentialServer(request *git.Credential, configured string) string {
+	host := strings.ToLower(request.Host)
+	if request.Protocol == "https" {
+		for _, pattern := range azureDevOpsHosts {
+			if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+				if strings.HasSuffix(host, suffix) {
+					return azdo.DefaultServerURL
+				}
+			} else if host == pattern {
+				return azdo.DefaultServerURL
+			}
+		}
+	}
+
+	if configured != "" {
+		server := azdo.NormalizeServerURL(configured)
+		if parsed, err := url.Parse(server); err == nil && strings.EqualFold(parsed.Scheme, request.Protocol) && strings.EqualFold(parsed.Host, host) {
+			return server
+		}
+	}
+	return ""
+}
+
+// credentialOrganization determines the organization of a credential request from the
+// host ({org}.visualstudio.com), the path (with credential.useHttpPath) or the username
+// in the remote URL (https://{org}@dev.azure.com/...). It returns "" if none applies.
+func credentialOrganization(request *git.Credential, server string) string {
+	host := strings.ToLower(request.Host)
+	if org, ok := strings.CutSuffix(host, ".visualstudio.com"); ok {
+		return org
+	}
+
+	// On Azure DevOps Server the collection follows the server's own path
+	path := strings.Trim(request.Path, "/")
+	if server != "" {
+		if parsed, err := url.Parse(azdo.NormalizeServerURL(server)); err == nil && strings.EqualFold(parsed.Host, host) {
+			path = strings.TrimPrefix(strings.TrimPrefix(path, strings.Trim(parsed.Path, "/")), "/")
+		}
+	}
+	if org, _, _ := strings.Cut(path, "/"); org != "" {
+		if unescaped, err := url.PathUnescape(org); err == nil {
+			return unescaped
+		}
+		return org
+	}
+
+	if host == "dev.azure.com" {
+		return request.Username
+	}
+	return ""
+}
+
+func runSetupGit(cmd *cobra.Command, args []string) error {
+	ctx := commandContext(cmd)
+	cwd, _ := os.Getwd()
+	cfg, err := loadConfig(ctx, cwd)
+	if err != nil {
+		return err
+	}
+
+	urls := []string{azdo.DefaultServerURL, visualStudioURL}
+	if server := resolveServerURL(cfg.Config); server != "" && azdo.NormalizeServerURL(server) != azdo.DefaultServerURL {
+		urls = append(urls, azdo.NormalizeServerURL(server))
+	}
+
+	if unsetupGit {
+		for _, u := range urls {
+			if err := git.UnsetGlobalConfig(ctx, "credential."+u+".helper"); err != nil {
+				return err
+			}
+			if err := git.UnsetGlobalConfig(ctx, "credential."+u+".useHttpPath"); err != nil {
+				return err
+			}
+		}
+		fmt.Println("✓ dex is no longer the git credential helper for Azure DevOps")
+		return nil
+	}
+
+	helper, err := gitCredentialHelper()
+	if err != nil {
+		return err
+	}
+
+	for _, u := range urls {
+		// An empty helper clears the helpers configured for all URLs, such as the Git
+		// Credential Manager or osxkeychain, which git would otherwise ask first
+		if err := git.SetGlobalConfig(ctx, "credential."+u+".helper", ""); err != nil {
+			return err
+		}
+		if err := git.AddGlobalConfig(ctx, "credential."+u+".helper", helper); err != nil {
+			return err
+		}
+		// Sending the path lets the helper tell organizations on the same host apart
+		if err := git.SetGlobalConfig(ctx, "credential."+u+".useHttpPath", "true"); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Registered dex as the git credential helper for %s\n", u)
+	}
+
+	return nil
+}
+
+// gitCredentialHelper returns the helper command to register in git config. The
+// --profile flag is kept, so git uses the profile's credentials.
+func gitCredentialHelper() (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the dex executable: %w", err)
+	}
+
+	helper := "!" + shellQuote(executable)
+	if profileName != "" {
+		helper += " --profile " + shellQuote(profileName)
+	}
+	return helper + " auth git-credential", nil
+}
+
+// shellQuote quotes s for the POSIX shell git runs "!" helpers with
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chriskievit/dex-cli/internal/auth"
+	"github.com/chriskievit/dex-cli/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialServer(t *testing.T) {
+	tests := []struct {
+		name       string
+		request    git.Credential
+		configured string
+		want       string
+	}{
+		{name: "dev.azure.com", request: git.Credential{Protocol: "https", Host: "dev.azure.com"}, want: "https://dev.azure.com"},
+		{name: "visualstudio.com", request: git.Credential{Protocol: "https", Host: "contoso.visualstudio.com"}, configured: "https://tfs.corp", want: "https://dev.azure.com"},
+		{name: "dev.azure.com over HTTP", request: git.Credential{Protocol: "http", Host: "dev.azure.com"}, want: ""},
+		{name: "visualstudio.com over HTTP", request: git.Credential{Protocol: "http", Host: "contoso.visualstudio.com"}, want: ""},
+		{name: "server with port", request: git.Credential{Protocol: "https", Host: "tfs.corp:8080"}, configured: "https://tfs.corp:8080/", want: "https://tfs.corp:8080"},
+		{name: "server on another port", request: git.Credential{Protocol: "https", Host: "tfs.corp:8443"}, configured: "https://tfs.corp:8080", want: ""},
+		{name: "HTTP server", request: git.Credential{Protocol: "http", Host: "tfs.corp"}, configured: "http://tfs.corp/tfs", want: "http://tfs.corp/tfs"},
+		{name: "HTTPS server over HTTP", request: git.Credential{Protocol: "http", Host: "tfs.corp"}, configured: "https://tfs.corp", want: ""},
+		{name: "unconfigured server", request: git.Credential{Protocol: "https", Host: "tfs.corp"}, want: ""},
+		{name: "other host", request: git.Credential{Protocol: "https", Host: "github.com"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, credentialServer(&tt.request, tt.configured))
+		})
+	}
+}
+
+func TestCredentialOrganization(t *testing.T) {
+	tests := []struct {
+		name    string
+		request git.Credential
+		server  string
+		want    string
+	}{
+		{name: "path", request: git.Credential{Host: "dev.azure.com", Path: "myorg/proj/_git/repo", Username: "other"}, want: "myorg"},
+		{name: "username", request: git.Credential{Host: "dev.azure.com", Username: "myorg"}, want: "myorg"},
+		{name: "visualstudio.com host", request: git.Credential{Host: "contoso.visualstudio.com", Path: "proj/_git/repo"}, want: "contoso"},
+		{name: "server collection", request: git.Credential{Host: "tfs.corp", Path: "tfs/DefaultCollection/proj/_git/repo"}, server: "https://tfs.corp/tfs", want: "DefaultCollection"},
+		{name: "unknown", request: git.Credential{Host: "dev.azure.com"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, credentialOrganization(&tt.request, tt.server))
+		})
+	}
+}
+
+// gitCredential runs the helper with input and returns what it wrote for git
+func gitCredential(t *testing.T, operation, input string) string {
+	t.Helper()
+
+	var out bytes.Buffer
+	gitCredentialCmd.SetIn(strings.NewReader(input))
+	gitCredentialCmd.SetOut(&out)
+	t.Cleanup(func() {
+		gitCredentialCmd.SetIn(nil)
+		gitCredentialCmd.SetOut(nil)
+	})
+
+	require.NoError(t, runGitCredential(gitCredentialCmd, []string{operation}))
+	return out.String()
+}
+
+func TestRunGitCredential(t *testing.T) {
+	store := setupAuthTest(t)
+	account := auth.Account{Organization: "myorg"}
+	request := "protocol=https\nhost=dev.azure.com\npath=myorg/proj/_git/repo\n"
+
+	// Without credentials git is left to its other helpers
+	assert.Empty(t, gitCredential(t, "get", request))
+	assert.Empty(t, gitCredential(t, "get", "protocol=https\nhost=github.com\n\n"))
+
+	// A token git obtained elsewhere is stored
+	gitCredential(t, "store", "protocol=https\nhost=dev.azure.com\npath=myorg/proj/_git/repo\nusername=me\npassword=my-personal-access-token-value\n\n")
+	token, err := store.Token(account)
+	require.NoError(t, err)
+	assert.Equal(t, "my-personal-access-token-value", token)
+
+	assert.Equal(t, "username=myorg\npassword=my-personal-access-token-value\n", gitCredential(t, "get", request))
+	// The token is never sent in cleartext
+	assert.Empty(t, gitCredential(t, "get", "protocol=http\nhost=dev.azure.com\npath=myorg/proj/_git/repo\n"))
+	assert.Equal(t, "username=me\npassword=my-personal-access-token-value\n",
+		gitCredential(t, "get", "protocol=https\nhost=dev.azure.com\nusername=me\npath=myorg/proj/_git/repo\n\n"))
+
+	// Rejected credentials other than the stored token are not erased
+	gitCredential(t, "erase", request+"password=some-other-token\n")
+	_, err = store.Token(account)
+	require.NoError(t, err)
+
+	gitCredential(t, "erase", request+"password=my-personal-access-token-value\n")
+	_, err = store.Token(account)
+	assert.ErrorIs(t, err, auth.ErrNoCredentials)
+
+	// Unknown operations are ignored
+	assert.Empty(t, gitCredential(t, "capabilities", request))
+}
+
+func TestRunSetupGit(t *testing.T) {
+	setupProfileTest(t)
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(t.TempDir(), "gitconfig"))
+	oldUnset := unsetupGit
+	defer func() { unsetupGit = oldUnset }()
+
+	ctx := commandContext(setupGitCmd)
+	require.NoError(t, git.SetGlobalConfig(ctx, "credential.helper", "osxkeychain"))
+
+	// Running it again replaces the earlier registration
+	require.NoError(t, runSetupGit(setupGitCmd, []string{}))
+	require.NoError(t, runSetupGit(setupGitCmd, []string{}))
+
+	// The empty value resets the helper list, so the global helper is not asked first
+	for _, u := range []string{"https://dev.azure.com", "https://*.visualstudio.com"} {
+		helpers, err := git.GetAllGlobalConfig(ctx, "credential."+u+".helper")
+		require.NoError(t, err)
+		require.Len(t, helpers, 2, u)
+		assert.Empty(t, helpers[0])
+		assert.True(t, strings.HasPrefix(helpers[1], "!'"), helpers[1])
+		assert.True(t, strings.HasSuffix(helpers[1], "' auth git-credential"), helpers[1])
+	}
+	useHTTPPath, err := git.GetGlobalConfig(ctx, "credential.https://dev.azure.com.useHttpPath")
+	require.NoError(t, err)
+	assert.Equal(t, "true", useHTTPPath)
+
+	unsetupGit = true
+	require.NoError(t, runSetupGit(setupGitCmd, []string{}))
+	for _, u := range []string{"https://dev.azure.com", "https://*.visualstudio.com"} {
+		helpers, err := git.GetAllGlobalConfig(ctx, "credential."+u+".helper")
+		require.NoError(t, err)
+		assert.Empty(t, helpers, u)
+	}
+	helper, err := git.GetGlobalConfig(ctx, "credential.helper")
+	require.NoError(t, err)
+	assert.Equal(t, "osxkeychain", helper)
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, `'/usr/local/bin/dex'`, shellQuote("/usr/local/bin/dex"))
+	assert.Equal(t, `'/home/o'\''brien/dex'`, shellQuote("/home/o'brien/dex"))
+}
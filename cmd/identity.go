@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+)
+
+// meIdentity stands for the authenticated user in identity flags
+const meIdentity = "me"
+
+// identityIDPattern matches identity IDs (GUIDs)
+var identityIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// resolveIdentity returns the identity ID for an identity flag value (see findIdentity)
+func resolveIdentity(ctx context.Context, client *azdo.Client, project, value string) (string, error) {
+	identity, err := findIdentity(ctx, client, project, value)
+	if err != nil {
+		return "", err
+	}
+	return identity.ID, nil
+}
+
+// findIdentity looks up the user or group for an identity flag value: "me" for the
+// authenticated user, an identity ID, an email address, a display name or a group name.
+// Names without a scope are also looked up as teams of project, i.e. "[project]\name".
+func findIdentity(ctx context.Context, client *azdo.Client, project, value string) (*azdo.Identity, error) {
+	value = strings.TrimSpace(value)
+	if strings.EqualFold(value, meIdentity) {
+		connection, err := client.GetConnectionData(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &connection.AuthenticatedUser, nil
+	}
+	if identityIDPattern.MatchString(value) {
+		return &azdo.Identity{ID: value}, nil
+	}
+
+	searches := []string{value}
+	if project != "" && !strings.ContainsAny(value, `\@`) {
+		searches = append(searches, fmt.Sprintf(`[%s]\%s`, project, value))
+	}
+	for _, search := range searches {
+		identities, err := client.SearchIdentities(ctx, azdo.IdentitySearchGeneral, search)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %q: %w", value, err)
+		}
+		identity, err := matchIdentity(value, identities)
+		if err != nil || identity != nil {
+			return identity, err
+		}
+	}
+
+	return nil, fmt.Errorf("could not resolve %q to an Azure DevOps user or group. Use an email address, display name, team name or identity ID", value)
+}
+
+// matchIdentity picks the identity value refers to from search results. Several
+// results are narrowed down to the one whose account or name is value. It returns nil
+// when nothing matches, and an error when value is ambiguous.
+func matchIdentity(value string, identities []azdo.Identity) (*azdo.Identity, error) {
+	var active []azdo.Identity
+	for _, identity := range identities {
+		if identity.IsActive || identity.IsContainer {
+			active = append(active, identity)
+		}
+	}
+
+	switch len(active) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &active[0], nil
+	}
+
+	var exact []azdo.Identity
+	for _, identity := range active {
+		name := strings.ToLower(identity.DisplayName())
+		if strings.EqualFold(identity.Account(), value) || name == strings.ToLower(value) ||
+			strings.HasSuffix(name, `\`+strings.ToLower(value)) {
+			exact = append(exact, identity)
+		}
+	}
+	if len(exact) == 1 {
+		return &exact[0], nil
+	}
+
+	names := make([]string, len(active))
+	for i, identity := range active {
+		names[i] = identity.DisplayName()
+		if account := identity.Account(); account != "" {
+			names[i] += " <" + account + ">"
+		}
+	}
+	return nil, fmt.Errorf("%q matches several identities: %s. Use an email address or identity ID", value, strings.Join(names, ", "))
+}
+
+// splitIdentities splits a comma-separated list of identities from the config
+func splitIdentities(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// resolveReviewers resolves the reviewers of a new pull request. A reviewer given as
+// both optional and required is added once, as required.
+func resolveReviewers(ctx context.Context, client *azdo.Client, project string, optional, required []string) ([]azdo.Reviewer, error) {
+	var reviewers []azdo.Reviewer
+	index := map[string]int{}
+
+	add := func(values []string, isRequired bool) error {
+		for _, value := range values {
+			identity, err := findIdentity(ctx, client, project, value)
+			if err != nil {
+				return fmt.Errorf("invalid reviewer: %w", err)
+			}
+			key := strings.ToLower(identity.ID)
+			if i, ok := index[key]; ok {
+				reviewers[i].IsRequired = reviewers[i].IsRequired || isRequired
+				continue
+			}
+
+			name := identity.DisplayName()
+			if name == "" {
+				name = value
+			}
+			index[key] = len(reviewers)
+			reviewers = append(reviewers, azdo.Reviewer{
+				IdentityRef: azdo.IdentityRef{ID: identity.ID, DisplayName: name},
+				IsRequired:  isRequired,
+			})
+		}
+		return nil
+	}
+
+	if err := add(required, true); err != nil {
+		return nil, err
+	}
+	if err := add(optional, false); err != nil {
+		return nil, err
+	}
+	return reviewers, nil
+}
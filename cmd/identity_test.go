@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// identityHandler answers identity searches like Azure DevOps for a user, a team and
+// two users sharing a name
+func identityHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/myorg/_apis/identities" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch r.URL.Query().Get("filterValue") {
+	case "john@contoso.com", "John Smith":
+		w.Write([]byte(`{"value": [{"id": "u2", "providerDisplayName": "John Smith", "isActive": true,
+			"properties": {"Account": {"$value": "john@contoso.com"}}}]}`))
+	case `[proj]\Web Team`:
+		w.Write([]byte(`{"value": [{"id": "t1", "providerDisplayName": "[proj]\\Web Team", "isContainer": true}]}`))
+	case "Alex":
+		w.Write([]byte(`{"value": [
+			{"id": "u3", "providerDisplayName": "Alex Brown", "isActive": true, "properties": {"Account": {"$value": "alex.brown@contoso.com"}}},
+			{"id": "u4", "providerDisplayName": "Alex Green", "isActive": true, "properties": {"Account": {"$value": "alex.green@contoso.com"}}}]}`))
+	default:
+		w.Write([]byte(`{"value": []}`))
+	}
+}
+
+func TestResolveIdentity(t *testing.T) {
+	setupPRTest(t, identityHandler)
+	client := azdo.NewClient(serverURL, "myorg", "test-token", nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		value   string
+		wantID  string
+		wantErr string
+	}{
+		{value: "Me", wantID: testUserID},
+		{value: "3F2504E0-4F89-11D3-9A0C-0305E82C3301", wantID: "3F2504E0-4F89-11D3-9A0C-0305E82C3301"},
+		{value: "john@contoso.com", wantID: "u2"},
+		{value: " John Smith ", wantID: "u2"},
+		{value: "Web Team", wantID: "t1"},
+		{value: "Alex", wantErr: "matches several identities: Alex Brown <alex.brown@contoso.com>, Alex Green <alex.green@contoso.com>"},
+		{value: "nobody@contoso.com", wantErr: `could not resolve "nobody@contoso.com"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			id, err := resolveIdentity(ctx, client, "proj", tt.value)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, id)
+		})
+	}
+}
+
+func TestMatchIdentity(t *testing.T) {
+	identities := []azdo.Identity{
+		{ID: "u1", ProviderDisplayName: "Jane Doe", IsActive: true},
+		{ID: "u2", ProviderDisplayName: "Jane Doerr", IsActive: true},
+		{ID: "u3", ProviderDisplayName: "Jane Doe (former)"},
+	}
+
+	identity, err := matchIdentity("jane doe", identities)
+	require.NoError(t, err)
+	assert.Equal(t, "u1", identity.ID)
+
+	identity, err = matchIdentity("Jane", identities[1:])
+	require.NoError(t, err)
+	assert.Equal(t, "u2", identity.ID, "inactive identities are ignored")
+
+	identity, err = matchIdentity("Jane", nil)
+	require.NoError(t, err)
+	assert.Nil(t, identity)
+
+	_, err = matchIdentity("Jane", identities)
+	assert.Error(t, err)
+}
+
+func TestSplitIdentities(t *testing.T) {
+	assert.Equal(t, []string{"jane@contoso.com", "Web Team"}, splitIdentities(" jane@contoso.com, ,Web Team "))
+	assert.Nil(t, splitIdentities(""))
+}
+
+func TestResolveReviewers(t *testing.T) {
+	setupPRTest(t, identityHandler)
+	client := azdo.NewClient(serverURL, "myorg", "test-token", nil)
+	ctx := context.Background()
+
+	reviewers, err := resolveReviewers(ctx, client, "proj",
+		[]string{"john@contoso.com", "Web Team"}, []string{"John Smith"})
+	require.NoError(t, err)
+	require.Len(t, reviewers, 2)
+	assert.Equal(t, "u2", reviewers[0].ID)
+	assert.True(t, reviewers[0].IsRequired, "a reviewer given as required stays required")
+	assert.Equal(t, "t1", reviewers[1].ID)
+	assert.Equal(t, `[proj]\Web Team`, reviewers[1].DisplayName)
+	assert.False(t, reviewers[1].IsRequired)
+
+	_, err = resolveReviewers(ctx, client, "proj", []string{"nobody@contoso.com"}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid reviewer")
+}
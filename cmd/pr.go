@@ -1,13 +1,14 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
 
-	"github.com/chriskievit/dex-cli/internal/auth"
 	"github.com/chriskievit/dex-cli/internal/azdo"
 	"github.com/chriskievit/dex-cli/internal/config"
 	"github.com/chriskievit/dex-cli/internal/git"
@@ -21,8 +22,23 @@ var (
 	prDesc       string
 	workItemID   int
 	isDraft      bool
+
+	prReviewers         []string
+	prRequiredReviewers []string
+
+	autoComplete     bool
+	createCompletion completionFlags
+
+	updateExisting bool
+
+	appendCommitLog    bool
+	appendWorkItemLink bool
 )
 
+// defaultTitleFormat is the title of a pull request for a work item when title_format
+// is not configured
+const defaultTitleFormat = "#{id} {title}"
+
 var prCmd = &cobra.Command{
 	Use:   "pr",
 	Short: "Manage pull requests",
@@ -35,48 +51,135 @@ var createPRCmd = &cobra.Command{
 	Long: `Create a new pull request in Azure DevOps.
 
 The source branch defaults to your current Git branch.
+The target branch defaults to target_branch from the config (e.g. a repository's .dex.yaml).
 Work item ID will be automatically extracted from the branch name if it follows the naming convention.
+The title defaults to the work item title, formatted with title_format from the config
+(default "#{id} {title}"), or else to the subject of the only commit on the source branch.
 If no description is provided, the command will automatically look for a PR template in:
   - .azuredevops/pull_request_template.md
   - .github/pull_request_template.md
   - pull_request_template.md (repository root)
 
+Reviewers are added from --reviewer, --required-reviewer and the default_reviewer and
+required_reviewers config values. They can be email addresses, display names, team or
+group names, or identity IDs.
+
+With --commit-log and --workitem-link, the commits of the source branch and a link to the
+work item are appended to the description.
+
+With --auto-complete, the pull request completes automatically once its policies pass,
+merged as chosen with --strategy, --delete-source-branch and --complete-work-items.
+
+Only one active pull request can exist from a source branch into a target branch. If it
+already exists, it is reported instead; with --update, it is updated instead: the title and
+description when given, the draft state when --draft is given, the work item link, missing
+reviewers and auto-complete.
+
 Example:
+  dex-cli pr create
   dex-cli pr create --target main --title "Add login feature"
-  dex-cli pr create --source feature/123/login --target main --title "Add login" --workitem 123`,
+  dex-cli pr create --source feature/123/login --target main --title "Add login" --workitem 123
+  dex-cli pr create --title "Add login" --auto-complete --strategy squash --delete-source-branch`,
 	RunE: runCreatePR,
 }
 
+var ensurePRCmd = &cobra.Command{
+	Use:   "ensure",
+	Short: "Create a pull request unless one already exists",
+	Long: `Make sure an active pull request exists from the source branch into the target branch.
+
+Like 'pr create', but when the pull request already exists, it is reported and the command
+succeeds, which makes it safe to run from scripts and git push hooks. With --update, the
+existing pull request is updated as 'pr create --update' does.
+
+Example:
+  dex-cli pr ensure --title "Add login feature"
+  dex-cli pr ensure --title "Add login feature" --update --reviewer jane@example.com`,
+	RunE: runEnsurePR,
+}
+
 func init() {
 	rootCmd.AddCommand(prCmd)
 	prCmd.AddCommand(createPRCmd)
+	prCmd.AddCommand(ensurePRCmd)
 
-	createPRCmd.Flags().StringVarP(&sourceBranch, "source", "s", "", "Source branch (defaults to current branch)")
-	createPRCmd.Flags().StringVarP(&targetBranch, "target", "t", "", "Target branch (required)")
-	createPRCmd.Flags().StringVar(&prTitle, "title", "", "Pull request title (required)")
-	createPRCmd.Flags().StringVar(&prDesc, "description", "", "Pull request description")
-	createPRCmd.Flags().IntVarP(&workItemID, "workitem", "w", 0, "Work item ID to link (auto-detected from branch name)")
-	createPRCmd.Flags().BoolVar(&isDraft, "draft", false, "Create as draft pull request")
+	registerCreateFlags(createPRCmd)
+	registerCreateFlags(ensurePRCmd)
+}
 
-	createPRCmd.MarkFlagRequired("target")
-	createPRCmd.MarkFlagRequired("title")
+// registerCreateFlags adds the flags shared by pr create and pr ensure to cmd
+func registerCreateFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&sourceBranch, "source", "s", "", "Source branch (defaults to current branch)")
+	cmd.Flags().StringVarP(&targetBranch, "target", "t", "", "Target branch (defaults to target_branch from config)")
+	cmd.Flags().StringVar(&prTitle, "title", "", "Pull request title (defaults to the work item title or the only commit subject)")
+	cmd.Flags().StringVar(&prDesc, "description", "", "Pull request description")
+	cmd.Flags().IntVarP(&workItemID, "workitem", "w", 0, "Work item ID to link (auto-detected from branch name)")
+	cmd.Flags().BoolVar(&isDraft, "draft", false, "Create as draft pull request")
+	cmd.Flags().StringArrayVarP(&prReviewers, "reviewer", "r", nil, "Reviewer to add: email, name, team or identity ID (repeatable)")
+	cmd.Flags().StringArrayVar(&prRequiredReviewers, "required-reviewer", nil, "Required reviewer to add (repeatable)")
+	cmd.Flags().BoolVar(&autoComplete, "auto-complete", false, "Complete automatically once policies pass")
+	cmd.Flags().BoolVar(&updateExisting, "update", false, "Update the pull request if it already exists")
+	cmd.Flags().BoolVar(&appendCommitLog, "commit-log", false, "Append the commits of the source branch to the description")
+	cmd.Flags().BoolVar(&appendWorkItemLink, "workitem-link", false, "Append a link to the work item to the description")
+	createCompletion.register(cmd)
 }
 
 func runCreatePR(cmd *cobra.Command, args []string) error {
+	return createPullRequest(cmd, false)
+}
+
+func runEnsurePR(cmd *cobra.Command, args []string) error {
+	return createPullRequest(cmd, true)
+}
+
+// createPullRequest creates a pull request from the pr create flags. An existing active
+// pull request for the same branches is updated with --update; otherwise it is an error,
+// unless ensure is set.
+func createPullRequest(cmd *cobra.Command, ensure bool) error {
+	ctx := commandContext(cmd)
+
+	// Completion options only apply with --auto-complete
+	if autoComplete {
+		if _, err := createCompletion.options(); err != nil {
+			return err
+		}
+	} else {
+		for _, name := range []string{"strategy", "delete-source-branch", "complete-work-items"} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--%s requires --auto-complete", name)
+			}
+		}
+	}
+
 	// Check if we're in a Git repository
 	cwd, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("failed to get current directory: %w", err)
 	}
 
-	if !git.IsGitRepository(cwd) {
+	if !git.IsGitRepository(ctx, cwd) {
 		return fmt.Errorf("not a git repository. Please run this command from within a git repository")
 	}
 
+	// Load config
+	cfg, err := loadConfig(ctx, cwd)
+	if err != nil {
+		return err
+	}
+
+	// Determine target branch
+	prTarget := targetBranch
+	if prTarget == "" {
+		prTarget = cfg.TargetBranch
+	}
+	if prTarget == "" {
+		return fmt.Errorf("target branch not configured. Use --target flag or set target_branch in config")
+	}
+
 	// Determine source branch
 	source := sourceBranch
 	if source == "" {
-		source, err = git.GetCurrentBranch(cwd)
+		source, err = git.GetCurrentBranch(ctx, cwd)
 		if err != nil {
 			return fmt.Errorf("failed to get current branch: %w", err)
 		}
@@ -86,65 +189,108 @@ func runCreatePR(cmd *cobra.Command, args []string) error {
 	}
 
 	// Validate source != target
-	if source == targetBranch {
+	if source == prTarget {
 		return fmt.Errorf("source branch cannot be the same as target branch: %s", source)
 	}
 
 	// Extract work item ID from branch name if not provided
 	wiID := workItemID
 	if wiID == 0 {
-		wiID = extractWorkItemFromBranch(source)
+		wiID = workItemIDFromBranch(source, cfg.BranchFormat)
 		if wiID > 0 && debug {
 			fmt.Printf("Extracted work item ID from branch name: %d\n", wiID)
 		}
 	}
 
-	// Load config
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
-	}
-
-	org := organization
-	if org == "" {
-		org = cfg.Organization
-	}
+	t := resolveTarget(ctx, cfg.Config, cwd)
+	server, org, proj, repo := t.Server, t.Organization, t.Project, t.Repository
 	if org == "" {
 		return fmt.Errorf("organization not configured. Use --org flag or run 'dex-cli auth login'")
 	}
-
-	proj := project
 	if proj == "" {
-		proj = cfg.Project
+		return fmt.Errorf("project not configured. Use --project flag, set in config, or run from a clone of an Azure DevOps repository")
 	}
-	if proj == "" {
-		return fmt.Errorf("project not configured. Use --project flag or set in config")
-	}
-
-	repo := cfg.Repository
 	if repo == "" {
-		return fmt.Errorf("repository not configured. Please set in config file at %s", config.GetConfigDir())
+		return fmt.Errorf("repository not configured. Run from a clone of an Azure DevOps repository or set it in the config file at %s", config.GetConfigDir())
 	}
 
-	// Get authentication token
-	token, err := auth.GetToken(org, debug)
+	// Create an Azure DevOps client with the credentials in effect
+	client, err := authenticatedClient(cfg.Config, server, org)
 	if err != nil {
 		return err
 	}
 
-	// Create Azure DevOps client
-	client := azdo.NewClient(org, token, debug)
-
 	// Get repository information
 	if debug {
 		fmt.Printf("Getting repository information for: %s\n", repo)
 	}
 
-	repository, err := client.GetRepository(proj, repo)
+	repository, err := client.GetRepository(ctx, proj, repo)
 	if err != nil {
+		if azdo.IsNotFound(err) {
+			return fmt.Errorf("repository %q not found in project %q: %w", repo, proj, err)
+		}
 		return fmt.Errorf("failed to get repository: %w", err)
 	}
 
+	// Azure DevOps rejects a second active pull request for the same branches
+	existing, err := findPullRequest(ctx, client, proj, repository.ID, source, prTarget)
+	if err != nil {
+		return err
+	}
+	if existing != nil && !updateExisting {
+		if ensure {
+			fmt.Printf("✓ Pull request #%d already exists\n", existing.PullRequestID)
+			fmt.Printf("  Title: %s\n", existing.Title)
+			fmt.Printf("  URL: %s\n", client.PullRequestWebURL(proj, repo, existing.PullRequestID))
+			return nil
+		}
+		return fmt.Errorf("pull request #%d from %s into %s already exists: %s\nUse --update to update it",
+			existing.PullRequestID, source, prTarget, client.PullRequestWebURL(proj, repo, existing.PullRequestID))
+	}
+
+	// Resolve reviewers before creating anything, so a typo does not leave a pull request behind
+	reviewers, err := resolveReviewers(ctx, client, proj,
+		append(splitIdentities(cfg.DefaultReviewer), prReviewers...),
+		append(splitIdentities(cfg.RequiredReviewers), prRequiredReviewers...))
+	if err != nil {
+		return err
+	}
+
+	if existing != nil {
+		if err := updateExistingPR(ctx, cmd, client, proj, existing, wiID, reviewers); err != nil {
+			return err
+		}
+		if autoComplete {
+			return setAutoComplete(ctx, client, proj, repository.ID, existing.PullRequestID, &createCompletion)
+		}
+		return nil
+	}
+
+	// The work item and commits provide the default title and the description additions
+	var workItem *azdo.WorkItem
+	if wiID > 0 && (prTitle == "" || appendWorkItemLink) {
+		workItem, err = client.GetWorkItem(ctx, wiID)
+		if err != nil {
+			return fmt.Errorf("failed to get work item #%d: %w", wiID, err)
+		}
+	}
+	var commits []string
+	if (prTitle == "" && (workItem == nil || workItem.GetTitle() == "")) || appendCommitLog {
+		commits, err = branchCommitSubjects(ctx, cwd, source, prTarget)
+		if err != nil {
+			return err
+		}
+	}
+
+	title := prTitle
+	if title == "" {
+		title, err = defaultTitle(cfg.TitleFormat, workItem, commits)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Load PR template if no description provided
 	description := prDesc
 	if description == "" {
@@ -160,11 +306,19 @@ func runCreatePR(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if appendCommitLog {
+		description = appendSection(description, commitLog(commits))
+	}
+	if appendWorkItemLink && workItem != nil {
+		description = appendSection(description, fmt.Sprintf("Work item: [#%d %s](%s)",
+			wiID, workItem.GetTitle(), client.WorkItemWebURL(wiID)))
+	}
+
 	// Prepare PR request
 	prRequest := &azdo.CreatePRRequest{
 		SourceRefName: azdo.FormatRefName(source),
-		TargetRefName: azdo.FormatRefName(targetBranch),
-		Title:         prTitle,
+		TargetRefName: azdo.FormatRefName(prTarget),
+		Title:         title,
 		Description:   description,
 		IsDraft:       isDraft,
 	}
@@ -178,29 +332,230 @@ func runCreatePR(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	for _, reviewer := range reviewers {
+		prRequest.Reviewers = append(prRequest.Reviewers, map[string]interface{}{
+			"id":         reviewer.ID,
+			"isRequired": reviewer.IsRequired,
+		})
+	}
+
 	// Create pull request
 	fmt.Printf("Creating pull request...\n")
 	fmt.Printf("  Source: %s\n", source)
-	fmt.Printf("  Target: %s\n", targetBranch)
-	fmt.Printf("  Title: %s\n", prTitle)
+	fmt.Printf("  Target: %s\n", prTarget)
+	fmt.Printf("  Title: %s\n", title)
+	if len(reviewers) > 0 {
+		names := make([]string, len(reviewers))
+		for i, reviewer := range reviewers {
+			names[i] = reviewer.DisplayName
+			if reviewer.IsRequired {
+				names[i] += " (required)"
+			}
+		}
+		fmt.Printf("  Reviewers: %s\n", strings.Join(names, ", "))
+	}
 	if wiID > 0 {
 		fmt.Printf("  Work Item: #%d\n", wiID)
 	} else {
 		fmt.Printf("  ⚠ Warning: No work item linked (branch name doesn't match format or --workitem not provided)\n")
 	}
 
-	pr, err := client.CreatePullRequest(proj, repository.ID, prRequest)
+	pr, err := client.CreatePullRequest(ctx, proj, repository.ID, prRequest)
 	if err != nil {
+		if azdo.IsConflict(err) {
+			return fmt.Errorf("failed to create pull request, an active pull request from %s into %s may have been created meanwhile: %w", source, prTarget, err)
+		}
 		return fmt.Errorf("failed to create pull request: %w", err)
 	}
 
 	fmt.Printf("\n✓ Successfully created pull request #%d\n", pr.PullRequestID)
-	fmt.Printf("  URL: https://dev.azure.com/%s/%s/_git/%s/pullrequest/%d\n",
-		org, proj, repo, pr.PullRequestID)
+	fmt.Printf("  URL: %s\n", client.PullRequestWebURL(proj, repo, pr.PullRequestID))
+
+	// Auto-complete cannot be set on creation, only on the existing pull request
+	if autoComplete {
+		if err := setAutoComplete(ctx, client, proj, repository.ID, pr.PullRequestID, &createCompletion); err != nil {
+			return fmt.Errorf("pull request #%d was created, but setting auto-complete failed: %w", pr.PullRequestID, err)
+		}
+	}
+
+	return nil
+}
+
+// findPullRequest returns the active pull request from source into target in a
+// repository, or nil if there is none
+func findPullRequest(ctx context.Context, client *azdo.Client, project, repoID, source, target string) (*azdo.PullRequest, error) {
+	criteria := azdo.PullRequestSearchCriteria{
+		Status:        azdo.PullRequestStatusActive,
+		SourceRefName: azdo.FormatRefName(source),
+		TargetRefName: azdo.FormatRefName(target),
+		RepositoryID:  repoID,
+	}
+	pullRequests, err := client.ListPullRequests(ctx, project, criteria, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look for an existing pull request: %w", err)
+	}
+	if len(pullRequests) == 0 {
+		return nil, nil
+	}
+	// The list omits details such as the description, so fetch the pull request itself
+	return client.GetPullRequest(ctx, project, pullRequests[0].PullRequestID)
+}
+
+// updateExistingPR brings an existing pull request in line with the pr create flags: the
+// title and description when given, the draft state when --draft is given, the work item
+// link and the reviewers. Reviewers who are already on the pull request keep their vote.
+func updateExistingPR(ctx context.Context, cmd *cobra.Command, client *azdo.Client, proj string, pr *azdo.PullRequest, wiID int, reviewers []azdo.Reviewer) error {
+	var changes []string
+	update := &azdo.PullRequestUpdate{}
+	if prTitle != "" && prTitle != pr.Title {
+		update.Title = prTitle
+		changes = append(changes, fmt.Sprintf("Title: %s", prTitle))
+	}
+	if prDesc != "" && prDesc != pr.Description {
+		update.Description = &prDesc
+		changes = append(changes, "Description: updated")
+	}
+	if cmd.Flags().Changed("draft") && isDraft != pr.IsDraft {
+		update.IsDraft = &isDraft
+		if isDraft {
+			changes = append(changes, "Marked as draft")
+		} else {
+			changes = append(changes, "Published for review")
+		}
+	}
+	if len(changes) > 0 {
+		if _, err := client.UpdatePullRequest(ctx, proj, pr.Repository.ID, pr.PullRequestID, update); err != nil {
+			return err
+		}
+	}
+
+	if wiID > 0 {
+		linked, err := linkedWorkItems(ctx, client, proj, pr)
+		if err != nil {
+			return err
+		}
+		if !linked[wiID] {
+			if err := linkWorkItem(ctx, client, pr, wiID); err != nil {
+				return err
+			}
+			changes = append(changes, fmt.Sprintf("Linked work item #%d", wiID))
+		}
+	}
+
+	current := map[string]bool{}
+	for _, reviewer := range pr.Reviewers {
+		current[strings.ToLower(reviewer.ID)] = true
+	}
+	for _, reviewer := range reviewers {
+		if current[strings.ToLower(reviewer.ID)] {
+			continue
+		}
+		if _, err := client.AddReviewer(ctx, proj, pr.Repository.ID, pr.PullRequestID, reviewer.ID, reviewer.IsRequired); err != nil {
+			return fmt.Errorf("failed to add reviewer %s: %w", reviewer.DisplayName, err)
+		}
+		change := "Added reviewer " + reviewer.DisplayName
+		if reviewer.IsRequired {
+			change += " (required)"
+		}
+		changes = append(changes, change)
+	}
 
+	if len(changes) == 0 {
+		fmt.Printf("Pull request #%d is already up to date\n", pr.PullRequestID)
+	} else {
+		fmt.Printf("✓ Updated pull request #%d\n", pr.PullRequestID)
+		for _, change := range changes {
+			fmt.Printf("  %s\n", change)
+		}
+	}
+	fmt.Printf("  URL: %s\n", client.PullRequestWebURL(proj, pr.Repository.Name, pr.PullRequestID))
 	return nil
 }
 
+// defaultTitle returns the title of a pull request created without --title: the work
+// item title in format, or else the subject of the only commit on the source branch
+func defaultTitle(format string, workItem *azdo.WorkItem, commits []string) (string, error) {
+	if workItem != nil && workItem.GetTitle() != "" {
+		if format == "" {
+			format = defaultTitleFormat
+		}
+		workItemType, _ := workItem.Fields["System.WorkItemType"].(string)
+		replacer := strings.NewReplacer(
+			"{id}", strconv.Itoa(workItem.ID),
+			"{type}", workItemType,
+			"{title}", workItem.GetTitle(),
+		)
+		return strings.TrimSpace(replacer.Replace(format)), nil
+	}
+
+	switch len(commits) {
+	case 0:
+		return "", fmt.Errorf("--title is required: no work item is linked and the source branch has no new commits")
+	case 1:
+		return commits[0], nil
+	}
+	return "", fmt.Errorf("--title is required: no work item is linked and the source branch has %d commits", len(commits))
+}
+
+// branchCommitSubjects returns the subjects of the commits on source that are not on
+// target, oldest first. The target is taken from origin when it has been fetched, since
+// a local branch may be out of date; the source is taken from origin when it is not local.
+func branchCommitSubjects(ctx context.Context, dir, source, target string) ([]string, error) {
+	base, err := branchRef(ctx, dir, target, true)
+	if err != nil {
+		return nil, err
+	}
+	head, err := branchRef(ctx, dir, source, false)
+	if err != nil {
+		return nil, err
+	}
+	return git.CommitSubjects(ctx, dir, base, head)
+}
+
+// branchRef returns the ref of a local branch or its remote-tracking branch on origin,
+// whichever exists, checking the remote-tracking branch first with preferRemote
+func branchRef(ctx context.Context, dir, branch string, preferRemote bool) (string, error) {
+	refs := []string{"refs/heads/" + branch, "refs/remotes/origin/" + branch}
+	if preferRemote {
+		refs[0], refs[1] = refs[1], refs[0]
+	}
+	for _, ref := range refs {
+		exists, err := git.BranchExists(ctx, dir, ref)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return ref, nil
+		}
+	}
+	return "", fmt.Errorf("branch %s not found locally or on origin", branch)
+}
+
+// commitLog formats commit subjects as a Markdown list for a description
+func commitLog(commits []string) string {
+	if len(commits) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("## Commits\n")
+	for _, subject := range commits {
+		b.WriteString("\n- " + subject)
+	}
+	return b.String()
+}
+
+// appendSection appends a section to a description, separated by a blank line
+func appendSection(description, section string) string {
+	description = strings.TrimRight(description, " \t\r\n")
+	if section == "" {
+		return description
+	}
+	if description == "" {
+		return section
+	}
+	return description + "\n\n" + section
+}
+
 // extractWorkItemFromBranch attempts to extract work item ID from branch name
 // Expected format: {type}/{id}/{description}
 func extractWorkItemFromBranch(branchName string) int {
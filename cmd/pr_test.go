@@ -1,10 +1,15 @@
 package cmd
 
 import (
+	"encoding/json"
+	"net/http"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/chriskievit/dex-cli/internal/config"
+	"github.com/chriskievit/dex-cli/internal/testhelpers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -306,3 +311,186 @@ func TestLoadPRTemplate(t *testing.T) {
 		})
 	}
 }
+
+// setupCreateTest prepares runCreatePR in a git repository on branch feature/1/login of
+// repository web-app, against a fake server handling the requests after the repository
+// lookup; the repository request itself is answered here. It returns the repository.
+func setupCreateTest(t *testing.T, handler http.HandlerFunc) string {
+	t.Helper()
+
+	repoDir, cleanup := testhelpers.SetupTempGitRepo(t)
+	t.Cleanup(cleanup)
+	testhelpers.CreateBranch(t, repoDir, "feature/1/login")
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(repoDir))
+	t.Cleanup(func() { os.Chdir(oldDir) })
+
+	setupPRTest(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/myorg/proj/_apis/git/repositories/web-app" {
+			w.Write([]byte(`{"id": "repo-1", "name": "web-app", "project": {"id": "p-1"}}`))
+			return
+		}
+		handler(w, r)
+	})
+	t.Setenv(config.EnvVar("repository"), "web-app")
+
+	// Without a config file, one is written with the values other tests left in viper
+	require.NoError(t, os.MkdirAll(config.GetConfigDir(), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(config.GetConfigDir(), "config.yaml"), []byte("organization: myorg\n"), 0600))
+
+	oldSource, oldTarget, oldTitle, oldDesc, oldWorkItem, oldDraft := sourceBranch, targetBranch, prTitle, prDesc, workItemID, isDraft
+	oldReviewers, oldRequired, oldAutoComplete, oldUpdate := prReviewers, prRequiredReviewers, autoComplete, updateExisting
+	oldCommitLog, oldWorkItemLink := appendCommitLog, appendWorkItemLink
+	t.Cleanup(func() {
+		sourceBranch, targetBranch, prTitle, prDesc, workItemID, isDraft = oldSource, oldTarget, oldTitle, oldDesc, oldWorkItem, oldDraft
+		prReviewers, prRequiredReviewers, autoComplete, updateExisting = oldReviewers, oldRequired, oldAutoComplete, oldUpdate
+		appendCommitLog, appendWorkItemLink = oldCommitLog, oldWorkItemLink
+	})
+	sourceBranch, targetBranch, prTitle, prDesc, workItemID, isDraft = "", "main", "Add login", "", 0, false
+	prReviewers, prRequiredReviewers, autoComplete, updateExisting = nil, nil, false, false
+	appendCommitLog, appendWorkItemLink = false, false
+	return repoDir
+}
+
+func TestRunCreatePR_Existing(t *testing.T) {
+	const reviewerID = "11111111-2222-3333-4444-555555555555"
+	var query, update map[string]interface{}
+	var requests []string
+	setupCreateTest(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/myorg/proj/_apis/git/pullrequests":
+			query = map[string]interface{}{}
+			for key, values := range r.URL.Query() {
+				query[key] = values[0]
+			}
+			w.Write([]byte(`{"value": [{"pullRequestId": 7}]}`))
+		case "/myorg/proj/_apis/git/pullrequests/7":
+			w.Write([]byte(`{"pullRequestId": 7, "status": "active", "title": "Login",
+				"sourceRefName": "refs/heads/feature/1/login", "targetRefName": "refs/heads/main",
+				"reviewers": [{"id": "` + testUserID + `", "vote": 10}],
+				"repository": {"id": "repo-1", "name": "web-app", "project": {"id": "p-1"}}}`))
+		case "/myorg/proj/_apis/git/repositories/repo-1/pullrequests/7":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&update))
+			w.Write([]byte(`{"pullRequestId": 7}`))
+		case "/myorg/proj/_apis/git/repositories/repo-1/pullrequests/7/workitems":
+			w.Write([]byte(`{"value": []}`))
+		case "/myorg/_apis/wit/workitems/1":
+			w.Write([]byte(`{"id": 1}`))
+		case "/myorg/proj/_apis/git/repositories/repo-1/pullrequests/7/reviewers/" + reviewerID:
+			w.Write([]byte(`{"id": "` + reviewerID + `"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	err := runCreatePR(createPRCmd, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pull request #7 from feature/1/login into main already exists")
+	assert.Contains(t, err.Error(), "--update")
+	assert.Equal(t, "refs/heads/feature/1/login", query["searchCriteria.sourceRefName"])
+	assert.Equal(t, "refs/heads/main", query["searchCriteria.targetRefName"])
+	assert.Equal(t, "repo-1", query["searchCriteria.repositoryId"])
+
+	requests = nil
+	require.NoError(t, runEnsurePR(ensurePRCmd, nil))
+	assert.Equal(t, []string{"GET /myorg/proj/_apis/git/pullrequests", "GET /myorg/proj/_apis/git/pullrequests/7"}, requests)
+
+	// Only the reviewer who is not on the pull request yet is added, so votes are kept
+	requests, updateExisting = nil, true
+	prReviewers = []string{reviewerID, meIdentity}
+	require.NoError(t, runEnsurePR(ensurePRCmd, nil))
+	assert.Equal(t, map[string]interface{}{"title": "Add login"}, update)
+	assert.Contains(t, requests, "PATCH /myorg/_apis/wit/workitems/1")
+	assert.Contains(t, requests, "PUT /myorg/proj/_apis/git/repositories/repo-1/pullrequests/7/reviewers/"+reviewerID)
+	for _, request := range requests {
+		assert.NotContains(t, request, testUserID)
+		assert.NotEqual(t, "POST /myorg/proj/_apis/git/repositories/repo-1/pullrequests", request)
+	}
+}
+
+func TestRunCreatePR_New(t *testing.T) {
+	var created map[string]interface{}
+	setupCreateTest(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/myorg/proj/_apis/git/pullrequests":
+			w.Write([]byte(`{"value": []}`))
+		case "/myorg/proj/_apis/git/repositories/repo-1/pullrequests":
+			require.Equal(t, http.MethodPost, r.Method)
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			w.Write([]byte(`{"pullRequestId": 8}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	require.NoError(t, runEnsurePR(ensurePRCmd, nil))
+	require.NotNil(t, created)
+	assert.Equal(t, "Add login", created["title"])
+	assert.Equal(t, "refs/heads/feature/1/login", created["sourceRefName"])
+}
+
+func TestRunCreatePR_DefaultTitle(t *testing.T) {
+	var created map[string]interface{}
+	repoDir := setupCreateTest(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/myorg/proj/_apis/git/pullrequests":
+			w.Write([]byte(`{"value": []}`))
+		case "/myorg/_apis/wit/workitems/1":
+			w.Write([]byte(`{"id": 1, "fields": {"System.Title": "Add login", "System.WorkItemType": "User Story"}}`))
+		case "/myorg/proj/_apis/git/repositories/repo-1/pullrequests":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			w.Write([]byte(`{"pullRequestId": 8}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	testhelpers.CreateCommit(t, repoDir, "Add login form")
+	testhelpers.CreateCommit(t, repoDir, "Validate the password")
+
+	prTitle, prDesc, appendCommitLog, appendWorkItemLink = "", "Adds the login page\n", true, true
+	require.NoError(t, runCreatePR(createPRCmd, nil))
+	assert.Equal(t, "#1 Add login", created["title"])
+	assert.Equal(t, "Adds the login page\n\n## Commits\n\n- Add login form\n- Validate the password\n\n"+
+		"Work item: [#1 Add login]("+serverURL+"/myorg/_workitems/edit/1)", created["description"])
+
+	// Without a work item, a branch with several commits needs a title
+	runGit(t, repoDir, "branch", "login", "feature/1/login")
+	sourceBranch = "login"
+	err := runCreatePR(createPRCmd, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--title is required")
+}
+
+func TestDefaultTitle(t *testing.T) {
+	workItem := &azdo.WorkItem{ID: 123, Fields: map[string]interface{}{"System.Title": "Add login", "System.WorkItemType": "Bug"}}
+
+	tests := []struct {
+		name     string
+		format   string
+		workItem *azdo.WorkItem
+		commits  []string
+		want     string
+		wantErr  bool
+	}{
+		{name: "work item", workItem: workItem, commits: []string{"a", "b"}, want: "#123 Add login"},
+		{name: "custom format", format: "{type} {id}: {title}", workItem: workItem, want: "Bug 123: Add login"},
+		{name: "single commit", commits: []string{"Fix the typo"}, want: "Fix the typo"},
+		{name: "several commits", commits: []string{"a", "b"}, wantErr: true},
+		{name: "no commits", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, err := defaultTitle(tt.format, tt.workItem, tt.commits)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, title)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/spf13/cobra"
+)
+
+var abandonPRCmd = &cobra.Command{
+	Use:   "abandon <id>",
+	Short: "Abandon a pull request",
+	Long: `Abandon an active pull request without merging it. It can be reactivated later with
+'dex pr reactivate'.
+
+Example:
+  dex pr abandon 1234`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAbandonPR,
+}
+
+var reactivatePRCmd = &cobra.Command{
+	Use:   "reactivate <id>",
+	Short: "Reactivate an abandoned pull request",
+	Long: `Reactivate an abandoned pull request, so it can be reviewed and merged again.
+
+Example:
+  dex pr reactivate 1234`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReactivatePR,
+}
+
+func init() {
+	prCmd.AddCommand(abandonPRCmd)
+	prCmd.AddCommand(reactivatePRCmd)
+}
+
+func runAbandonPR(cmd *cobra.Command, args []string) error {
+	return setPullRequestStatus(cmd, args, azdo.PullRequestStatusActive, azdo.PullRequestStatusAbandoned, "Abandoned")
+}
+
+func runReactivatePR(cmd *cobra.Command, args []string) error {
+	return setPullRequestStatus(cmd, args, azdo.PullRequestStatusAbandoned, azdo.PullRequestStatusActive, "Reactivated")
+}
+
+// setPullRequestStatus changes the status of the pull request in args from one status to
+// another, and reports it with verb
+func setPullRequestStatus(cmd *cobra.Command, args []string, from, to, verb string) error {
+	ctx := commandContext(cmd)
+
+	env, err := newPREnv(ctx)
+	if err != nil {
+		return err
+	}
+
+	pr, err := env.getPullRequest(ctx, args)
+	if err != nil {
+		return err
+	}
+	if pr.Status != from {
+		return fmt.Errorf("pull request #%d is %s, not %s", pr.PullRequestID, pr.Status, from)
+	}
+
+	proj := env.target.Project
+	update := &azdo.PullRequestUpdate{Status: to}
+	if _, err := env.client.UpdatePullRequest(ctx, proj, pr.Repository.ID, pr.PullRequestID, update); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ %s pull request #%d\n", verb, pr.PullRequestID)
+	fmt.Printf("  Title: %s\n", pr.Title)
+	fmt.Printf("  URL: %s\n", env.client.PullRequestWebURL(proj, pr.Repository.Name, pr.PullRequestID))
+	return nil
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunAbandonAndReactivatePR(t *testing.T) {
+	status := azdo.PullRequestStatusActive
+	var update *azdo.PullRequestUpdate
+	setupPRTest(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/myorg/proj/_apis/git/pullrequests/42":
+			w.Write([]byte(`{"pullRequestId": 42, "status": "` + status + `", "title": "Add login",
+				"repository": {"id": "repo-1", "name": "web-app"}}`))
+		case "/myorg/proj/_apis/git/repositories/repo-1/pullrequests/42":
+			require.Equal(t, http.MethodPatch, r.Method)
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&update))
+			status = update.Status
+			w.Write([]byte(`{"pullRequestId": 42}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	err := runReactivatePR(reactivatePRCmd, []string{"42"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is active, not abandoned")
+	assert.Nil(t, update)
+
+	require.NoError(t, runAbandonPR(abandonPRCmd, []string{"42"}))
+	assert.Equal(t, azdo.PullRequestStatusAbandoned, update.Status)
+
+	err = runAbandonPR(abandonPRCmd, []string{"42"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is abandoned, not active")
+
+	require.NoError(t, runReactivatePR(reactivatePRCmd, []string{"42"}))
+	assert.Equal(t, azdo.PullRequestStatusActive, update.Status)
+}
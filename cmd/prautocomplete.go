@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/spf13/cobra"
+)
+
+var (
+	autoCompletion     completionFlags
+	autoCompleteCancel bool
+)
+
+var autoCompletePRCmd = &cobra.Command{
+	Use:   "auto-complete <id>",
+	Short: "Complete a pull request automatically once its policies pass",
+	Long: `Set auto-complete on a pull request, so Azure DevOps completes it on your behalf as
+soon as all required branch policies have passed. Use --cancel to turn it off again.
+
+Example:
+  dex pr auto-complete 1234 --strategy squash --delete-source-branch
+  dex pr auto-complete 1234 --cancel`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAutoCompletePR,
+}
+
+func init() {
+	prCmd.AddCommand(autoCompletePRCmd)
+
+	autoCompletion.register(autoCompletePRCmd)
+	autoCompletePRCmd.Flags().BoolVar(&autoCompleteCancel, "cancel", false, "Turn off auto-complete")
+
+	autoCompletePRCmd.MarkFlagsMutuallyExclusive("cancel", "strategy")
+	autoCompletePRCmd.MarkFlagsMutuallyExclusive("cancel", "delete-source-branch")
+	autoCompletePRCmd.MarkFlagsMutuallyExclusive("cancel", "complete-work-items")
+}
+
+// setAutoComplete turns on auto-complete for a pull request on behalf of the
+// authenticated user
+func setAutoComplete(ctx context.Context, client *azdo.Client, proj, repoID string, id int, flags *completionFlags) error {
+	options, err := flags.options()
+	if err != nil {
+		return err
+	}
+	userID, err := resolveIdentity(ctx, client, proj, meIdentity)
+	if err != nil {
+		return err
+	}
+	if _, err := client.SetAutoComplete(ctx, proj, repoID, id, userID, options); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Set auto-complete on pull request #%d\n", id)
+	fmt.Printf("  Completes when policies pass: %s\n", flags.describe())
+	return nil
+}
+
+func runAutoCompletePR(cmd *cobra.Command, args []string) error {
+	ctx := commandContext(cmd)
+
+	if _, err := autoCompletion.options(); err != nil {
+		return err
+	}
+
+	env, err := newPREnv(ctx)
+	if err != nil {
+		return err
+	}
+
+	pr, err := env.getPullRequest(ctx, args)
+	if err != nil {
+		return err
+	}
+	if pr.Status != azdo.PullRequestStatusActive {
+		return fmt.Errorf("pull request #%d is %s", pr.PullRequestID, pr.Status)
+	}
+	proj := env.target.Project
+
+	if autoCompleteCancel {
+		if pr.AutoCompleteSetBy == nil {
+			fmt.Printf("Auto-complete is not set on pull request #%d\n", pr.PullRequestID)
+			return nil
+		}
+		if _, err := env.client.CancelAutoComplete(ctx, proj, pr.Repository.ID, pr.PullRequestID); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Cancelled auto-complete on pull request #%d\n", pr.PullRequestID)
+	} else if err := setAutoComplete(ctx, env.client, proj, pr.Repository.ID, pr.PullRequestID, &autoCompletion); err != nil {
+		return err
+	}
+
+	fmt.Printf("  URL: %s\n", env.client.PullRequestWebURL(proj, pr.Repository.Name, pr.PullRequestID))
+	return nil
+}
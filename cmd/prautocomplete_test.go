@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunAutoCompletePR(t *testing.T) {
+	var update *azdo.PullRequestUpdate
+	setupPRTest(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/myorg/proj/_apis/git/pullrequests/42":
+			w.Write([]byte(`{"pullRequestId": 42, "status": "active", "autoCompleteSetBy": {"id": "` + testUserID + `"},
+				"repository": {"id": "repo-1", "name": "web-app"}}`))
+		case "/myorg/proj/_apis/git/repositories/repo-1/pullrequests/42":
+			require.Equal(t, http.MethodPatch, r.Method)
+			update = nil
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&update))
+			w.Write([]byte(`{"pullRequestId": 42}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	oldCompletion, oldCancel := autoCompletion, autoCompleteCancel
+	defer func() { autoCompletion, autoCompleteCancel = oldCompletion, oldCancel }()
+
+	autoCompletion = completionFlags{strategy: "squash", completeWorkItems: true}
+	require.NoError(t, runAutoCompletePR(autoCompletePRCmd, []string{"42"}))
+	require.NotNil(t, update)
+	assert.Equal(t, testUserID, update.AutoCompleteSetBy.ID)
+	assert.Equal(t, &azdo.CompletionOptions{MergeStrategy: azdo.MergeStrategySquash, TransitionWorkItems: true}, update.CompletionOptions)
+
+	autoCompletion, autoCompleteCancel = completionFlags{strategy: "no-fast-forward"}, true
+	require.NoError(t, runAutoCompletePR(autoCompletePRCmd, []string{"42"}))
+	assert.Equal(t, "00000000-0000-0000-0000-000000000000", update.AutoCompleteSetBy.ID)
+	assert.Nil(t, update.CompletionOptions)
+
+	autoCompletion, autoCompleteCancel = completionFlags{strategy: "octopus"}, false
+	assert.Error(t, runAutoCompletePR(autoCompletePRCmd, []string{"42"}))
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/chriskievit/dex-cli/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// checkoutRemote is the remote pull request branches are fetched from
+const checkoutRemote = "origin"
+
+var checkoutBranch string
+
+var checkoutPRCmd = &cobra.Command{
+	Use:   "checkout <id>",
+	Short: "Check out the source branch of a pull request",
+	Long: `Fetch the source branch of a pull request and check it out as a local branch that
+tracks it. An existing local branch is fast-forwarded.
+
+Pull requests from forks are checked out from the pull request's merge ref as
+pr/<id>, without tracking. The merge ref is recreated whenever either branch moves, so
+an existing pr/<id> branch is reset to it. Uncommitted changes must be committed or
+stashed first.
+
+Example:
+  dex pr checkout 1234
+  dex pr checkout 1234 --branch review/login`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCheckoutPR,
+}
+
+func init() {
+	prCmd.AddCommand(checkoutPRCmd)
+
+	checkoutPRCmd.Flags().StringVarP(&checkoutBranch, "branch", "b", "", "Local branch name (defaults to the source branch name)")
+}
+
+func runCheckoutPR(cmd *cobra.Command, args []string) error {
+	ctx := commandContext(cmd)
+
+	env, err := newPREnv(ctx)
+	if err != nil {
+		return err
+	}
+	if !git.IsGitRepository(ctx, env.cwd) {
+		return fmt.Errorf("not a git repository. Please run this command from within a git repository")
+	}
+
+	pr, err := env.getPullRequest(ctx, args)
+	if err != nil {
+		return err
+	}
+	if env.target.Repository != "" && !strings.EqualFold(env.target.Repository, pr.Repository.Name) {
+		return fmt.Errorf("pull request #%d is in repository %s, not in %s", pr.PullRequestID, pr.Repository.Name, env.target.Repository)
+	}
+
+	dirty, err := git.HasUncommittedChanges(ctx, env.cwd)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("your working tree has uncommitted changes. Commit or stash them before checking out pull request #%d", pr.PullRequestID)
+	}
+
+	branch, updated, err := checkoutPullRequest(ctx, env.cwd, pr, checkoutBranch)
+	if err != nil {
+		return err
+	}
+
+	if updated {
+		fmt.Printf("✓ Checked out pull request #%d on existing branch %s\n", pr.PullRequestID, branch)
+	} else {
+		fmt.Printf("✓ Checked out pull request #%d on new branch %s\n", pr.PullRequestID, branch)
+	}
+	fmt.Printf("  Title: %s\n", pr.Title)
+	return nil
+}
+
+// checkoutPullRequest fetches the source of a pull request into dir and checks it out
+// as branch, or the default branch name for the pull request when branch is empty. It
+// returns the branch and whether it existed before and was fast-forwarded, or reset to
+// the merge ref of a pull request from a fork.
+func checkoutPullRequest(ctx context.Context, dir string, pr *azdo.PullRequest, branch string) (string, bool, error) {
+	// The source branch of a fork is not in this repository, but its merge ref is
+	var ref, trackingRef string
+	track, mergeBranch := pr.ForkSource == nil, false
+	if track {
+		ref = pr.SourceRefName
+		trackingRef = checkoutRemote + "/" + azdo.BranchName(pr.SourceRefName)
+		if branch == "" {
+			branch = azdo.BranchName(pr.SourceRefName)
+		}
+	} else {
+		ref = fmt.Sprintf("refs/pull/%d/merge", pr.PullRequestID)
+		trackingRef = fmt.Sprintf("%s/pull/%d/merge", checkoutRemote, pr.PullRequestID)
+		if branch == "" {
+			branch, mergeBranch = fmt.Sprintf("pr/%d", pr.PullRequestID), true
+		}
+	}
+
+	if err := git.Fetch(ctx, dir, checkoutRemote, fmt.Sprintf("+%s:refs/remotes/%s", ref, trackingRef)); err != nil {
+		return "", false, err
+	}
+
+	exists, err := git.BranchExists(ctx, dir, "refs/heads/"+branch)
+	if err != nil {
+		return "", false, err
+	}
+	if !exists {
+		if err := git.CreateTrackingBranch(ctx, dir, branch, trackingRef, track); err != nil {
+			return "", false, err
+		}
+		return branch, false, nil
+	}
+
+	// The merge ref is recreated rather than advanced, so the branch dex made for it follows it
+	if mergeBranch {
+		if err := git.ResetBranch(ctx, dir, branch, trackingRef); err != nil {
+			return "", false, err
+		}
+		return branch, true, nil
+	}
+
+	// Check before switching, so a failure leaves the current branch checked out
+	fastForward, err := git.IsAncestor(ctx, dir, "refs/heads/"+branch, trackingRef)
+	if err != nil {
+		return "", false, err
+	}
+	if !fastForward {
+		return "", false, fmt.Errorf("branch %s has diverged from %s and cannot be fast-forwarded. "+
+			"Use --branch to check out the pull request on another branch", branch, trackingRef)
+	}
+
+	current, err := git.GetCurrentBranch(ctx, dir)
+	if err != nil {
+		return "", false, err
+	}
+	if current != branch {
+		if err := git.CheckoutBranch(ctx, dir, branch); err != nil {
+			return "", false, err
+		}
+	}
+	if err := git.FastForward(ctx, dir, trackingRef); err != nil {
+		return "", false, err
+	}
+	return branch, true, nil
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/chriskievit/dex-cli/internal/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runGit runs git in dir and returns its trimmed output
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	require.NoError(t, err, "git %s", strings.Join(args, " "))
+	return strings.TrimSpace(string(output))
+}
+
+// setupCheckoutTest returns an origin repository with a feature/1/login branch and a
+// clone of it that has only main checked out
+func setupCheckoutTest(t *testing.T) (string, string) {
+	originDir, cleanup := testhelpers.SetupTempGitRepo(t)
+	t.Cleanup(cleanup)
+	testhelpers.CreateBranch(t, originDir, "feature/1/login")
+	testhelpers.CreateCommit(t, originDir, "Add login")
+	testhelpers.CheckoutBranch(t, originDir, "main")
+
+	cloneDir := filepath.Join(t.TempDir(), "clone")
+	runGit(t, originDir, "clone", "--quiet", originDir, cloneDir)
+	runGit(t, cloneDir, "config", "user.name", "Test User")
+	runGit(t, cloneDir, "config", "user.email", "test@example.com")
+	return originDir, cloneDir
+}
+
+func TestCheckoutPullRequest(t *testing.T) {
+	originDir, cloneDir := setupCheckoutTest(t)
+	ctx := context.Background()
+	pr := &azdo.PullRequest{PullRequestID: 42, SourceRefName: "refs/heads/feature/1/login"}
+
+	branch, updated, err := checkoutPullRequest(ctx, cloneDir, pr, "")
+	require.NoError(t, err)
+	assert.Equal(t, "feature/1/login", branch)
+	assert.False(t, updated)
+	assert.Equal(t, "feature/1/login", testhelpers.GetCurrentBranch(t, cloneDir))
+	assert.Equal(t, "origin/feature/1/login", runGit(t, cloneDir, "rev-parse", "--abbrev-ref", "@{upstream}"))
+
+	// New commits on the source branch fast-forward the local branch, also from another branch
+	testhelpers.CheckoutBranch(t, originDir, "feature/1/login")
+	testhelpers.CreateCommit(t, originDir, "Fix review comments")
+	testhelpers.CheckoutBranch(t, cloneDir, "main")
+
+	branch, updated, err = checkoutPullRequest(ctx, cloneDir, pr, "")
+	require.NoError(t, err)
+	assert.True(t, updated)
+	assert.Equal(t, "feature/1/login", testhelpers.GetCurrentBranch(t, cloneDir))
+	assert.Equal(t, runGit(t, originDir, "rev-parse", "feature/1/login"), runGit(t, cloneDir, "rev-parse", "HEAD"))
+
+	// Local commits are never discarded
+	testhelpers.CreateCommit(t, cloneDir, "Local change")
+	testhelpers.CreateCommit(t, originDir, "Remote change")
+	testhelpers.CheckoutBranch(t, cloneDir, "main")
+	_, _, err = checkoutPullRequest(ctx, cloneDir, pr, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has diverged")
+	assert.Equal(t, "main", testhelpers.GetCurrentBranch(t, cloneDir))
+
+	// --branch picks another local branch
+	branch, _, err = checkoutPullRequest(ctx, cloneDir, pr, "review/login")
+	require.NoError(t, err)
+	assert.Equal(t, "review/login", branch)
+}
+
+func TestCheckoutPullRequest_Fork(t *testing.T) {
+	originDir, cloneDir := setupCheckoutTest(t)
+	ctx := context.Background()
+
+	// updateMergeRef merges the source into main as Azure DevOps does for refs/pull/42/merge
+	updateMergeRef := func() string {
+		runGit(t, originDir, "checkout", "--quiet", "--detach", "main")
+		runGit(t, originDir, "merge", "--quiet", "--no-ff", "-m", "Merge pull request 42", "feature/1/login")
+		runGit(t, originDir, "update-ref", "refs/pull/42/merge", "HEAD")
+		testhelpers.CheckoutBranch(t, originDir, "main")
+		return runGit(t, originDir, "rev-parse", "refs/pull/42/merge")
+	}
+	merge := updateMergeRef()
+
+	pr := &azdo.PullRequest{
+		PullRequestID: 42,
+		SourceRefName: "refs/heads/login",
+		ForkSource:    &azdo.ForkRef{Name: "refs/heads/login"},
+	}
+	branch, updated, err := checkoutPullRequest(ctx, cloneDir, pr, "")
+	require.NoError(t, err)
+	assert.Equal(t, "pr/42", branch)
+	assert.False(t, updated)
+	assert.Equal(t, merge, runGit(t, cloneDir, "rev-parse", "HEAD"))
+
+	// The merge ref is recreated when the target moves, so the branch is reset to it
+	testhelpers.CreateCommit(t, originDir, "Move main")
+	merge = updateMergeRef()
+	testhelpers.CheckoutBranch(t, cloneDir, "main")
+
+	branch, updated, err = checkoutPullRequest(ctx, cloneDir, pr, "")
+	require.NoError(t, err)
+	assert.Equal(t, "pr/42", branch)
+	assert.True(t, updated)
+	assert.Equal(t, "pr/42", testhelpers.GetCurrentBranch(t, cloneDir))
+	assert.Equal(t, merge, runGit(t, cloneDir, "rev-parse", "HEAD"))
+}
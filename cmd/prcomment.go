@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/chriskievit/dex-cli/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// threadRootCommentID is the ID of the first comment of a thread; comment IDs are
+// numbered per thread
+const threadRootCommentID = 1
+
+var (
+	commentsActive bool
+
+	commentFile    string
+	commentLine    int
+	commentReply   int
+	commentResolve int
+	commentWontFix int
+)
+
+var commentsPRCmd = &cobra.Command{
+	Use:   "comments [id]",
+	Short: "List the comment threads of a pull request",
+	Long: `List the comment threads of a pull request with their status, the file and line they
+are on, and their replies.
+
+Without an ID, the active pull request for the current branch is used.
+
+Example:
+  dex pr comments 1234
+  dex pr comments --active`,
+	Args: cobra.RangeArgs(0, 1),
+	RunE: runCommentsPR,
+}
+
+var commentPRCmd = &cobra.Command{
+	Use:   "comment <id> [text]",
+	Short: "Comment on a pull request, reply to or resolve a thread",
+	Long: `Start a comment thread on a pull request, optionally on a line of a file, reply to an
+existing thread, or resolve a thread. File paths are relative to the current directory
+or, with a leading "/", to the repository root.
+
+Example:
+  dex pr comment 1234 "Looks good overall"
+  dex pr comment 1234 --file src/login.go --line 42 "Handle the error here"
+  dex pr comment 1234 --reply 12 "Done"
+  dex pr comment 1234 --resolve 12 "Fixed in the latest push"
+  dex pr comment 1234 --wont-fix 13`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runCommentPR,
+}
+
+func init() {
+	prCmd.AddCommand(commentsPRCmd)
+	prCmd.AddCommand(commentPRCmd)
+
+	commentsPRCmd.Flags().BoolVar(&commentsActive, "active", false, "Only list threads that are not resolved")
+
+	commentPRCmd.Flags().StringVar(&commentFile, "file", "", "File to comment on")
+	commentPRCmd.Flags().IntVar(&commentLine, "line", 0, "Line of --file to comment on")
+	commentPRCmd.Flags().IntVar(&commentReply, "reply", 0, "Reply to this thread")
+	commentPRCmd.Flags().IntVar(&commentResolve, "resolve", 0, "Resolve this thread, with an optional reply")
+	commentPRCmd.Flags().IntVar(&commentWontFix, "wont-fix", 0, "Close this thread as won't fix, with an optional reply")
+
+	commentPRCmd.MarkFlagsMutuallyExclusive("reply", "resolve", "wont-fix")
+	for _, name := range []string{"reply", "resolve", "wont-fix"} {
+		commentPRCmd.MarkFlagsMutuallyExclusive("file", name)
+	}
+}
+
+func runCommentsPR(cmd *cobra.Command, args []string) error {
+	ctx := commandContext(cmd)
+
+	env, err := newPREnv(ctx)
+	if err != nil {
+		return err
+	}
+
+	pr, err := env.getPullRequest(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	threads, err := env.client.ListThreads(ctx, env.target.Project, pr.Repository.ID, pr.PullRequestID)
+	if err != nil {
+		return err
+	}
+
+	shown, active := 0, 0
+	for i := range threads {
+		thread := &threads[i]
+		if thread.IsDeleted || !hasUserComments(thread) {
+			continue
+		}
+		if isActiveThread(thread) {
+			active++
+		} else if commentsActive {
+			continue
+		}
+		if shown > 0 {
+			fmt.Println()
+		}
+		printThread(thread)
+		shown++
+	}
+
+	if shown == 0 {
+		fmt.Printf("No comments on pull request #%d\n", pr.PullRequestID)
+		return nil
+	}
+	noun := "threads"
+	if shown == 1 {
+		noun = "thread"
+	}
+	fmt.Printf("\n%d %s, %d active\n", shown, noun, active)
+	return nil
+}
+
+// hasUserComments reports whether a thread has comments written by users, as opposed
+// to the system threads Azure DevOps adds for votes, pushes and the like
+func hasUserComments(thread *azdo.Thread) bool {
+	for _, comment := range thread.Comments {
+		if !comment.IsDeleted && comment.CommentType != azdo.CommentTypeSystem {
+			return true
+		}
+	}
+	return false
+}
+
+// isActiveThread reports whether a thread still needs attention
+func isActiveThread(thread *azdo.Thread) bool {
+	return thread.Status == azdo.ThreadStatusActive || thread.Status == azdo.ThreadStatusPending
+}
+
+// threadStatusDescription describes a thread status as the web interface does
+func threadStatusDescription(status string) string {
+	switch status {
+	case azdo.ThreadStatusFixed:
+		return "resolved"
+	case azdo.ThreadStatusWontFix:
+		return "won't fix"
+	case azdo.ThreadStatusByDesign:
+		return "by design"
+	case "":
+		return "no status"
+	}
+	return status
+}
+
+// printThread prints a thread with its location and comments, indenting replies
+func printThread(thread *azdo.Thread) {
+	header := fmt.Sprintf("Thread %d [%s]", thread.ID, threadStatusDescription(thread.Status))
+	if path := thread.FilePath(); path != "" {
+		header += " " + path
+		if line := thread.Line(); line > 0 {
+			header += fmt.Sprintf(":%d", line)
+		}
+	}
+	fmt.Println(header)
+
+	for _, comment := range thread.Comments {
+		if comment.IsDeleted || comment.CommentType == azdo.CommentTypeSystem {
+			continue
+		}
+		indent := "  "
+		if comment.ParentCommentID != 0 {
+			indent = "    ↳ "
+		}
+		fmt.Printf("%s%s (%s):\n", indent, comment.Author.DisplayName, comment.PublishedDate.Local().Format("2006-01-02 15:04"))
+
+		indent = strings.Repeat(" ", len([]rune(indent))+2)
+		for _, line := range strings.Split(strings.TrimSpace(comment.Content), "\n") {
+			fmt.Printf("%s%s\n", indent, strings.TrimRight(line, "\r"))
+		}
+	}
+}
+
+// threadFilePath converts a --file value to a path from the repository root, as thread
+// contexts use. Relative paths are taken relative to dir; other paths starting with "/"
+// are already taken to be from the repository root.
+func threadFilePath(ctx context.Context, dir, file string) string {
+	if strings.HasPrefix(filepath.ToSlash(file), "/") && !filepath.IsAbs(file) {
+		return filepath.ToSlash(file)
+	}
+
+	if root, err := git.GetRepositoryRoot(ctx, dir); err == nil {
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(evalSymlinks(dir), path)
+		}
+		if rel, err := filepath.Rel(evalSymlinks(root), evalSymlinks(path)); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "/" + filepath.ToSlash(rel)
+		}
+	}
+
+	if filepath.IsAbs(file) {
+		return filepath.ToSlash(file)
+	}
+	return "/" + filepath.ToSlash(filepath.Clean(file))
+}
+
+// evalSymlinks resolves the symbolic links in path, or returns it unchanged when that
+// fails, e.g. because it does not exist
+func evalSymlinks(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+func runCommentPR(cmd *cobra.Command, args []string) error {
+	ctx := commandContext(cmd)
+
+	text := ""
+	if len(args) > 1 {
+		text = strings.TrimSpace(args[1])
+	}
+	threadID, status := commentReply, ""
+	switch {
+	case commentResolve != 0:
+		threadID, status = commentResolve, azdo.ThreadStatusFixed
+	case commentWontFix != 0:
+		threadID, status = commentWontFix, azdo.ThreadStatusWontFix
+	}
+	if threadID < 0 {
+		return fmt.Errorf("invalid thread ID: %d", threadID)
+	}
+	if text == "" && status == "" {
+		return fmt.Errorf("comment text is required")
+	}
+	if commentLine != 0 && commentFile == "" {
+		return fmt.Errorf("--line requires --file")
+	}
+	if commentLine < 0 {
+		return fmt.Errorf("invalid line: %d", commentLine)
+	}
+
+	env, err := newPREnv(ctx)
+	if err != nil {
+		return err
+	}
+
+	pr, err := env.getPullRequest(ctx, args[:1])
+	if err != nil {
+		return err
+	}
+	proj, repoID := env.target.Project, pr.Repository.ID
+
+	if threadID == 0 {
+		request := azdo.NewCommentThread(text)
+		location := ""
+		if commentFile != "" {
+			request.ThreadContext = &azdo.ThreadContext{FilePath: threadFilePath(ctx, env.cwd, commentFile)}
+			location = " on " + request.ThreadContext.FilePath
+			if commentLine > 0 {
+				request.ThreadContext.RightFileStart = &azdo.FilePosition{Line: commentLine, Offset: 1}
+				request.ThreadContext.RightFileEnd = &azdo.FilePosition{Line: commentLine, Offset: 1}
+				location += fmt.Sprintf(":%d", commentLine)
+			}
+		}
+
+		thread, err := env.client.CreateThread(ctx, proj, repoID, pr.PullRequestID, request)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ Added comment%s (thread %d)\n", location, thread.ID)
+	} else {
+		if text != "" {
+			reply := &azdo.CreateCommentRequest{ParentCommentID: threadRootCommentID, Content: text, CommentType: azdo.CommentTypeText}
+			if _, err := env.client.AddComment(ctx, proj, repoID, pr.PullRequestID, threadID, reply); err != nil {
+				if azdo.IsNotFound(err) {
+					return fmt.Errorf("thread %d not found on pull request #%d: %w", threadID, pr.PullRequestID, err)
+				}
+				return err
+			}
+			fmt.Printf("✓ Replied to thread %d\n", threadID)
+		}
+		if status != "" {
+			if _, err := env.client.SetThreadStatus(ctx, proj, repoID, pr.PullRequestID, threadID, status); err != nil {
+				if azdo.IsNotFound(err) {
+					return fmt.Errorf("thread %d not found on pull request #%d: %w", threadID, pr.PullRequestID, err)
+				}
+				return err
+			}
+			fmt.Printf("✓ Marked thread %d as %s\n", threadID, threadStatusDescription(status))
+		}
+	}
+
+	fmt.Printf("  URL: %s\n", env.client.PullRequestWebURL(proj, pr.Repository.Name, pr.PullRequestID))
+	return nil
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/chriskievit/dex-cli/internal/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// prCommentHandler serves pull request 42 with its threads and records the comment
+// requests it receives by method and path
+func prCommentHandler(t *testing.T, requests map[string]map[string]interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/myorg/proj/_apis/git/pullrequests/42" {
+			w.Write([]byte(`{"pullRequestId": 42, "status": "active", "repository": {"id": "repo-1", "name": "web-app"}}`))
+			return
+		}
+		if r.Method == http.MethodGet && r.URL.Path == "/myorg/proj/_apis/git/repositories/repo-1/pullrequests/42/threads" {
+			w.Write([]byte(`{"value": [
+				{"id": 7, "status": "active", "threadContext": {"filePath": "/src/login.go", "rightFileStart": {"line": 42, "offset": 1}},
+					"comments": [
+						{"id": 1, "content": "Handle the error", "commentType": "text", "author": {"displayName": "John Smith"}},
+						{"id": 2, "parentCommentId": 1, "content": "Will do", "commentType": "text", "author": {"displayName": "Jane Doe"}}]},
+				{"id": 8, "status": "fixed", "comments": [{"id": 1, "content": "Typo in the title", "commentType": "text"}]},
+				{"id": 9, "comments": [{"id": 1, "content": "John Smith voted 10", "commentType": "system"}]}]}`))
+			return
+		}
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests[r.Method+" "+r.URL.Path] = body
+		w.Write([]byte(`{"id": 15}`))
+	}
+}
+
+func TestRunCommentsPR(t *testing.T) {
+	setupPRTest(t, prCommentHandler(t, map[string]map[string]interface{}{}))
+
+	oldActive := commentsActive
+	defer func() { commentsActive = oldActive }()
+
+	for _, active := range []bool{false, true} {
+		commentsActive = active
+		require.NoError(t, runCommentsPR(commentsPRCmd, []string{"42"}))
+	}
+}
+
+func TestHasUserComments(t *testing.T) {
+	assert.True(t, hasUserComments(&azdo.Thread{Comments: []azdo.Comment{{CommentType: azdo.CommentTypeText}}}))
+	assert.False(t, hasUserComments(&azdo.Thread{Comments: []azdo.Comment{{CommentType: azdo.CommentTypeSystem}}}))
+	assert.False(t, hasUserComments(&azdo.Thread{Comments: []azdo.Comment{{CommentType: azdo.CommentTypeText, IsDeleted: true}}}))
+}
+
+func TestRunCommentPR(t *testing.T) {
+	const threadsPath = "/myorg/proj/_apis/git/repositories/repo-1/pullrequests/42/threads"
+
+	tests := []struct {
+		name    string
+		args    []string
+		file    string
+		line    int
+		reply   int
+		resolve int
+		wontFix int
+		want    map[string]map[string]interface{}
+		wantErr string
+	}{
+		{
+			name: "new thread on a line",
+			args: []string{"42", "Handle the error here"},
+			file: "/src/login.go",
+			line: 42,
+			want: map[string]map[string]interface{}{
+				"POST " + threadsPath: {
+					"status": "active",
+					"threadContext": map[string]interface{}{
+						"filePath":       "/src/login.go",
+						"rightFileStart": map[string]interface{}{"line": float64(42), "offset": float64(1)},
+						"rightFileEnd":   map[string]interface{}{"line": float64(42), "offset": float64(1)},
+					},
+					"comments": []interface{}{map[string]interface{}{"content": "Handle the error here", "commentType": "text"}},
+				},
+			},
+		},
+		{
+			name:  "reply",
+			args:  []string{"42", "Done"},
+			reply: 7,
+			want: map[string]map[string]interface{}{
+				"POST " + threadsPath + "/7/comments": {"parentCommentId": float64(1), "content": "Done", "commentType": "text"},
+			},
+		},
+		{
+			name:    "resolve with reply",
+			args:    []string{"42", "Fixed"},
+			resolve: 7,
+			want: map[string]map[string]interface{}{
+				"POST " + threadsPath + "/7/comments": {"parentCommentId": float64(1), "content": "Fixed", "commentType": "text"},
+				"PATCH " + threadsPath + "/7":         {"status": "fixed"},
+			},
+		},
+		{
+			name:    "won't fix",
+			args:    []string{"42"},
+			wontFix: 8,
+			want: map[string]map[string]interface{}{
+				"PATCH " + threadsPath + "/8": {"status": "wontFix"},
+			},
+		},
+		{name: "text required", args: []string{"42"}, wantErr: "comment text is required"},
+		{name: "line requires file", args: []string{"42", "text"}, line: 3, wantErr: "--line requires --file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := map[string]map[string]interface{}{}
+			setupPRTest(t, prCommentHandler(t, requests))
+
+			oldFile, oldLine, oldReply, oldResolve, oldWontFix := commentFile, commentLine, commentReply, commentResolve, commentWontFix
+			defer func() {
+				commentFile, commentLine, commentReply, commentResolve, commentWontFix = oldFile, oldLine, oldReply, oldResolve, oldWontFix
+			}()
+			commentFile, commentLine, commentReply, commentResolve, commentWontFix = tt.file, tt.line, tt.reply, tt.resolve, tt.wontFix
+
+			err := runCommentPR(commentPRCmd, tt.args)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, requests)
+		})
+	}
+}
+
+func TestThreadFilePath(t *testing.T) {
+	repoDir, cleanup := testhelpers.SetupTempGitRepo(t)
+	defer cleanup()
+	subDir := filepath.Join(repoDir, "src")
+	require.NoError(t, os.MkdirAll(subDir, 0755))
+	ctx := context.Background()
+
+	assert.Equal(t, "/src/login.go", threadFilePath(ctx, repoDir, "src/login.go"))
+	assert.Equal(t, "/src/login.go", threadFilePath(ctx, subDir, "login.go"))
+	assert.Equal(t, "/README.md", threadFilePath(ctx, subDir, "../README.md"))
+	assert.Equal(t, "/src/login.go", threadFilePath(ctx, subDir, filepath.Join(subDir, "login.go")))
+	assert.Equal(t, "/src/login.go", threadFilePath(ctx, subDir, "/src/login.go"))
+	assert.Equal(t, "/src/login.go", threadFilePath(ctx, t.TempDir(), "src/login.go"))
+}
@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/spf13/cobra"
+)
+
+var (
+	editTitle           string
+	editDescription     string
+	editDescriptionFile string
+	editInEditor        bool
+	editTarget          string
+	editPublish         bool
+	editDraft           bool
+	editAddWorkItems    []int
+	editRemoveWorkItems []int
+)
+
+var editPRCmd = &cobra.Command{
+	Use:   "edit <id>",
+	Short: "Edit the title, description, target, draft state or work items of a pull request",
+	Long: `Edit an active pull request. Only the given fields are changed.
+
+The description is set with --description, read from a file with --description-file
+("-" reads standard input), or edited in your git editor with --editor.
+
+Example:
+  dex pr edit 1234 --title "Add login with SSO"
+  dex pr edit 1234 --editor
+  dex pr edit 1234 --target release/2.0
+  dex pr edit 1234 --publish --add-workitem 567 --remove-workitem 123`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEditPR,
+}
+
+func init() {
+	prCmd.AddCommand(editPRCmd)
+
+	editPRCmd.Flags().StringVar(&editTitle, "title", "", "New title")
+	editPRCmd.Flags().StringVar(&editDescription, "description", "", "New description")
+	editPRCmd.Flags().StringVar(&editDescriptionFile, "description-file", "", `Read the new description from a file ("-" for standard input)`)
+	editPRCmd.Flags().BoolVar(&editInEditor, "editor", false, "Edit the description in your git editor")
+	editPRCmd.Flags().StringVarP(&editTarget, "target", "t", "", "Retarget the pull request to this branch")
+	editPRCmd.Flags().BoolVar(&editPublish, "publish", false, "Publish a draft pull request for review")
+	editPRCmd.Flags().BoolVar(&editDraft, "draft", false, "Turn the pull request back into a draft")
+	editPRCmd.Flags().IntSliceVar(&editAddWorkItems, "add-workitem", nil, "Work item ID to link (repeatable)")
+	editPRCmd.Flags().IntSliceVar(&editRemoveWorkItems, "remove-workitem", nil, "Work item ID to unlink (repeatable)")
+
+	editPRCmd.MarkFlagsMutuallyExclusive("description", "description-file", "editor")
+	editPRCmd.MarkFlagsMutuallyExclusive("publish", "draft")
+	editPRCmd.MarkFlagsOneRequired("title", "description", "description-file", "editor", "target", "publish", "draft", "add-workitem", "remove-workitem")
+}
+
+func runEditPR(cmd *cobra.Command, args []string) error {
+	ctx := commandContext(cmd)
+
+	if cmd.Flags().Changed("title") && strings.TrimSpace(editTitle) == "" {
+		return fmt.Errorf("title cannot be empty")
+	}
+	for _, id := range append(append([]int{}, editAddWorkItems...), editRemoveWorkItems...) {
+		if id <= 0 {
+			return fmt.Errorf("invalid work item ID: %d", id)
+		}
+	}
+	for _, id := range editAddWorkItems {
+		if slices.Contains(editRemoveWorkItems, id) {
+			return fmt.Errorf("work item #%d cannot be both linked and unlinked", id)
+		}
+	}
+
+	// Read the description before anything is changed, so a missing file changes nothing
+	var description *string
+	switch {
+	case cmd.Flags().Changed("description"):
+		description = &editDescription
+	case editDescriptionFile != "":
+		var content []byte
+		var err error
+		if editDescriptionFile == "-" {
+			content, err = io.ReadAll(cmd.InOrStdin())
+		} else {
+			content, err = os.ReadFile(editDescriptionFile)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read description: %w", err)
+		}
+		text := strings.TrimRight(string(content), " \t\r\n")
+		description = &text
+	}
+
+	env, err := newPREnv(ctx)
+	if err != nil {
+		return err
+	}
+
+	pr, err := env.getPullRequest(ctx, args)
+	if err != nil {
+		return err
+	}
+	if pr.Status != azdo.PullRequestStatusActive {
+		return fmt.Errorf("pull request #%d is %s and cannot be edited", pr.PullRequestID, pr.Status)
+	}
+	proj, repoID := env.target.Project, pr.Repository.ID
+
+	var changes []string
+	update := &azdo.PullRequestUpdate{}
+	if title := strings.TrimSpace(editTitle); title != "" && title != pr.Title {
+		update.Title = title
+		changes = append(changes, fmt.Sprintf("Title: %s", update.Title))
+	}
+
+	if editInEditor {
+		text, err := editText(ctx, env.cwd, pr.Description)
+		if err != nil {
+			return err
+		}
+		description = &text
+	}
+	if description != nil && *description != pr.Description {
+		update.Description = description
+		changes = append(changes, "Description: updated")
+	}
+
+	if editTarget != "" {
+		target := azdo.FormatRefName(editTarget)
+		if target == pr.SourceRefName {
+			return fmt.Errorf("target branch cannot be the same as source branch: %s", azdo.BranchName(target))
+		}
+		if target != pr.TargetRefName {
+			update.TargetRefName = target
+			changes = append(changes, fmt.Sprintf("Target: %s → %s", azdo.BranchName(pr.TargetRefName), azdo.BranchName(target)))
+		}
+	}
+
+	if (editPublish && pr.IsDraft) || (editDraft && !pr.IsDraft) {
+		isDraft := editDraft
+		update.IsDraft = &isDraft
+		if isDraft {
+			changes = append(changes, "Marked as draft")
+		} else {
+			changes = append(changes, "Published for review")
+		}
+	}
+
+	// Check the work items before updating, so an invalid one changes nothing
+	var addWorkItems, removeWorkItems []int
+	if len(editAddWorkItems) > 0 || len(editRemoveWorkItems) > 0 {
+		linked, err := linkedWorkItems(ctx, env.client, proj, pr)
+		if err != nil {
+			return err
+		}
+		for _, id := range editRemoveWorkItems {
+			if !linked[id] {
+				return fmt.Errorf("work item #%d is not linked to pull request #%d", id, pr.PullRequestID)
+			}
+			if !slices.Contains(removeWorkItems, id) {
+				removeWorkItems = append(removeWorkItems, id)
+			}
+		}
+		for _, id := range editAddWorkItems {
+			if !linked[id] && !slices.Contains(addWorkItems, id) {
+				addWorkItems = append(addWorkItems, id)
+			}
+		}
+	}
+
+	if len(changes) > 0 {
+		if _, err := env.client.UpdatePullRequest(ctx, proj, repoID, pr.PullRequestID, update); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range addWorkItems {
+		if err := linkWorkItem(ctx, env.client, pr, id); err != nil {
+			return err
+		}
+		changes = append(changes, fmt.Sprintf("Linked work item #%d", id))
+	}
+	artifactURL := azdo.PullRequestArtifactURL(pr.Repository.Project.ID, repoID, pr.PullRequestID)
+	for _, id := range removeWorkItems {
+		if err := env.client.RemoveWorkItemRelation(ctx, id, artifactURL); err != nil {
+			return fmt.Errorf("failed to unlink work item #%d: %w", id, err)
+		}
+		changes = append(changes, fmt.Sprintf("Unlinked work item #%d", id))
+	}
+
+	if len(changes) == 0 {
+		fmt.Printf("Pull request #%d is already up to date\n", pr.PullRequestID)
+	} else {
+		fmt.Printf("✓ Updated pull request #%d\n", pr.PullRequestID)
+		for _, change := range changes {
+			fmt.Printf("  %s\n", change)
+		}
+	}
+	fmt.Printf("  URL: %s\n", env.client.PullRequestWebURL(proj, pr.Repository.Name, pr.PullRequestID))
+	return nil
+}
+
+// linkedWorkItems returns the IDs of the work items linked to a pull request
+func linkedWorkItems(ctx context.Context, client *azdo.Client, project string, pr *azdo.PullRequest) (map[int]bool, error) {
+	refs, err := client.GetPullRequestWorkItems(ctx, project, pr.Repository.ID, pr.PullRequestID)
+	if err != nil {
+		return nil, err
+	}
+	linked := map[int]bool{}
+	for _, ref := range refs {
+		if id, err := strconv.Atoi(ref.ID); err == nil {
+			linked[id] = true
+		}
+	}
+	return linked, nil
+}
+
+// linkWorkItem links a work item to an existing pull request, as the web interface does
+func linkWorkItem(ctx context.Context, client *azdo.Client, pr *azdo.PullRequest, id int) error {
+	relation := azdo.WorkItemRelation{
+		Rel:        azdo.RelationArtifactLink,
+		URL:        azdo.PullRequestArtifactURL(pr.Repository.Project.ID, pr.Repository.ID, pr.PullRequestID),
+		Attributes: map[string]interface{}{"name": "Pull Request"},
+	}
+	if err := client.AddWorkItemRelation(ctx, id, relation); err != nil {
+		return fmt.Errorf("failed to link work item #%d: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/chriskievit/dex-cli/internal/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// editTestPR is a draft pull request linked to work item 123
+const editTestPR = `{"pullRequestId": 42, "status": "active", "isDraft": true, "title": "Add login",
+	"description": "Old description",
+	"sourceRefName": "refs/heads/feature/1/login", "targetRefName": "refs/heads/main",
+	"repository": {"id": "repo-1", "name": "web-app", "project": {"id": "p-1"}}}`
+
+// resetEditFlags restores the pr edit flags when the test ends
+func resetEditFlags(t *testing.T) {
+	oldTitle, oldDescFile, oldEditor, oldTarget := editTitle, editDescriptionFile, editInEditor, editTarget
+	oldPublish, oldDraft, oldAdd, oldRemove := editPublish, editDraft, editAddWorkItems, editRemoveWorkItems
+	t.Cleanup(func() {
+		editTitle, editDescriptionFile, editInEditor, editTarget = oldTitle, oldDescFile, oldEditor, oldTarget
+		editPublish, editDraft, editAddWorkItems, editRemoveWorkItems = oldPublish, oldDraft, oldAdd, oldRemove
+	})
+}
+
+func TestRunEditPR(t *testing.T) {
+	var update map[string]interface{}
+	patches := map[string][]map[string]interface{}{}
+	setupPRTest(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/myorg/proj/_apis/git/pullrequests/42":
+			w.Write([]byte(editTestPR))
+		case "/myorg/proj/_apis/git/repositories/repo-1/pullrequests/42/workitems":
+			w.Write([]byte(`{"value": [{"id": "123"}]}`))
+		case "/myorg/proj/_apis/git/repositories/repo-1/pullrequests/42":
+			require.Equal(t, http.MethodPatch, r.Method)
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&update))
+			w.Write([]byte(`{"pullRequestId": 42}`))
+		case "/myorg/_apis/wit/workitems/123":
+			if r.Method == http.MethodGet {
+				w.Write([]byte(`{"id": 123, "rev": 3, "relations": [{"rel": "ArtifactLink",
+					"url": "` + azdo.PullRequestArtifactURL("p-1", "repo-1", 42) + `"}]}`))
+				return
+			}
+			fallthrough
+		case "/myorg/_apis/wit/workitems/567":
+			var patch []map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&patch))
+			patches[r.URL.Path] = patch
+			w.Write([]byte(`{"id": 1}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	resetEditFlags(t)
+	descFile := filepath.Join(t.TempDir(), "description.md")
+	require.NoError(t, os.WriteFile(descFile, []byte("New description\n\n"), 0644))
+	editTitle, editDescriptionFile, editTarget, editPublish = " Add SSO login ", descFile, "release", true
+	editAddWorkItems, editRemoveWorkItems = []int{567, 123}, []int{123}
+
+	// A work item cannot be linked and unlinked at once
+	err := runEditPR(editPRCmd, []string{"42"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "both linked and unlinked")
+
+	editAddWorkItems = []int{567}
+	require.NoError(t, runEditPR(editPRCmd, []string{"42"}))
+	assert.Equal(t, map[string]interface{}{
+		"title":         "Add SSO login",
+		"description":   "New description",
+		"targetRefName": "refs/heads/release",
+		"isDraft":       false,
+	}, update)
+
+	require.Len(t, patches["/myorg/_apis/wit/workitems/567"], 1)
+	link := patches["/myorg/_apis/wit/workitems/567"][0]
+	assert.Equal(t, "add", link["op"])
+	assert.Equal(t, azdo.PullRequestArtifactURL("p-1", "repo-1", 42), link["value"].(map[string]interface{})["url"])
+
+	require.Len(t, patches["/myorg/_apis/wit/workitems/123"], 2)
+	assert.Equal(t, "/relations/0", patches["/myorg/_apis/wit/workitems/123"][1]["path"])
+}
+
+func TestRunEditPR_NothingChanged(t *testing.T) {
+	updated := false
+	setupPRTest(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/myorg/proj/_apis/git/pullrequests/42":
+			w.Write([]byte(editTestPR))
+		case "/myorg/proj/_apis/git/repositories/repo-1/pullrequests/42/workitems":
+			w.Write([]byte(`{"value": [{"id": "123"}]}`))
+		default:
+			updated = true
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	resetEditFlags(t)
+	editTitle, editDescriptionFile, editTarget, editPublish, editDraft = "Add login", "", "main", false, true
+	editAddWorkItems, editRemoveWorkItems = []int{123}, nil
+	require.NoError(t, runEditPR(editPRCmd, []string{"42"}))
+	assert.False(t, updated)
+
+	// Unlinking a work item that is not linked changes nothing either
+	editAddWorkItems, editRemoveWorkItems = nil, []int{999}
+	err := runEditPR(editPRCmd, []string{"42"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "work item #999 is not linked")
+	assert.False(t, updated)
+
+	editRemoveWorkItems, editTarget = nil, "feature/1/login"
+	err = runEditPR(editPRCmd, []string{"42"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "same as source branch")
+}
+
+func TestEditText(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell command as editor")
+	}
+	repoDir, cleanup := testhelpers.SetupTempGitRepo(t)
+	defer cleanup()
+	// Keep the temporary file and sed's backup of it out of the system temp directory
+	t.Setenv("TMPDIR", t.TempDir())
+
+	t.Setenv("GIT_EDITOR", `sed -i.bak -e "s/Old/New/"`)
+	text, err := editText(context.Background(), repoDir, "Old description\n")
+	require.NoError(t, err)
+	assert.Equal(t, "New description", text)
+
+	t.Setenv("GIT_EDITOR", "false")
+	_, err = editText(context.Background(), repoDir, "Old description")
+	assert.Error(t, err)
+}
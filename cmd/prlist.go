@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/chriskievit/dex-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listMine     bool
+	listReviewer string
+	listStatus   string
+	listSource   string
+	listTarget   string
+	listRepo     string
+	listLimit    int
+)
+
+var listPRCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List pull requests",
+	Long: `List pull requests in the project, newest first. By default all active pull
+requests in every repository of the project are listed.
+
+Example:
+  dex pr list --mine                    # my open pull requests
+  dex pr list --reviewer                # pull requests waiting on my review
+  dex pr list --status completed --target main --repo web-app`,
+	Args: cobra.NoArgs,
+	RunE: runListPRs,
+}
+
+func init() {
+	prCmd.AddCommand(listPRCmd)
+
+	listPRCmd.Flags().BoolVar(&listMine, "mine", false, "Only pull requests created by you")
+	listPRCmd.Flags().StringVar(&listReviewer, "reviewer", "", "Only pull requests with this reviewer: \"me\" (the default without a value), an email, name or identity ID")
+	listPRCmd.Flags().Lookup("reviewer").NoOptDefVal = meIdentity
+	listPRCmd.Flags().StringVar(&listStatus, "status", azdo.PullRequestStatusActive, "Status: active, completed, abandoned or all")
+	listPRCmd.Flags().StringVarP(&listSource, "source", "s", "", "Only pull requests from this source branch")
+	listPRCmd.Flags().StringVarP(&listTarget, "target", "t", "", "Only pull requests into this target branch")
+	listPRCmd.Flags().StringVar(&listRepo, "repo", "", "Only pull requests in this repository")
+	listPRCmd.Flags().IntVarP(&listLimit, "limit", "L", 50, "Maximum number of pull requests to list (0 for all)")
+}
+
+// prEnv is the configuration and API client shared by the pull request commands
+type prEnv struct {
+	cwd    string
+	cfg    *config.Resolved
+	target target
+	client *azdo.Client
+}
+
+// newPREnv resolves the organization and project of a pull request command and creates
+// an authenticated client. The repository is resolved but not required.
+func newPREnv(ctx context.Context) (*prEnv, error) {
+	cwd, _ := os.Getwd()
+	cfg, err := loadConfig(ctx, cwd)
+	if err != nil {
+		return nil, err
+	}
+
+	t := resolveTarget(ctx, cfg.Config, cwd)
+	if t.Organization == "" {
+		return nil, fmt.Errorf("organization not configured. Use --org flag or run 'dex-cli auth login'")
+	}
+	if t.Project == "" {
+		return nil, fmt.Errorf("project not configured. Use --project flag, set in config, or run from a clone of an Azure DevOps repository")
+	}
+
+	client, err := authenticatedClient(cfg.Config, t.Server, t.Organization)
+	if err != nil {
+		return nil, err
+	}
+
+	return &prEnv{cwd: cwd, cfg: cfg, target: t, client: client}, nil
+}
+
+// validatePRStatus checks a --status value
+func validatePRStatus(status string) error {
+	switch status {
+	case azdo.PullRequestStatusActive, azdo.PullRequestStatusCompleted,
+		azdo.PullRequestStatusAbandoned, azdo.PullRequestStatusAll:
+		return nil
+	}
+	return fmt.Errorf("invalid status %q: use active, completed, abandoned or all", status)
+}
+
+func runListPRs(cmd *cobra.Command, args []string) error {
+	ctx := commandContext(cmd)
+
+	if err := validatePRStatus(listStatus); err != nil {
+		return err
+	}
+	if listLimit < 0 {
+		return fmt.Errorf("--limit cannot be negative")
+	}
+
+	env, err := newPREnv(ctx)
+	if err != nil {
+		return err
+	}
+	proj := env.target.Project
+
+	criteria := azdo.PullRequestSearchCriteria{Status: listStatus}
+	if listSource != "" {
+		criteria.SourceRefName = azdo.FormatRefName(listSource)
+	}
+	if listTarget != "" {
+		criteria.TargetRefName = azdo.FormatRefName(listTarget)
+	}
+	if listMine {
+		if criteria.CreatorID, err = resolveIdentity(ctx, env.client, proj, meIdentity); err != nil {
+			return err
+		}
+	}
+	if listReviewer != "" {
+		if criteria.ReviewerID, err = resolveIdentity(ctx, env.client, proj, listReviewer); err != nil {
+			return err
+		}
+	}
+	if listRepo != "" {
+		repository, err := env.client.GetRepository(ctx, proj, listRepo)
+		if err != nil {
+			if azdo.IsNotFound(err) {
+				return fmt.Errorf("repository %q not found in project %q: %w", listRepo, proj, err)
+			}
+			return err
+		}
+		criteria.RepositoryID = repository.ID
+	}
+
+	pullRequests, err := env.client.ListPullRequests(ctx, proj, criteria, listLimit)
+	if err != nil {
+		return err
+	}
+
+	if len(pullRequests) == 0 {
+		fmt.Println("No pull requests found")
+		return nil
+	}
+
+	printPullRequests(pullRequests, listStatus == azdo.PullRequestStatusAll)
+	return nil
+}
+
+// printPullRequests prints pull requests as a table, marking those that are not active
+// when showStatus is set
+func printPullRequests(pullRequests []azdo.PullRequest, showStatus bool) {
+	fmt.Printf("%-7s %-20s %-20s %-50s %s\n", "ID", "REPOSITORY", "AUTHOR", "TITLE", "BRANCHES")
+	for _, pr := range pullRequests {
+		title := pr.Title
+		if pr.IsDraft {
+			title = "[draft] " + title
+		}
+		if showStatus && pr.Status != azdo.PullRequestStatusActive {
+			title = "[" + pr.Status + "] " + title
+		}
+
+		fmt.Printf("%-7d %-20s %-20s %-50s %s → %s\n", pr.PullRequestID,
+			truncate(pr.Repository.Name, 20), truncate(pr.CreatedBy.DisplayName, 20), truncate(title, 50),
+			azdo.BranchName(pr.SourceRefName), azdo.BranchName(pr.TargetRefName))
+	}
+}
+
+// truncate shortens s to at most n characters, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
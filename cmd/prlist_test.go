@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chriskievit/dex-cli/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testUserID is the identity connectionData reports in pull request tests
+const testUserID = "8c8c7d32-6b1b-47f4-b2e9-30b477b5ab3d"
+
+// setupPRTest points the pull request commands at a fake Azure DevOps server for
+// organization myorg and project proj. Requests other than connectionData go to handler.
+func setupPRTest(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+
+	setupProfileTest(t)
+	t.Setenv(auth.TokenEnvVar, "test-token")
+	t.Setenv(auth.AzureDevOpsTokenEnvVar, "")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/myorg/_apis/connectionData" {
+			w.Write([]byte(`{"authenticatedUser": {"id": "` + testUserID + `", "providerDisplayName": "Jane Doe"}}`))
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	organization, project, serverURL = "myorg", "proj", server.URL
+}
+
+func TestRunListPRs(t *testing.T) {
+	var query map[string]string
+	setupPRTest(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/myorg/proj/_apis/git/repositories/web-app":
+			w.Write([]byte(`{"id": "repo-1", "name": "web-app"}`))
+		case "/myorg/proj/_apis/git/pullrequests":
+			query = map[string]string{}
+			for key, values := range r.URL.Query() {
+				query[key] = values[0]
+			}
+			w.Write([]byte(`{"value": [{"pullRequestId": 7, "title": "Add login", "isDraft": true,
+				"sourceRefName": "refs/heads/feature/1/login", "targetRefName": "refs/heads/main",
+				"createdBy": {"displayName": "Jane Doe"}, "repository": {"name": "web-app"}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	oldMine, oldReviewer, oldStatus, oldRepo, oldTarget := listMine, listReviewer, listStatus, listRepo, listTarget
+	defer func() {
+		listMine, listReviewer, listStatus, listRepo, listTarget = oldMine, oldReviewer, oldStatus, oldRepo, oldTarget
+	}()
+
+	listMine, listReviewer, listStatus, listRepo, listTarget = true, "me", "active", "web-app", "main"
+	require.NoError(t, runListPRs(listPRCmd, []string{}))
+	assert.Equal(t, testUserID, query["searchCriteria.creatorId"])
+	assert.Equal(t, testUserID, query["searchCriteria.reviewerId"])
+	assert.Equal(t, "repo-1", query["searchCriteria.repositoryId"])
+	assert.Equal(t, "refs/heads/main", query["searchCriteria.targetRefName"])
+	assert.Equal(t, "active", query["searchCriteria.status"])
+
+	listStatus = "merged"
+	assert.Error(t, runListPRs(listPRCmd, []string{}))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", truncate("short", 10))
+	assert.Equal(t, "exactly10!", truncate("exactly10!", 10))
+	assert.Equal(t, "a longer …", truncate("a longer title", 10))
+	assert.Equal(t, "ünïcø…", truncate("ünïcødé", 6))
+}
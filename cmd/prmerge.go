@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/spf13/cobra"
+)
+
+// mergeStrategies maps the --strategy values to Azure DevOps merge strategies
+var mergeStrategies = map[string]string{
+	"no-fast-forward": azdo.MergeStrategyNoFastForward,
+	"squash":          azdo.MergeStrategySquash,
+	"rebase":          azdo.MergeStrategyRebase,
+	"rebase-merge":    azdo.MergeStrategyRebaseMerge,
+}
+
+// completionFlags are the flags that choose how a pull request is completed, shared by
+// pr merge, pr auto-complete and pr create --auto-complete
+type completionFlags struct {
+	strategy          string
+	deleteBranch      bool
+	completeWorkItems bool
+}
+
+// register adds the completion flags to cmd
+func (f *completionFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.strategy, "strategy", "no-fast-forward", "Merge strategy: no-fast-forward, squash, rebase or rebase-merge")
+	cmd.Flags().BoolVar(&f.deleteBranch, "delete-source-branch", false, "Delete the source branch after merging")
+	cmd.Flags().BoolVar(&f.completeWorkItems, "complete-work-items", false, "Complete the linked work items after merging")
+}
+
+// options returns the completion options selected by the flags
+func (f *completionFlags) options() (*azdo.CompletionOptions, error) {
+	strategy, err := parseMergeStrategy(f.strategy)
+	if err != nil {
+		return nil, err
+	}
+	return &azdo.CompletionOptions{
+		MergeStrategy:       strategy,
+		DeleteSourceBranch:  f.deleteBranch,
+		TransitionWorkItems: f.completeWorkItems,
+	}, nil
+}
+
+// describe summarizes the completion options for output
+func (f *completionFlags) describe() string {
+	parts := []string{strings.ToLower(f.strategy)}
+	if f.deleteBranch {
+		parts = append(parts, "delete source branch")
+	}
+	if f.completeWorkItems {
+		parts = append(parts, "complete work items")
+	}
+	return strings.Join(parts, ", ")
+}
+
+var (
+	mergeCompletion   completionFlags
+	mergeMessage      string
+	mergeBypassPolicy bool
+	mergeBypassReason string
+)
+
+var mergePRCmd = &cobra.Command{
+	Use:   "merge <id>",
+	Short: "Complete (merge) a pull request",
+	Long: `Complete a pull request, merging its source branch into the target branch.
+
+The pull request is only completed when all required branch policies have passed. Use
+--bypass-policy with a --reason to override them, if you are allowed to.
+
+Example:
+  dex pr merge 1234
+  dex pr merge 1234 --strategy squash --delete-source-branch --complete-work-items
+  dex pr merge 1234 --bypass-policy --reason "Hotfix for the outage"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMergePR,
+}
+
+func init() {
+	prCmd.AddCommand(mergePRCmd)
+
+	mergeCompletion.register(mergePRCmd)
+	mergePRCmd.Flags().StringVarP(&mergeMessage, "message", "m", "", "Merge commit message")
+	mergePRCmd.Flags().BoolVar(&mergeBypassPolicy, "bypass-policy", false, "Complete even if required policies have not passed")
+	mergePRCmd.Flags().StringVar(&mergeBypassReason, "reason", "", "Reason for bypassing policies")
+
+	mergePRCmd.MarkFlagsRequiredTogether("bypass-policy", "reason")
+}
+
+// parseMergeStrategy returns the Azure DevOps merge strategy for a --strategy value
+func parseMergeStrategy(value string) (string, error) {
+	if strategy, ok := mergeStrategies[strings.ToLower(value)]; ok {
+		return strategy, nil
+	}
+	return "", fmt.Errorf("invalid merge strategy %q: use no-fast-forward, squash, rebase or rebase-merge", value)
+}
+
+// policyFailureReport describes the required policies that block a pull request from
+// completing, or returns "" when there are none
+func policyFailureReport(evaluations []azdo.PolicyEvaluation) string {
+	var b strings.Builder
+	for _, evaluation := range evaluations {
+		if !evaluation.Configuration.IsEnabled || !evaluation.IsBlockingFailure() {
+			continue
+		}
+		fmt.Fprintf(&b, "\n  %s %s (%s)", policySymbol(evaluation.Status), evaluation.Name(), evaluation.Status)
+	}
+	return b.String()
+}
+
+func runMergePR(cmd *cobra.Command, args []string) error {
+	ctx := commandContext(cmd)
+
+	options, err := mergeCompletion.options()
+	if err != nil {
+		return err
+	}
+	if mergeBypassPolicy && strings.TrimSpace(mergeBypassReason) == "" {
+		return fmt.Errorf("--bypass-policy requires a --reason")
+	}
+
+	env, err := newPREnv(ctx)
+	if err != nil {
+		return err
+	}
+
+	pr, err := env.getPullRequest(ctx, args)
+	if err != nil {
+		return err
+	}
+	if pr.Status != azdo.PullRequestStatusActive {
+		return fmt.Errorf("pull request #%d is %s", pr.PullRequestID, pr.Status)
+	}
+	if pr.IsDraft {
+		return fmt.Errorf("pull request #%d is a draft. Publish it before completing it", pr.PullRequestID)
+	}
+	if pr.MergeStatus == "conflicts" {
+		return fmt.Errorf("pull request #%d has merge conflicts. Resolve them before completing it", pr.PullRequestID)
+	}
+
+	if !mergeBypassPolicy {
+		evaluations, err := env.client.GetPolicyEvaluations(ctx, pr.Repository.Project.ID, pr.PullRequestID)
+		if err != nil {
+			return fmt.Errorf("could not check branch policies: %w", err)
+		}
+		if report := policyFailureReport(evaluations); report != "" {
+			return fmt.Errorf("pull request #%d cannot be completed, required policies have not passed:%s\n\n"+
+				"Use --bypass-policy --reason \"...\" to complete it anyway", pr.PullRequestID, report)
+		}
+	}
+
+	options.MergeCommitMessage = mergeMessage
+	options.BypassPolicy = mergeBypassPolicy
+	options.BypassReason = mergeBypassReason
+	update := &azdo.PullRequestUpdate{
+		Status:                azdo.PullRequestStatusCompleted,
+		LastMergeSourceCommit: pr.LastMergeSourceCommit,
+		CompletionOptions:     options,
+	}
+	proj := env.target.Project
+	updated, err := env.client.UpdatePullRequest(ctx, proj, pr.Repository.ID, pr.PullRequestID, update)
+	if err != nil {
+		return err
+	}
+
+	// Azure DevOps merges in the background; the response may still show the pull request as active
+	if updated.Status == azdo.PullRequestStatusCompleted {
+		fmt.Printf("✓ Completed pull request #%d\n", pr.PullRequestID)
+	} else {
+		fmt.Printf("✓ Completing pull request #%d\n", pr.PullRequestID)
+	}
+	fmt.Printf("  Merged: %s → %s (%s)\n", azdo.BranchName(pr.SourceRefName), azdo.BranchName(pr.TargetRefName), mergeCompletion.describe())
+	if updated.LastMergeCommit != nil && updated.LastMergeCommit.CommitID != "" {
+		fmt.Printf("  Merge commit: %s\n", shortCommit(updated.LastMergeCommit.CommitID))
+	}
+	fmt.Printf("  URL: %s\n", env.client.PullRequestWebURL(proj, pr.Repository.Name, pr.PullRequestID))
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMergeStrategy(t *testing.T) {
+	strategy, err := parseMergeStrategy("squash")
+	require.NoError(t, err)
+	assert.Equal(t, azdo.MergeStrategySquash, strategy)
+
+	strategy, err = parseMergeStrategy("Rebase-Merge")
+	require.NoError(t, err)
+	assert.Equal(t, azdo.MergeStrategyRebaseMerge, strategy)
+
+	_, err = parseMergeStrategy("octopus")
+	assert.Error(t, err)
+}
+
+func TestRunMergePR(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     string
+		bypass     bool
+		wantErr    string
+		wantUpdate bool
+	}{
+		{name: "policies passed", policy: "approved", wantUpdate: true},
+		{name: "policy rejected", policy: "rejected", wantErr: "required policies have not passed:\n  ✗ Build (rejected)"},
+		{name: "policy bypassed", policy: "rejected", bypass: true, wantUpdate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var update *azdo.PullRequestUpdate
+			setupPRTest(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/myorg/proj/_apis/git/pullrequests/42":
+					w.Write([]byte(`{"pullRequestId": 42, "status": "active",
+						"sourceRefName": "refs/heads/feature/1/login", "targetRefName": "refs/heads/main",
+						"lastMergeSourceCommit": {"commitId": "abc123"},
+						"repository": {"id": "repo-1", "name": "web-app", "project": {"id": "p-1"}}}`))
+				case "/myorg/p-1/_apis/policy/evaluations":
+					w.Write([]byte(`{"value": [{"status": "` + tt.policy + `",
+						"configuration": {"isEnabled": true, "isBlocking": true, "type": {"displayName": "Build"}}}]}`))
+				case "/myorg/proj/_apis/git/repositories/repo-1/pullrequests/42":
+					require.Equal(t, http.MethodPatch, r.Method)
+					require.NoError(t, json.NewDecoder(r.Body).Decode(&update))
+					w.Write([]byte(`{"pullRequestId": 42, "status": "completed", "lastMergeCommit": {"commitId": "def4567890"}}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			})
+
+			oldCompletion, oldBypass, oldReason := mergeCompletion, mergeBypassPolicy, mergeBypassReason
+			defer func() {
+				mergeCompletion, mergeBypassPolicy, mergeBypassReason = oldCompletion, oldBypass, oldReason
+			}()
+			mergeCompletion = completionFlags{strategy: "squash", deleteBranch: true}
+			mergeBypassPolicy, mergeBypassReason = tt.bypass, ""
+			if tt.bypass {
+				mergeBypassReason = "Hotfix"
+			}
+
+			err := runMergePR(mergePRCmd, []string{"42"})
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.Nil(t, update)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, update)
+			assert.Equal(t, azdo.PullRequestStatusCompleted, update.Status)
+			assert.Equal(t, "abc123", update.LastMergeSourceCommit.CommitID)
+			assert.Equal(t, azdo.MergeStrategySquash, update.CompletionOptions.MergeStrategy)
+			assert.True(t, update.CompletionOptions.DeleteSourceBranch)
+			assert.Equal(t, tt.bypass, update.CompletionOptions.BypassPolicy)
+		})
+	}
+}
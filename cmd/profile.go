@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/chriskievit/dex-cli/internal/auth"
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/chriskievit/dex-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	profileRepo     string
+	profileReviewer string
+	profileNone     bool
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage named profiles",
+	Long: `Manage named profiles for working with multiple organizations and projects.
+
+Each profile has its own organization, project, repository, default reviewer and
+server URL, and its own credentials in the credential store. Select a profile with
+'dex config profile use', the --profile flag or the DEX_PROFILE environment variable.
+Repository config files cannot select a profile.`,
+}
+
+var addProfileCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add a profile",
+	Long: `Add a named profile. The organization, project and server are taken from the
+--org, --project and --server flags.
+
+Example:
+  dex config profile add client-a --org contoso --project Web --repo web-app
+  dex --profile client-a auth login`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAddProfile,
+}
+
+var useProfileCmd = &cobra.Command{
+	Use:   "use [name]",
+	Short: "Select the profile to use by default",
+	Long:  "Select the profile used when neither --profile nor DEX_PROFILE is given. Use --none to stop using a profile.",
+	Args:  cobra.RangeArgs(0, 1),
+	RunE:  runUseProfile,
+}
+
+var listProfileCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles",
+	Long:  "List all profiles. The active profile is marked with an asterisk.",
+	Args:  cobra.NoArgs,
+	RunE:  runListProfiles,
+}
+
+var removeProfileCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a profile",
+	Long:  "Remove a profile and its credentials from the credential store",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRemoveProfile,
+}
+
+func init() {
+	configCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(addProfileCmd)
+	profileCmd.AddCommand(useProfileCmd)
+	profileCmd.AddCommand(listProfileCmd)
+	profileCmd.AddCommand(removeProfileCmd)
+
+	addProfileCmd.Flags().StringVar(&profileRepo, "repo", "", "Azure DevOps repository")
+	addProfileCmd.Flags().StringVar(&profileReviewer, "reviewer", "", "Default reviewer for pull requests")
+	useProfileCmd.Flags().BoolVar(&profileNone, "none", false, "Stop using a profile")
+}
+
+func runAddProfile(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if err := config.ValidateProfileName(name); err != nil {
+		return err
+	}
+
+	profiles, err := config.LoadProfiles()
+	if err != nil {
+		return err
+	}
+	if _, exists := profiles[name]; exists {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+
+	profile := config.Profile{
+		Project:         project,
+		Repository:      profileRepo,
+		DefaultReviewer: profileReviewer,
+	}
+	if serverURL != "" {
+		profile.ServerURL = azdo.NormalizeServerURL(serverURL)
+	}
+	if organization != "" {
+		profile.Organization = azdo.NormalizeOrganization(profile.ServerURL, organization)
+	}
+
+	profiles[name] = profile
+	if err := config.SaveProfiles(profiles); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Profile added: %s\n", name)
+	if profile.Organization == "" {
+		fmt.Printf("Run 'dex --profile %s auth login' to set its organization and credentials\n", name)
+	} else {
+		fmt.Printf("Run 'dex --profile %s auth login' to store its credentials\n", name)
+	}
+
+	return nil
+}
+
+func runUseProfile(cmd *cobra.Command, args []string) error {
+	name := ""
+	switch {
+	case profileNone && len(args) > 0:
+		return fmt.Errorf("cannot combine a profile name with --none")
+	case !profileNone && len(args) == 0:
+		return fmt.Errorf("profile name is required (or use --none)")
+	case len(args) > 0:
+		name = args[0]
+	}
+
+	if name != "" {
+		profiles, err := config.LoadProfiles()
+		if err != nil {
+			return err
+		}
+		if _, exists := profiles[name]; !exists {
+			return fmt.Errorf("profile %q not found. Run 'dex config profile list' to see available profiles", name)
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	cfg.Profile = name
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	if name == "" {
+		fmt.Println("✓ No longer using a profile")
+	} else {
+		fmt.Printf("✓ Now using profile: %s\n", name)
+	}
+	if env := os.Getenv(config.EnvVar("profile")); env != "" && env != name {
+		fmt.Printf("  ⚠ Warning: %s=%s takes precedence in this shell\n", config.EnvVar("profile"), env)
+	}
+
+	return nil
+}
+
+func runListProfiles(cmd *cobra.Command, args []string) error {
+	profiles, err := config.LoadProfiles()
+	if err != nil {
+		return err
+	}
+	if len(profiles) == 0 {
+		fmt.Println("No profiles configured. Run 'dex config profile add <name>' to add one")
+		return nil
+	}
+
+	// The active profile may be selected by a flag, the environment or a repository file
+	active := ""
+	cwd, _ := os.Getwd()
+	if cfg, err := loadConfig(commandContext(cmd), cwd); err == nil {
+		active = cfg.Profile
+	}
+
+	fmt.Printf("  %-20s %-25s %-20s %s\n", "NAME", "ORGANIZATION", "PROJECT", "REPOSITORY")
+	for _, name := range config.ProfileNames(profiles) {
+		p := profiles[name]
+
+		marker := " "
+		if name == active {
+			marker = "*"
+		}
+
+		fmt.Printf("%s %-20s %-25s %-20s %s\n", marker, name,
+			formatValue(p.Organization), formatValue(p.Project), formatValue(p.Repository))
+	}
+
+	return nil
+}
+
+func runRemoveProfile(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	profiles, err := config.LoadProfiles()
+	if err != nil {
+		return err
+	}
+	profile, exists := profiles[name]
+	if !exists {
+		return fmt.Errorf("profile %q not found", name)
+	}
+
+	delete(profiles, name)
+	if err := config.SaveProfiles(profiles); err != nil {
+		return err
+	}
+
+	// Stop selecting the removed profile
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.Profile == name {
+		cfg.Profile = ""
+		if err := config.Save(cfg); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+	}
+
+	// The profile may never have been logged in to
+	store, err := credentialStore(cfg)
+	if err != nil {
+		return err
+	}
+	account := auth.Account{Organization: profile.Organization, Profile: name, Server: profile.ServerURL}
+	if err := auth.DeleteToken(store, account); err != nil && debug {
+		fmt.Printf("Note: %v\n", err)
+	}
+	if err := auth.DeleteEntraToken(store, account); err != nil && !errors.Is(err, auth.ErrNoCredentials) && debug {
+		fmt.Printf("Note: %v\n", err)
+	}
+	if err := tokenMetadata().Delete(account); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Profile removed: %s\n", name)
+
+	return nil
+}
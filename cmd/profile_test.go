@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chriskievit/dex-cli/internal/auth"
+	"github.com/chriskievit/dex-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupProfileTest points the config at a temp dir, resets the flags used by profile
+// commands and installs an in-memory credential store
+func setupProfileTest(t *testing.T) {
+	t.Helper()
+
+	originalConfigDir := config.GetConfigDir()
+	t.Cleanup(func() { config.SetConfigDir(originalConfigDir) })
+	config.SetConfigDir(filepath.Join(t.TempDir(), ".dex-cli"))
+
+	// Load would otherwise write the values earlier tests saved into the new config file
+	require.NoError(t, os.MkdirAll(config.GetConfigDir(), 0700))
+	require.NoError(t, os.WriteFile(config.GetConfigFile(), nil, 0600))
+
+	oldOrg, oldProject, oldServer, oldProfile := organization, project, serverURL, profileName
+	oldRepo, oldReviewer, oldNone := profileRepo, profileReviewer, profileNone
+	t.Cleanup(func() {
+		organization, project, serverURL, profileName = oldOrg, oldProject, oldServer, oldProfile
+		profileRepo, profileReviewer, profileNone = oldRepo, oldReviewer, oldNone
+	})
+	organization, project, serverURL, profileName = "", "", "", ""
+	profileRepo, profileReviewer, profileNone = "", "", false
+
+	oldOverride := credentialStoreOverride
+	t.Cleanup(func() { credentialStoreOverride = oldOverride })
+	credentialStoreOverride = auth.NewMemoryStore()
+
+	t.Setenv(config.EnvVar("profile"), "")
+
+	_, err := config.Load()
+	require.NoError(t, err)
+}
+
+func TestRunAddProfile(t *testing.T) {
+	setupProfileTest(t)
+
+	organization, project, profileRepo = "https://dev.azure.com/contoso/", "Web", "web-app"
+	err := runAddProfile(addProfileCmd, []string{"client-a"})
+	require.NoError(t, err)
+
+	profiles, err := config.LoadProfiles()
+	require.NoError(t, err)
+	assert.Equal(t, config.Profile{Organization: "contoso", Project: "Web", Repository: "web-app"}, profiles["client-a"])
+
+	// Duplicate names are rejected
+	err = runAddProfile(addProfileCmd, []string{"client-a"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+
+	// Invalid names are rejected
+	err = runAddProfile(addProfileCmd, []string{"Client A"})
+	assert.Error(t, err)
+}
+
+func TestRunUseProfile(t *testing.T) {
+	setupProfileTest(t)
+	require.NoError(t, config.SaveProfiles(map[string]config.Profile{"client-a": {Organization: "contoso"}}))
+
+	err := runUseProfile(useProfileCmd, []string{"missing"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	err = runUseProfile(useProfileCmd, []string{"client-a"})
+	require.NoError(t, err)
+
+	cfg, err := loadConfig(commandContext(useProfileCmd), "")
+	require.NoError(t, err)
+	assert.Equal(t, "client-a", cfg.Profile)
+	assert.Equal(t, "contoso", cfg.Organization)
+
+	// --none stops using the profile
+	profileNone = true
+	err = runUseProfile(useProfileCmd, []string{})
+	require.NoError(t, err)
+
+	loaded, err := config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "", loaded.Profile)
+}
+
+func TestRunRemoveProfile(t *testing.T) {
+	setupProfileTest(t)
+	require.NoError(t, config.SaveProfiles(map[string]config.Profile{
+		"client-a": {Organization: "contoso"},
+		"client-b": {Organization: "fabrikam"},
+	}))
+	require.NoError(t, runUseProfile(useProfileCmd, []string{"client-a"}))
+	account := auth.Account{Organization: "contoso", Profile: "client-a"}
+	require.NoError(t, credentialStoreOverride.Store(account, "client-a-token"))
+
+	err := runRemoveProfile(removeProfileCmd, []string{"client-a"})
+	require.NoError(t, err)
+
+	profiles, err := config.LoadProfiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"client-b"}, config.ProfileNames(profiles))
+
+	// Its credentials are removed as well
+	_, err = credentialStoreOverride.Token(account)
+	assert.ErrorIs(t, err, auth.ErrNoCredentials)
+
+	// The removed profile is no longer selected
+	loaded, err := config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "", loaded.Profile)
+
+	err = runRemoveProfile(removeProfileCmd, []string{"client-a"})
+	assert.Error(t, err)
+}
+
+func TestCredentialAccount(t *testing.T) {
+	account := credentialAccount(&config.Config{}, "contoso")
+	assert.Equal(t, "organization contoso", account.String())
+
+	account = credentialAccount(&config.Config{Profile: "client-a"}, "contoso")
+	assert.Equal(t, "profile client-a", account.String())
+
+	cfg := &config.Config{Profile: "client-a", ProfileOrganization: "contoso"}
+	account = credentialAccount(cfg, "Contoso")
+	assert.Equal(t, "profile client-a", account.String())
+
+	// Another organization, e.g. from a repository's config or --org, never gets the profile's token
+	account = credentialAccount(cfg, "fabrikam")
+	assert.Equal(t, "organization fabrikam", account.String())
+
+	cfg = &config.Config{Profile: "client-a", ProfileOrganization: "contoso", ServerURL: "https://tfs.example.com/tfs"}
+	account = credentialAccount(cfg, "contoso")
+	assert.Equal(t, "organization contoso on https://tfs.example.com/tfs", account.String())
+
+	cfg.ProfileServerURL = "https://tfs.example.com/tfs/"
+	account = credentialAccount(cfg, "contoso")
+	assert.Equal(t, "profile client-a", account.String())
+}
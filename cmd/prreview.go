@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reviewApprove                bool
+	reviewApproveWithSuggestions bool
+	reviewWait                   bool
+	reviewReject                 bool
+	reviewReset                  bool
+	reviewComment                string
+)
+
+var reviewPRCmd = &cobra.Command{
+	Use:   "review <id>",
+	Short: "Vote on a pull request",
+	Long: `Cast your vote on a pull request, optionally with a comment that is posted as a
+new comment thread. Exactly one vote is required.
+
+Example:
+  dex pr review 1234 --approve
+  dex pr review 1234 --wait --comment "Please add tests for the error paths"
+  dex pr review 1234 --reset`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReviewPR,
+}
+
+func init() {
+	prCmd.AddCommand(reviewPRCmd)
+
+	reviewPRCmd.Flags().BoolVar(&reviewApprove, "approve", false, "Approve")
+	reviewPRCmd.Flags().BoolVar(&reviewApproveWithSuggestions, "approve-with-suggestions", false, "Approve with suggestions")
+	reviewPRCmd.Flags().BoolVar(&reviewWait, "wait", false, "Wait for author")
+	reviewPRCmd.Flags().BoolVar(&reviewReject, "reject", false, "Reject")
+	reviewPRCmd.Flags().BoolVar(&reviewReset, "reset", false, "Reset your vote")
+	reviewPRCmd.Flags().StringVarP(&reviewComment, "comment", "m", "", "Comment to post with the vote")
+
+	votes := []string{"approve", "approve-with-suggestions", "wait", "reject", "reset"}
+	reviewPRCmd.MarkFlagsMutuallyExclusive(votes...)
+	reviewPRCmd.MarkFlagsOneRequired(votes...)
+}
+
+// reviewVote returns the vote selected by the review flags
+func reviewVote() (int, error) {
+	switch {
+	case reviewApprove:
+		return azdo.VoteApproved, nil
+	case reviewApproveWithSuggestions:
+		return azdo.VoteApprovedWithSuggestions, nil
+	case reviewWait:
+		return azdo.VoteWaitingForAuthor, nil
+	case reviewReject:
+		return azdo.VoteRejected, nil
+	case reviewReset:
+		return azdo.VoteNone, nil
+	}
+	return 0, fmt.Errorf("a vote is required: use --approve, --approve-with-suggestions, --wait, --reject or --reset")
+}
+
+func runReviewPR(cmd *cobra.Command, args []string) error {
+	ctx := commandContext(cmd)
+
+	vote, err := reviewVote()
+	if err != nil {
+		return err
+	}
+
+	env, err := newPREnv(ctx)
+	if err != nil {
+		return err
+	}
+
+	pr, err := env.getPullRequest(ctx, args)
+	if err != nil {
+		return err
+	}
+	if pr.Status != azdo.PullRequestStatusActive {
+		return fmt.Errorf("pull request #%d is %s", pr.PullRequestID, pr.Status)
+	}
+
+	// Votes are cast as the identity the credentials belong to
+	reviewerID, err := resolveIdentity(ctx, env.client, env.target.Project, meIdentity)
+	if err != nil {
+		return err
+	}
+
+	proj, repoID := env.target.Project, pr.Repository.ID
+	if _, err := env.client.SetReviewerVote(ctx, proj, repoID, pr.PullRequestID, reviewerID, vote); err != nil {
+		return err
+	}
+
+	if vote == azdo.VoteNone {
+		fmt.Printf("✓ Reset your vote on pull request #%d\n", pr.PullRequestID)
+	} else {
+		fmt.Printf("✓ Voted on pull request #%d: %s\n", pr.PullRequestID, azdo.VoteDescription(vote))
+	}
+
+	if reviewComment != "" {
+		thread, err := env.client.CreateThread(ctx, proj, repoID, pr.PullRequestID, azdo.NewCommentThread(reviewComment))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ Added comment (thread %d)\n", thread.ID)
+	}
+
+	fmt.Printf("  URL: %s\n", env.client.PullRequestWebURL(proj, pr.Repository.Name, pr.PullRequestID))
+
+	return nil
+}
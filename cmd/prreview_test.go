@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunReviewPR(t *testing.T) {
+	var vote map[string]int
+	var thread azdo.Thread
+	setupPRTest(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/myorg/proj/_apis/git/pullrequests/42":
+			w.Write([]byte(`{"pullRequestId": 42, "status": "active", "repository": {"id": "repo-1", "name": "web-app"}}`))
+		case "/myorg/proj/_apis/git/pullrequests/43":
+			w.Write([]byte(`{"pullRequestId": 43, "status": "completed", "repository": {"id": "repo-1", "name": "web-app"}}`))
+		case "/myorg/proj/_apis/git/repositories/repo-1/pullrequests/42/reviewers/" + testUserID:
+			require.Equal(t, http.MethodPut, r.Method)
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&vote))
+			w.Write([]byte(`{"id": "` + testUserID + `"}`))
+		case "/myorg/proj/_apis/git/repositories/repo-1/pullrequests/42/threads":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&thread))
+			w.Write([]byte(`{"id": 9}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	oldWait, oldComment := reviewWait, reviewComment
+	defer func() { reviewWait, reviewComment = oldWait, oldComment }()
+
+	reviewWait, reviewComment = true, "Please add tests"
+	require.NoError(t, runReviewPR(reviewPRCmd, []string{"42"}))
+	assert.Equal(t, map[string]int{"vote": azdo.VoteWaitingForAuthor}, vote)
+	require.Len(t, thread.Comments, 1)
+	assert.Equal(t, "Please add tests", thread.Comments[0].Content)
+
+	err := runReviewPR(reviewPRCmd, []string{"43"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is completed")
+}
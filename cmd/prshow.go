@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chriskievit/dex-cli/internal/azdo"
+	"github.com/chriskievit/dex-cli/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var showPRCmd = &cobra.Command{
+	Use:   "show [id]",
+	Short: "Show a pull request",
+	Long: `Show a summary of a pull request: its status, reviewers and their votes, branch
+policy results and linked work items.
+
+Without an ID, the active pull request for the current branch is shown.
+
+Example:
+  dex pr show
+  dex pr show 1234`,
+	Args: cobra.RangeArgs(0, 1),
+	RunE: runShowPR,
+}
+
+func init() {
+	prCmd.AddCommand(showPRCmd)
+}
+
+// parsePRID parses a pull request ID argument, accepting an optional leading "!"
+// as Azure DevOps writes pull request mentions
+func parsePRID(arg string) (int, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(arg, "!"))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid pull request ID: %s", arg)
+	}
+	return id, nil
+}
+
+// getPullRequest returns the pull request with the ID in args, or the active pull request
+// for the current branch when args is empty
+func (env *prEnv) getPullRequest(ctx context.Context, args []string) (*azdo.PullRequest, error) {
+	if len(args) > 0 {
+		id, err := parsePRID(args[0])
+		if err != nil {
+			return nil, err
+		}
+		pr, err := env.client.GetPullRequest(ctx, env.target.Project, id)
+		if err != nil {
+			if azdo.IsNotFound(err) {
+				return nil, fmt.Errorf("pull request %d not found in project %q: %w", id, env.target.Project, err)
+			}
+			return nil, err
+		}
+		return pr, nil
+	}
+
+	branch, err := git.GetCurrentBranch(ctx, env.cwd)
+	if err != nil {
+		return nil, fmt.Errorf("no pull request ID given and %w", err)
+	}
+	return env.pullRequestForBranch(ctx, branch)
+}
+
+// pullRequestForBranch finds the single active pull request from branch, in the current
+// repository when it is known
+func (env *prEnv) pullRequestForBranch(ctx context.Context, branch string) (*azdo.PullRequest, error) {
+	criteria := azdo.PullRequestSearchCriteria{
+		Status:        azdo.PullRequestStatusActive,
+		SourceRefName: azdo.FormatRefName(branch),
+	}
+	if env.target.Repository != "" {
+		repository, err := env.client.GetRepository(ctx, env.target.Project, env.target.Repository)
+		if err != nil {
+			return nil, err
+		}
+		criteria.RepositoryID = repository.ID
+	}
+
+	pullRequests, err := env.client.ListPullRequests(ctx, env.target.Project, criteria, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(pullRequests) {
+	case 0:
+		return nil, fmt.Errorf("no active pull request found for branch %s", branch)
+	case 1:
+		// The list omits details such as work item links, so fetch the pull request itself
+		return env.client.GetPullRequest(ctx, env.target.Project, pullRequests[0].PullRequestID)
+	}
+
+	ids := make([]string, len(pullRequests))
+	for i, pr := range pullRequests {
+		ids[i] = fmt.Sprintf("#%d (into %s)", pr.PullRequestID, azdo.BranchName(pr.TargetRefName))
+	}
+	return nil, fmt.Errorf("branch %s has %d active pull requests: %s. Specify the pull request ID",
+		branch, len(pullRequests), strings.Join(ids, ", "))
+}
+
+func runShowPR(cmd *cobra.Command, args []string) error {
+	ctx := commandContext(cmd)
+
+	env, err := newPREnv(ctx)
+	if err != nil {
+		return err
+	}
+
+	pr, err := env.getPullRequest(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	// Work items and policies are separate resources; the summary is still useful without them
+	workItems, err := env.client.GetPullRequestWorkItems(ctx, env.target.Project, pr.Repository.ID, pr.PullRequestID)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		fmt.Printf("⚠ Warning: could not get linked work items: %s\n", describeError(err))
+	}
+	evaluations, err := env.client.GetPolicyEvaluations(ctx, pr.Repository.Project.ID, pr.PullRequestID)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		fmt.Printf("⚠ Warning: could not get policy evaluations: %s\n", describeError(err))
+	}
+
+	printPullRequest(pr, workItems, evaluations)
+	fmt.Printf("\n  URL: %s\n", env.client.PullRequestWebURL(env.target.Project, pr.Repository.Name, pr.PullRequestID))
+
+	return nil
+}
+
+// printPullRequest prints a compact summary of a pull request
+func printPullRequest(pr *azdo.PullRequest, workItems []azdo.ResourceRef, evaluations []azdo.PolicyEvaluation) {
+	title := pr.Title
+	if pr.IsDraft {
+		title += " [draft]"
+	}
+	fmt.Printf("#%d %s\n", pr.PullRequestID, title)
+
+	status := pr.Status
+	if pr.MergeStatus != "" && pr.Status == azdo.PullRequestStatusActive {
+		status += ", merge " + pr.MergeStatus
+	}
+	fmt.Printf("  Status: %s\n", status)
+	fmt.Printf("  Author: %s\n", pr.CreatedBy.DisplayName)
+	if !pr.CreationDate.IsZero() {
+		fmt.Printf("  Created: %s\n", pr.CreationDate.Local().Format("2006-01-02 15:04"))
+	}
+	fmt.Printf("  Repository: %s\n", pr.Repository.Name)
+	fmt.Printf("  Branches: %s → %s\n", azdo.BranchName(pr.SourceRefName), azdo.BranchName(pr.TargetRefName))
+	if pr.AutoCompleteSetBy != nil {
+		fmt.Printf("  Auto-complete: set by %s\n", pr.AutoCompleteSetBy.DisplayName)
+	}
+	if pr.LastMergeCommit != nil && pr.LastMergeCommit.CommitID != "" {
+		fmt.Printf("  Last merge commit: %s\n", shortCommit(pr.LastMergeCommit.CommitID))
+	}
+
+	fmt.Println("\nReviewers:")
+	if len(pr.Reviewers) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, reviewer := range pr.Reviewers {
+		suffix := ""
+		if reviewer.IsRequired {
+			suffix = " [required]"
+		}
+		if reviewer.HasDeclined {
+			suffix += " [declined]"
+		}
+		fmt.Printf("  %s %s (%s)%s\n", voteSymbol(reviewer.Vote), reviewer.DisplayName, azdo.VoteDescription(reviewer.Vote), suffix)
+	}
+
+	if len(evaluations) > 0 {
+		fmt.Println("\nPolicies:")
+		for _, evaluation := range evaluations {
+			if !evaluation.Configuration.IsEnabled {
+				continue
+			}
+			kind := "optional"
+			if evaluation.Configuration.IsBlocking {
+				kind = "required"
+			}
+			fmt.Printf("  %s %s (%s, %s)\n", policySymbol(evaluation.Status), evaluation.Name(), evaluation.Status, kind)
+		}
+	}
+
+	fmt.Println("\nWork items:")
+	if len(workItems) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, workItem := range workItems {
+		fmt.Printf("  #%s\n", workItem.ID)
+	}
+
+	if description := strings.TrimSpace(pr.Description); description != "" {
+		fmt.Println("\nDescription:")
+		for _, line := range strings.Split(description, "\n") {
+			fmt.Printf("  %s\n", strings.TrimRight(line, "\r"))
+		}
+	}
+}
+
+// voteSymbol marks a reviewer vote
+func voteSymbol(vote int) string {
+	switch {
+	case vote >= azdo.VoteApprovedWithSuggestions:
+		return "✓"
+	case vote == azdo.VoteNone:
+		return "-"
+	case vote > azdo.VoteRejected:
+		return "…"
+	default:
+		return "✗"
+	}
+}
+
+// policySymbol marks a policy evaluation status
+func policySymbol(status string) string {
+	switch status {
+	case azdo.PolicyStatusApproved:
+		return "✓"
+	case azdo.PolicyStatusRejected, azdo.PolicyStatusBroken:
+		return "✗"
+	case azdo.PolicyStatusNotApplicable:
+		return "-"
+	default:
+		return "…"
+	}
+}
+
+// shortCommit abbreviates a commit ID like git does
+func shortCommit(commitID string) string {
+	if len(commitID) > 7 {
+		return commitID[:7]
+	}
+	return commitID
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePRID(t *testing.T) {
+	id, err := parsePRID("42")
+	require.NoError(t, err)
+	assert.Equal(t, 42, id)
+
+	id, err = parsePRID("!42")
+	require.NoError(t, err)
+	assert.Equal(t, 42, id)
+
+	for _, arg := range []string{"", "0", "-1", "abc"} {
+		_, err := parsePRID(arg)
+		assert.Error(t, err, arg)
+	}
+}
+
+// prShowHandler serves pull request 42 with its work items and policy evaluations
+func prShowHandler(active string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/myorg/proj/_apis/git/pullrequests/42":
+			w.Write([]byte(`{"pullRequestId": 42, "title": "Add login", "status": "active", "mergeStatus": "succeeded",
+				"sourceRefName": "refs/heads/feature/1/login", "targetRefName": "refs/heads/main",
+				"createdBy": {"displayName": "Jane Doe"},
+				"repository": {"id": "repo-1", "name": "web-app", "project": {"id": "p-1"}},
+				"reviewers": [{"displayName": "John Smith", "vote": 10, "isRequired": true}],
+				"description": "Adds the login page"}`))
+		case "/myorg/proj/_apis/git/pullrequests":
+			w.Write([]byte(active))
+		case "/myorg/proj/_apis/git/repositories/repo-1/pullrequests/42/workitems":
+			w.Write([]byte(`{"value": [{"id": "123"}]}`))
+		case "/myorg/p-1/_apis/policy/evaluations":
+			w.Write([]byte(`{"value": [{"status": "rejected",
+				"configuration": {"isEnabled": true, "isBlocking": true, "type": {"displayName": "Build"}}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func TestRunShowPR(t *testing.T) {
+	setupPRTest(t, prShowHandler(`{"value": []}`))
+
+	require.NoError(t, runShowPR(showPRCmd, []string{"42"}))
+	assert.Error(t, runShowPR(showPRCmd, []string{"43"}))
+}
+
+func TestPullRequestForBranch(t *testing.T) {
+	tests := []struct {
+		name    string
+		active  string
+		wantID  int
+		wantErr string
+	}{
+		{name: "none", active: `{"value": []}`, wantErr: "no active pull request found for branch feature/1/login"},
+		{name: "one", active: `{"value": [{"pullRequestId": 42}]}`, wantID: 42},
+		{
+			name:    "several",
+			active:  `{"value": [{"pullRequestId": 42, "targetRefName": "refs/heads/main"}, {"pullRequestId": 43, "targetRefName": "refs/heads/release"}]}`,
+			wantErr: "#42 (into main), #43 (into release)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupPRTest(t, prShowHandler(tt.active))
+			env, err := newPREnv(context.Background())
+			require.NoError(t, err)
+			env.target.Repository = ""
+
+			pr, err := env.pullRequestForBranch(context.Background(), "feature/1/login")
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, pr.PullRequestID)
+		})
+	}
+}
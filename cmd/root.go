@@ -1,9 +1,17 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/chriskievit/dex-cli/internal/config"
+	"github.com/chriskievit/dex-cli/internal/logging"
 	"github.com/spf13/cobra"
 )
 
@@ -11,7 +19,25 @@ var (
 	// Global flags
 	organization string
 	project      string
+	serverURL    string
+	profileName  string
 	debug        bool
+
+	retryMaxAttempts int
+	retryMaxDelay    time.Duration
+
+	logLevel      string
+	traceFilePath string
+	timeout       time.Duration
+)
+
+var (
+	// logger receives diagnostic output; it is set up before every command runs
+	logger    *slog.Logger
+	traceFile *os.File
+
+	// cancelTimeout releases the --timeout deadline once the command finishes
+	cancelTimeout context.CancelFunc
 )
 
 // rootCmd represents the base command
@@ -25,12 +51,34 @@ var rootCmd = &cobra.Command{
   
 All credentials are stored securely in your system's keychain.`,
 	Version: "1.0.0",
+	// Errors are printed by Execute so API failures can include hints
+	SilenceErrors:     true,
+	PersistentPreRunE: persistentPreRun,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately
+// Ctrl-C (SIGINT) and SIGTERM cancel the command's context, which aborts in-flight
+// API requests and interrupts running git processes.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	err := rootCmd.ExecuteContext(ctx)
+	interrupted := ctx.Err() != nil
+
+	stop()
+	if cancelTimeout != nil {
+		cancelTimeout()
+	}
+	if traceFile != nil {
+		traceFile.Close()
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", describeError(err))
+		if interrupted {
+			// Conventional exit code for termination by SIGINT
+			os.Exit(130)
+		}
 		os.Exit(1)
 	}
 }
@@ -39,5 +87,83 @@ func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVarP(&organization, "org", "o", "", "Azure DevOps organization")
 	rootCmd.PersistentFlags().StringVarP(&project, "project", "p", "", "Azure DevOps project")
+	rootCmd.PersistentFlags().StringVar(&serverURL, "server", "", "Azure DevOps server URL (defaults to https://dev.azure.com)")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Named profile to use (overrides DEX_PROFILE and the configured profile)")
 	rootCmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "Enable debug output")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "Diagnostic output level: debug, info, warn or error (default warn, or debug with --debug)")
+	rootCmd.PersistentFlags().StringVar(&traceFilePath, "trace-file", "", "Append a JSON trace of every API request to this file")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Abort the command if it takes longer than this (e.g. 2m; default no limit)")
+	rootCmd.PersistentFlags().IntVar(&retryMaxAttempts, "retries", 0, "Maximum attempts for throttled or failed API requests (default 4)")
+	rootCmd.PersistentFlags().DurationVar(&retryMaxDelay, "retry-max-delay", 0, "Maximum wait between API request attempts (default 30s)")
+}
+
+// resolveServerURL returns the Azure DevOps server URL from the --server flag or the
+// configuration (which includes the DEX_SERVER_URL environment variable).
+// An empty result means Azure DevOps Services.
+func resolveServerURL(cfg *config.Config) string {
+	if serverURL != "" {
+		return serverURL
+	}
+	return cfg.ServerURL
+}
+
+// persistentPreRun prepares logging and the command context before any command runs
+func persistentPreRun(cmd *cobra.Command, args []string) error {
+	if err := setupLogging(cmd, args); err != nil {
+		return err
+	}
+	return applyTimeout(cmd)
+}
+
+// applyTimeout limits the command's context to the --timeout duration
+func applyTimeout(cmd *cobra.Command) error {
+	if timeout < 0 {
+		return fmt.Errorf("--timeout cannot be negative")
+	}
+	if timeout == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(commandContext(cmd), timeout)
+	cancelTimeout = cancel
+	cmd.SetContext(ctx)
+	return nil
+}
+
+// commandContext returns the command's context, or a background context when the
+// command was not started through Execute (e.g. in tests)
+func commandContext(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
+
+// setupLogging creates the diagnostic logger from the --log-level, --debug and --trace-file flags.
+// Credentials are never written to either destination.
+func setupLogging(cmd *cobra.Command, args []string) error {
+	level := slog.LevelWarn
+	if debug {
+		level = slog.LevelDebug
+	}
+	if logLevel != "" {
+		parsed, err := logging.ParseLevel(logLevel)
+		if err != nil {
+			return err
+		}
+		level = parsed
+	}
+
+	var trace io.Writer
+	if traceFilePath != "" {
+		f, err := os.OpenFile(traceFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open trace file: %w", err)
+		}
+		traceFile = f
+		trace = f
+	}
+
+	logger = logging.New(os.Stderr, level, trace)
+	return nil
 }
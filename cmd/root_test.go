@@ -2,10 +2,18 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
 
+	"github.com/chriskievit/dex-cli/internal/config"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRootCmd_Initialization(t *testing.T) {
@@ -29,6 +37,14 @@ func TestRootCmd_GlobalFlags(t *testing.T) {
 	assert.NotNil(t, projectFlag)
 	assert.Equal(t, "p", projectFlag.Shorthand)
 
+	// Test server flag
+	serverFlag := rootCmd.PersistentFlags().Lookup("server")
+	assert.NotNil(t, serverFlag)
+
+	// Test timeout flag
+	timeoutFlag := rootCmd.PersistentFlags().Lookup("timeout")
+	assert.NotNil(t, timeoutFlag)
+
 	// Test debug flag
 	debugFlag := rootCmd.PersistentFlags().Lookup("debug")
 	assert.NotNil(t, debugFlag)
@@ -68,3 +84,83 @@ func TestGlobalFlags_Variables(t *testing.T) {
 	assert.NotNil(t, &project)
 	assert.NotNil(t, &debug)
 }
+
+func TestResolveServerURL(t *testing.T) {
+	oldServerURL := serverURL
+	defer func() { serverURL = oldServerURL }()
+
+	cfg := &config.Config{ServerURL: "https://config.example"}
+
+	// Config value is used when the flag is not set
+	serverURL = ""
+	assert.Equal(t, "https://config.example", resolveServerURL(cfg))
+
+	// Flag overrides config
+	serverURL = "https://flag.example"
+	assert.Equal(t, "https://flag.example", resolveServerURL(cfg))
+}
+
+func TestSetupLogging(t *testing.T) {
+	oldLevel, oldTrace, oldDebug := logLevel, traceFilePath, debug
+	defer func() {
+		logLevel, traceFilePath, debug = oldLevel, oldTrace, oldDebug
+		if traceFile != nil {
+			traceFile.Close()
+			traceFile = nil
+		}
+	}()
+
+	// --debug enables debug output
+	logLevel, traceFilePath, debug = "", "", true
+	require.NoError(t, setupLogging(rootCmd, nil))
+	assert.True(t, logger.Enabled(context.Background(), slog.LevelDebug))
+
+	// --log-level takes precedence over --debug
+	logLevel = "error"
+	require.NoError(t, setupLogging(rootCmd, nil))
+	assert.False(t, logger.Enabled(context.Background(), slog.LevelWarn))
+
+	// Invalid levels are rejected
+	logLevel = "loud"
+	assert.Error(t, setupLogging(rootCmd, nil))
+
+	// The trace file is created with owner-only permissions
+	logLevel = ""
+	traceFilePath = filepath.Join(t.TempDir(), "trace.jsonl")
+	require.NoError(t, setupLogging(rootCmd, nil))
+	info, err := os.Stat(traceFilePath)
+	require.NoError(t, err)
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+}
+
+func TestApplyTimeout(t *testing.T) {
+	oldTimeout := timeout
+	defer func() {
+		timeout = oldTimeout
+		if cancelTimeout != nil {
+			cancelTimeout()
+			cancelTimeout = nil
+		}
+	}()
+
+	cmd := &cobra.Command{}
+
+	// No timeout leaves the context untouched
+	timeout = 0
+	require.NoError(t, applyTimeout(cmd))
+	_, hasDeadline := commandContext(cmd).Deadline()
+	assert.False(t, hasDeadline)
+
+	// A timeout sets a deadline on the command context
+	timeout = time.Minute
+	require.NoError(t, applyTimeout(cmd))
+	deadline, hasDeadline := commandContext(cmd).Deadline()
+	assert.True(t, hasDeadline)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, 5*time.Second)
+
+	// Negative timeouts are rejected
+	timeout = -time.Second
+	assert.Error(t, applyTimeout(&cobra.Command{}))
+}
@@ -7,9 +7,7 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/chriskievit/dex-cli/internal/auth"
 	"github.com/chriskievit/dex-cli/internal/azdo"
-	"github.com/chriskievit/dex-cli/internal/config"
 	"github.com/chriskievit/dex-cli/internal/git"
 	"github.com/spf13/cobra"
 )
@@ -60,6 +58,8 @@ func init() {
 }
 
 func runShowWorkitem(cmd *cobra.Command, args []string) error {
+	ctx := commandContext(cmd)
+
 	workItemIDStr := args[0]
 
 	// Parse work item ID
@@ -68,32 +68,34 @@ func runShowWorkitem(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid work item ID: %s", workItemIDStr)
 	}
 
+	// The working directory is only used to detect repository configuration and
+	// defaults from the git remote
+	cwd, _ := os.Getwd()
+
 	// Load config
-	cfg, err := config.Load()
+	cfg, err := loadConfig(ctx, cwd)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
-	org := organization
-	if org == "" {
-		org = cfg.Organization
-	}
+	t := resolveTarget(ctx, cfg.Config, cwd)
+	server, org := t.Server, t.Organization
 	if org == "" {
 		return fmt.Errorf("organization not configured. Use --org flag or run 'dex auth login'")
 	}
 
-	// Get authentication token
-	token, err := auth.GetToken(org, debug)
+	// Create an Azure DevOps client with the credentials in effect
+	client, err := authenticatedClient(cfg.Config, server, org)
 	if err != nil {
 		return err
 	}
 
-	// Create Azure DevOps client
-	client := azdo.NewClient(org, token, debug)
-
 	// Fetch work item
-	workItem, err := client.GetWorkItem(workItemID)
+	workItem, err := client.GetWorkItem(ctx, workItemID)
 	if err != nil {
+		if azdo.IsNotFound(err) {
+			return fmt.Errorf("work item #%d does not exist: %w", workItemID, err)
+		}
 		return fmt.Errorf("failed to fetch work item: %w", err)
 	}
 
@@ -104,19 +106,21 @@ func runShowWorkitem(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Type:        %s\n", workItem.GetWorkItemType())
 	fmt.Printf("State:       %s\n", workItem.GetState())
 	fmt.Printf("Assigned To: %s\n", workItem.GetAssignedTo())
-	fmt.Printf("URL:         https://dev.azure.com/%s/_workitems/edit/%d\n", org, workItem.ID)
+	fmt.Printf("URL:         %s\n", client.WorkItemWebURL(workItem.ID))
 
 	return nil
 }
 
 func runStartWorkitem(cmd *cobra.Command, args []string) error {
+	ctx := commandContext(cmd)
+
 	// Check if we're in a Git repository first (fail fast)
 	cwd, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("failed to get current directory: %w", err)
 	}
 
-	if !git.IsGitRepository(cwd) {
+	if !git.IsGitRepository(ctx, cwd) {
 		return fmt.Errorf("not a git repository. Please run this command from within a git repository")
 	}
 
@@ -129,45 +133,43 @@ func runStartWorkitem(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load config
-	cfg, err := config.Load()
+	cfg, err := loadConfig(ctx, cwd)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
-	org := organization
-	if org == "" {
-		org = cfg.Organization
-	}
+	t := resolveTarget(ctx, cfg.Config, cwd)
+	server, org := t.Server, t.Organization
 	if org == "" {
 		return fmt.Errorf("organization not configured. Use --org flag or run 'dex auth login'")
 	}
 
-	// Get authentication token
-	token, err := auth.GetToken(org, debug)
+	// Create an Azure DevOps client with the credentials in effect
+	client, err := authenticatedClient(cfg.Config, server, org)
 	if err != nil {
 		return err
 	}
 
-	// Create Azure DevOps client
-	client := azdo.NewClient(org, token, debug)
-
 	// Step 1: Verify work item exists
 	fmt.Printf("Step 1: Verifying work item #%d exists...\n", workItemID)
-	workItem, err := client.GetWorkItem(workItemID)
+	workItem, err := client.GetWorkItem(ctx, workItemID)
 	if err != nil {
+		if azdo.IsNotFound(err) {
+			return fmt.Errorf("work item #%d does not exist: %w", workItemID, err)
+		}
 		return fmt.Errorf("failed to fetch work item: %w", err)
 	}
 	fmt.Printf("✓ Work item found: %s #%d - %s\n", workItem.GetWorkItemType(), workItemID, workItem.GetTitle())
 
 	// Step 2: Optionally checkout base branch
-	currentBranch, err := git.GetCurrentBranch(cwd)
+	currentBranch, err := git.GetCurrentBranch(ctx, cwd)
 	if err != nil {
 		return fmt.Errorf("failed to get current branch: %w", err)
 	}
 
 	if startBaseBranch != "" {
 		fmt.Printf("Step 2: Checking out base branch '%s'...\n", startBaseBranch)
-		if err := git.CheckoutBranch(cwd, startBaseBranch); err != nil {
+		if err := git.CheckoutBranch(ctx, cwd, startBaseBranch); err != nil {
 			return fmt.Errorf("failed to checkout base branch: %w", err)
 		}
 		fmt.Printf("✓ Checked out branch: %s\n", startBaseBranch)
@@ -180,10 +182,13 @@ func runStartWorkitem(cmd *cobra.Command, args []string) error {
 	workItemType := workItem.GetWorkItemType()
 	workItemTitle := workItem.GetTitle()
 	description := generateBranchDescription(workItemTitle)
-	branchName := fmt.Sprintf("%s/%d/%s", workItemType, workItemID, description)
+	branchName, err := formatBranchName(cfg.BranchFormat, workItemType, workItemID, description)
+	if err != nil {
+		return err
+	}
 
 	// Check if branch already exists
-	exists, err := git.BranchExists(cwd, branchName)
+	exists, err := git.BranchExists(ctx, cwd, branchName)
 	if err != nil {
 		return fmt.Errorf("failed to check if branch exists: %w", err)
 	}
@@ -193,7 +198,7 @@ func runStartWorkitem(cmd *cobra.Command, args []string) error {
 
 	// Step 3: Create the branch
 	fmt.Printf("Step 3: Creating branch '%s' from '%s'...\n", branchName, currentBranch)
-	if err := git.CreateBranch(cwd, branchName, currentBranch); err != nil {
+	if err := git.CreateBranch(ctx, cwd, branchName, currentBranch); err != nil {
 		return fmt.Errorf("failed to create branch: %w", err)
 	}
 	fmt.Printf("✓ Branch created: %s\n", branchName)
@@ -201,14 +206,14 @@ func runStartWorkitem(cmd *cobra.Command, args []string) error {
 	// Step 4: Create commit with work item reference to link it
 	fmt.Printf("Step 4: Linking branch to work item #%d...\n", workItemID)
 	commitMessage := fmt.Sprintf("Start work on #%d: %s", workItemID, workItemTitle)
-	if err := git.CreateCommit(cwd, commitMessage); err != nil {
+	if err := git.CreateCommit(ctx, cwd, commitMessage); err != nil {
 		return fmt.Errorf("failed to create commit: %w", err)
 	}
 	fmt.Printf("✓ Commit created with work item reference\n")
 
 	// Step 5: Push the branch
 	fmt.Printf("Step 5: Pushing branch to remote...\n")
-	if err := git.PushBranch(cwd, branchName); err != nil {
+	if err := git.PushBranch(ctx, cwd, branchName); err != nil {
 		return fmt.Errorf("failed to push branch: %w", err)
 	}
 	fmt.Printf("✓ Branch pushed to remote\n")
@@ -7,6 +7,7 @@ require (
 	github.com/spf13/viper v1.21.0
 	github.com/stretchr/testify v1.11.1
 	github.com/zalando/go-keyring v0.2.6
+	golang.org/x/crypto v0.32.0
 	golang.org/x/term v0.28.0
 )
 
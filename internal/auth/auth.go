@@ -1,72 +1,97 @@
 package auth
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
-	"github.com/zalando/go-keyring"
+	"github.com/chriskievit/dex-cli/internal/azdo"
 )
 
 const (
 	serviceName = "dex-cli"
 )
 
-// StoreToken securely stores the PAT in the system keychain
-func StoreToken(organization, token string) error {
-	if organization == "" {
-		return fmt.Errorf("organization cannot be empty")
+// Account identifies a keychain entry: the token of an organization on a server, or of a
+// named profile, so that profiles pointing at the same organization keep separate tokens
+type Account struct {
+	Organization string
+	Profile      string
+	// Server is the server URL of the organization (empty = dev.azure.com). Collections of
+	// the same name on different servers have separate entries.
+	Server string
+
+	// entra selects the account's Entra ID sign-in rather than its PAT
+	entra bool
+}
+
+// username returns the keychain account name
+func (a Account) username() string {
+	name := normalizeOrganization(a.Organization)
+	if server := a.server(); server != azdo.DefaultServerURL {
+		name = server + "|" + name
 	}
-	if token == "" {
-		return fmt.Errorf("token cannot be empty")
+	if a.Profile != "" {
+		name = "profile:" + a.Profile
 	}
-
-	// Validate token format (basic check)
-	if len(token) < 20 {
-		return fmt.Errorf("invalid token format")
+	if a.entra {
+		return "entra:" + name
 	}
+	return name
+}
 
-	// Use organization URL as the username for keyring
-	username := normalizeOrganization(organization)
+// server returns the normalized, lower-cased server URL of the account
+func (a Account) server() string {
+	return strings.ToLower(azdo.NormalizeServerURL(a.Server))
+}
 
-	if err := keyring.Set(serviceName, username, token); err != nil {
-		return fmt.Errorf("failed to store token in keychain: %w", err)
+// String describes the account for messages
+func (a Account) String() string {
+	if a.Profile != "" {
+		return fmt.Sprintf("profile %s", a.Profile)
+	}
+	if server := a.server(); server != azdo.DefaultServerURL {
+		return fmt.Sprintf("organization %s on %s", a.Organization, server)
 	}
+	return fmt.Sprintf("organization %s", a.Organization)
+}
 
+// validate checks that the account identifies a keychain entry
+func (a Account) validate() error {
+	if a.Organization == "" && a.Profile == "" {
+		return fmt.Errorf("organization cannot be empty")
+	}
 	return nil
 }
 
-// GetToken retrieves the PAT from the system keychain
-func GetToken(organization string, debug bool) (string, error) {
-	if organization == "" {
-		return "", fmt.Errorf("organization cannot be empty")
+// StoreToken validates the PAT and saves it for account in store
+func StoreToken(store CredentialStore, account Account, token string) error {
+	if err := account.validate(); err != nil {
+		return err
+	}
+	if token == "" {
+		return fmt.Errorf("token cannot be empty")
 	}
 
-	username := normalizeOrganization(organization)
-
-	token, err := keyring.Get(serviceName, username)
-	if err != nil {
-		if err == keyring.ErrNotFound {
-			return "", fmt.Errorf("no credentials found for organization %s. Please run 'dex-cli auth login' first", organization)
-		}
-		return "", fmt.Errorf("failed to retrieve token from keychain: %w", err)
+	// Validate token format (basic check)
+	if len(token) < 20 {
+		return fmt.Errorf("invalid token format")
 	}
 
-	return token, nil
+	return store.Store(account, token)
 }
 
-// DeleteToken removes the PAT from the system keychain
-func DeleteToken(organization string) error {
-	if organization == "" {
-		return fmt.Errorf("organization cannot be empty")
+// DeleteToken removes the PAT for account from store
+func DeleteToken(store CredentialStore, account Account) error {
+	if err := account.validate(); err != nil {
+		return err
 	}
 
-	username := normalizeOrganization(organization)
-
-	if err := keyring.Delete(serviceName, username); err != nil {
-		if err == keyring.ErrNotFound {
-			return fmt.Errorf("no credentials found for organization %s", organization)
+	if err := store.Delete(account); err != nil {
+		if errors.Is(err, ErrNoCredentials) {
+			return fmt.Errorf("%w for %s in %s", ErrNoCredentials, account, store.Name())
 		}
-		return fmt.Errorf("failed to delete token from keychain: %w", err)
+		return err
 	}
 
 	return nil
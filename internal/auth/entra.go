@@ -0,0 +1,370 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultEntraAuthority accepts work and school accounts from any tenant
+	DefaultEntraAuthority = "https://login.microsoftonline.com/organizations"
+	// DefaultEntraClientID is the public client ID of the Azure CLI, which Azure DevOps
+	// accepts tokens from without registering an application
+	DefaultEntraClientID = "04b07795-8ddb-461a-bbee-02f9e1bf7b46"
+	// AzureDevOpsScope requests access to Azure DevOps, plus a refresh token
+	AzureDevOpsScope = "499b84ac-1321-427f-aa17-267ca6975798/.default offline_access"
+
+	deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+	// defaultPollInterval is used when the device code response has no interval
+	defaultPollInterval = 5 * time.Second
+	// expiryMargin renews access tokens shortly before they expire
+	expiryMargin = time.Minute
+)
+
+// EntraClient performs the OAuth 2.0 device authorization grant and token refreshes
+// against a Microsoft Entra ID authority. The authority can point to any server that
+// implements the /oauth2/v2.0/devicecode and /oauth2/v2.0/token endpoints.
+type EntraClient struct {
+	authority  string
+	clientID   string
+	httpClient *http.Client
+	sleep      func(context.Context, time.Duration) error
+}
+
+// NewEntraClient creates a client for authority and clientID, falling back to
+// DefaultEntraAuthority and DefaultEntraClientID when empty
+func NewEntraClient(authority, clientID string) *EntraClient {
+	if authority == "" {
+		authority = DefaultEntraAuthority
+	}
+	if clientID == "" {
+		clientID = DefaultEntraClientID
+	}
+	return &EntraClient{
+		authority:  strings.TrimRight(authority, "/"),
+		clientID:   clientID,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		sleep:      sleepContext,
+	}
+}
+
+// DeviceCode is the code the user enters to approve a device code sign-in
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	// ExpiresIn and Interval are in seconds
+	ExpiresIn int    `json:"expires_in"`
+	Interval  int    `json:"interval"`
+	Message   string `json:"message"`
+}
+
+// EntraToken is a signed-in Entra ID session. The credential store keeps only the refresh
+// token, with the authority and client ID so it is refreshed where it was issued. The
+// access token stays in memory: a JWT alone can exceed the size limit of system keychains
+// (2560 bytes on Windows).
+type EntraToken struct {
+	Authority    string    `json:"authority"`
+	ClientID     string    `json:"client_id"`
+	AccessToken  string    `json:"-"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"-"`
+}
+
+// valid reports whether the access token can still be used at now
+func (t *EntraToken) valid(now time.Time) bool {
+	return t.AccessToken != "" && now.Add(expiryMargin).Before(t.ExpiresAt)
+}
+
+// tokenResponse is the body of a successful token endpoint response
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// OAuthError is an error returned by the authority, such as "invalid_grant"
+type OAuthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// Error implements the error interface
+func (e *OAuthError) Error() string {
+	if e.Description != "" {
+		// Entra ID descriptions carry trace IDs on further lines
+		description, _, _ := strings.Cut(e.Description, "\n")
+		return fmt.Sprintf("%s: %s", e.Code, strings.TrimSpace(description))
+	}
+	return e.Code
+}
+
+// RequestDeviceCode starts a device code sign-in for Azure DevOps
+func (c *EntraClient) RequestDeviceCode(ctx context.Context) (*DeviceCode, error) {
+	form := url.Values{
+		"client_id": {c.clientID},
+		"scope":     {AzureDevOpsScope},
+	}
+
+	var code DeviceCode
+	if err := c.post(ctx, "devicecode", form, &code); err != nil {
+		return nil, fmt.Errorf("failed to start device code sign-in: %w", err)
+	}
+	if code.DeviceCode == "" || code.UserCode == "" {
+		return nil, fmt.Errorf("failed to start device code sign-in: incomplete response from %s", c.authority)
+	}
+	return &code, nil
+}
+
+// PollToken waits until the user approves the device code and returns the issued token.
+// It fails when the user declines, the code expires or ctx is cancelled.
+func (c *EntraClient) PollToken(ctx context.Context, code *DeviceCode) (*EntraToken, error) {
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	form := url.Values{
+		"grant_type":  {deviceCodeGrantType},
+		"client_id":   {c.clientID},
+		"device_code": {code.DeviceCode},
+	}
+
+	for {
+		token, err := c.requestToken(ctx, form)
+		if err == nil {
+			return token, nil
+		}
+
+		var oauthErr *OAuthError
+		if !errors.As(err, &oauthErr) {
+			return nil, err
+		}
+		switch oauthErr.Code {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		case "authorization_declined":
+			return nil, fmt.Errorf("sign-in was declined")
+		case "expired_token":
+			return nil, fmt.Errorf("the device code expired before sign-in was completed")
+		default:
+			return nil, err
+		}
+
+		if err := c.sleep(ctx, interval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Refresh redeems the refresh token of token for a new access token
+func (c *EntraClient) Refresh(ctx context.Context, token *EntraToken) (*EntraToken, error) {
+	if token.RefreshToken == "" {
+		return nil, fmt.Errorf("no refresh token available")
+	}
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {c.clientID},
+		"refresh_token": {token.RefreshToken},
+		"scope":         {AzureDevOpsScope},
+	}
+
+	refreshed, err := c.requestToken(ctx, form)
+	if err != nil {
+		return nil, err
+	}
+	// The authority may keep the refresh token instead of rotating it
+	if refreshed.RefreshToken == "" {
+		refreshed.RefreshToken = token.RefreshToken
+	}
+	return refreshed, nil
+}
+
+// requestToken posts form to the token endpoint
+func (c *EntraClient) requestToken(ctx context.Context, form url.Values) (*EntraToken, error) {
+	var resp tokenResponse
+	if err := c.post(ctx, "token", form, &resp); err != nil {
+		return nil, err
+	}
+	if resp.AccessToken == "" {
+		return nil, fmt.Errorf("no access token in response from %s", c.authority)
+	}
+	return &EntraToken{
+		Authority:    c.authority,
+		ClientID:     c.clientID,
+		AccessToken:  resp.AccessToken,
+		RefreshToken: resp.RefreshToken,
+		ExpiresAt:    time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second),
+	}, nil
+}
+
+// post sends a form to the authority's OAuth endpoint and decodes the JSON response
+// into result. OAuth error responses are returned as *OAuthError.
+func (c *EntraClient) post(ctx context.Context, endpoint string, form url.Values, result interface{}) error {
+	endpointURL := fmt.Sprintf("%s/oauth2/v2.0/%s", c.authority, endpoint)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpointURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", endpointURL, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response from %s: %w", endpointURL, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var oauthErr OAuthError
+		if err := json.Unmarshal(body, &oauthErr); err == nil && oauthErr.Code != "" {
+			return &oauthErr
+		}
+		return fmt.Errorf("%s returned status %d", endpointURL, resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to parse response from %s: %w", endpointURL, err)
+	}
+	return nil
+}
+
+// EntraTokenSource supplies the access token of a stored Entra ID sign-in, refreshing
+// it when it expires or is rejected and saving the renewed token back to the store
+type EntraTokenSource struct {
+	store   CredentialStore
+	account Account
+	client  *EntraClient
+
+	mu    sync.Mutex
+	token *EntraToken
+}
+
+// StoreEntraToken saves the Entra ID sign-in for account in store
+func StoreEntraToken(store CredentialStore, account Account, token *EntraToken) error {
+	if err := account.validate(); err != nil {
+		return err
+	}
+	account.entra = true
+	return storeEntraToken(store, account, token)
+}
+
+// storeEntraToken saves token under account, which must already be an Entra ID account
+func storeEntraToken(store CredentialStore, account Account, token *EntraToken) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+	return store.Store(account, string(data))
+}
+
+// DeleteEntraToken removes the Entra ID sign-in for account from store, returning an
+// error wrapping ErrNoCredentials if there is none
+func DeleteEntraToken(store CredentialStore, account Account) error {
+	if err := account.validate(); err != nil {
+		return err
+	}
+	account.entra = true
+	return store.Delete(account)
+}
+
+// LoadEntraTokenSource returns a token source for the Entra ID sign-in stored for
+// account, or an error wrapping ErrNoCredentials if the account has not signed in
+func LoadEntraTokenSource(store CredentialStore, account Account) (*EntraTokenSource, error) {
+	if err := account.validate(); err != nil {
+		return nil, err
+	}
+	account.entra = true
+
+	data, err := store.Token(account)
+	if err != nil {
+		return nil, err
+	}
+	var token EntraToken
+	if err := json.Unmarshal([]byte(data), &token); err != nil {
+		return nil, fmt.Errorf("failed to parse stored Entra ID token: %w", err)
+	}
+	// The access token is not stored, so the first use refreshes it
+	return newEntraTokenSource(store, account, &token), nil
+}
+
+// NewEntraTokenSource returns a token source for a sign-in that was just stored for
+// account with StoreEntraToken, using its access token until it expires
+func NewEntraTokenSource(store CredentialStore, account Account, token *EntraToken) *EntraTokenSource {
+	account.entra = true
+	return newEntraTokenSource(store, account, token)
+}
+
+func newEntraTokenSource(store CredentialStore, account Account, token *EntraToken) *EntraTokenSource {
+	return &EntraTokenSource{
+		store:   store,
+		account: account,
+		client:  NewEntraClient(token.Authority, token.ClientID),
+		token:   token,
+	}
+}
+
+// Name describes where the sign-in is stored
+func (s *EntraTokenSource) Name() string {
+	return fmt.Sprintf("Entra ID sign-in (%s)", s.store.Name())
+}
+
+// AccessToken returns the current access token, refreshing it first when it has expired
+func (s *EntraTokenSource) AccessToken(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.token.valid(time.Now()) {
+		if err := s.refresh(ctx); err != nil {
+			return "", err
+		}
+	}
+	return s.token.AccessToken, nil
+}
+
+// Refresh obtains a new access token, e.g. after the current one was rejected
+func (s *EntraTokenSource) Refresh(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.refresh(ctx)
+}
+
+func (s *EntraTokenSource) refresh(ctx context.Context) error {
+	token, err := s.client.Refresh(ctx, s.token)
+	if err != nil {
+		return fmt.Errorf("failed to refresh Entra ID sign-in for %s: %w. Run 'dex auth login --entra' to sign in again", s.account, err)
+	}
+	// Refresh tokens rotate, so the new one must be kept for the next command
+	if err := storeEntraToken(s.store, s.account, token); err != nil {
+		return fmt.Errorf("failed to save refreshed token: %w", err)
+	}
+	s.token = token
+	return nil
+}
+
+// sleepContext waits for d or until ctx is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,220 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAuthority serves the device code and token endpoints of a tenant. The device
+// code is approved after pending polls; refresh tokens rotate on every use.
+func newTestAuthority(t *testing.T, pending int, outcome string) *httptest.Server {
+	t.Helper()
+
+	refreshes := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tenant/oauth2/v2.0/devicecode", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "test-client", r.PostForm.Get("client_id"))
+		assert.Equal(t, AzureDevOpsScope, r.PostForm.Get("scope"))
+		json.NewEncoder(w).Encode(DeviceCode{
+			DeviceCode:      "device-123",
+			UserCode:        "ABCD-EFGH",
+			VerificationURI: "https://microsoft.com/devicelogin",
+			ExpiresIn:       900,
+			Interval:        1,
+		})
+	})
+	mux.HandleFunc("/tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		switch r.PostForm.Get("grant_type") {
+		case deviceCodeGrantType:
+			assert.Equal(t, "device-123", r.PostForm.Get("device_code"))
+			if pending > 0 {
+				pending--
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":"authorization_pending","error_description":"AADSTS70016: Pending\r\nTrace ID: 1"}`)
+				return
+			}
+			if outcome != "" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, `{"error":%q}`, outcome)
+				return
+			}
+			fmt.Fprint(w, `{"access_token":"access-0","refresh_token":"refresh-0","expires_in":3600}`)
+		case "refresh_token":
+			if r.PostForm.Get("refresh_token") != fmt.Sprintf("refresh-%d", refreshes) {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":"invalid_grant","error_description":"AADSTS70008: The refresh token has expired"}`)
+				return
+			}
+			refreshes++
+			fmt.Fprintf(w, `{"access_token":"access-%d","refresh_token":"refresh-%d","expires_in":3600}`, refreshes, refreshes)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"unsupported_grant_type"}`)
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestEntraClient(server *httptest.Server) (*EntraClient, *[]time.Duration) {
+	var slept []time.Duration
+	client := NewEntraClient(server.URL+"/tenant/", "test-client")
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return ctx.Err()
+	}
+	return client, &slept
+}
+
+func TestEntraClient_DeviceCodeFlow(t *testing.T) {
+	server := newTestAuthority(t, 2, "")
+	client, slept := newTestEntraClient(server)
+
+	code, err := client.RequestDeviceCode(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "ABCD-EFGH", code.UserCode)
+
+	token, err := client.PollToken(context.Background(), code)
+	require.NoError(t, err)
+	assert.Equal(t, "access-0", token.AccessToken)
+	assert.Equal(t, "refresh-0", token.RefreshToken)
+	assert.Equal(t, server.URL+"/tenant", token.Authority)
+	assert.Equal(t, "test-client", token.ClientID)
+	assert.True(t, token.valid(time.Now()))
+	assert.Equal(t, []time.Duration{time.Second, time.Second}, *slept)
+}
+
+func TestEntraClient_DeviceCodeFailures(t *testing.T) {
+	tests := []struct {
+		outcome string
+		want    string
+	}{
+		{outcome: "authorization_declined", want: "sign-in was declined"},
+		{outcome: "expired_token", want: "device code expired"},
+		{outcome: "bad_verification_code", want: "bad_verification_code"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.outcome, func(t *testing.T) {
+			client, _ := newTestEntraClient(newTestAuthority(t, 0, tt.outcome))
+
+			code, err := client.RequestDeviceCode(context.Background())
+			require.NoError(t, err)
+			_, err = client.PollToken(context.Background(), code)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestEntraTokenSource(t *testing.T) {
+	server := newTestAuthority(t, 0, "")
+	store := NewMemoryStore()
+	account := Account{Organization: "myorg"}
+
+	_, err := LoadEntraTokenSource(store, account)
+	assert.ErrorIs(t, err, ErrNoCredentials)
+
+	// An expired access token is refreshed before use
+	require.NoError(t, StoreEntraToken(store, account, &EntraToken{
+		Authority:    server.URL + "/tenant",
+		ClientID:     "test-client",
+		AccessToken:  "access-0",
+		RefreshToken: "refresh-0",
+		ExpiresAt:    time.Now().Add(-time.Minute),
+	}))
+	// The sign-in does not replace a PAT for the same account
+	_, err = store.Token(account)
+	assert.ErrorIs(t, err, ErrNoCredentials)
+
+	source, err := LoadEntraTokenSource(store, account)
+	require.NoError(t, err)
+	token, err := source.AccessToken(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", token)
+
+	// A rejected token is refreshed on request, and the rotated refresh token is stored
+	require.NoError(t, source.Refresh(context.Background()))
+	token, err = source.AccessToken(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "access-2", token)
+
+	reloaded, err := LoadEntraTokenSource(store, account)
+	require.NoError(t, err)
+	assert.Equal(t, "refresh-2", reloaded.token.RefreshToken)
+
+	// A stale refresh token cannot be used again
+	source.token.RefreshToken = "refresh-0"
+	err = source.Refresh(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid_grant: AADSTS70008")
+	assert.Contains(t, err.Error(), "auth login --entra")
+
+	require.NoError(t, DeleteEntraToken(store, account))
+	assert.ErrorIs(t, DeleteEntraToken(store, account), ErrNoCredentials)
+}
+
+// limitedStore rejects tokens larger than the Windows Credential Manager accepts, as
+// go-keyring does
+type limitedStore struct {
+	*MemoryStore
+}
+
+func (s limitedStore) Store(account Account, token string) error {
+	if len(token) > 2560 {
+		return fmt.Errorf("data passed to Set was too big")
+	}
+	return s.MemoryStore.Store(account, token)
+}
+
+func TestEntraTokenSource_LargeAccessToken(t *testing.T) {
+	// Access tokens for Azure DevOps are JWTs of several kilobytes
+	accessToken := "eyJ0eXAiOiJKV1QiLCJhbGciOiJSUzI1NiJ9." + strings.Repeat("a", 3000) + "." + strings.Repeat("s", 342)
+	refreshToken := "1.AQ" + strings.Repeat("r", 1800)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, refreshToken, r.PostForm.Get("refresh_token"))
+		fmt.Fprintf(w, `{"access_token":%q,"refresh_token":%q,"expires_in":3600}`, accessToken, refreshToken)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	store := limitedStore{NewMemoryStore()}
+	account := Account{Organization: "myorg"}
+	token := &EntraToken{
+		Authority:    server.URL + "/tenant",
+		ClientID:     "test-client",
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+	require.NoError(t, StoreEntraToken(store, account, token))
+
+	// The source of a new sign-in uses its access token without refreshing
+	access, err := NewEntraTokenSource(store, account, token).AccessToken(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, accessToken, access)
+
+	// A loaded sign-in refreshes first, and stores the rotated refresh token again
+	source, err := LoadEntraTokenSource(store, account)
+	require.NoError(t, err)
+	access, err = source.AccessToken(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, accessToken, access)
+	require.NoError(t, source.Refresh(context.Background()))
+}
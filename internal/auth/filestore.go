@@ -0,0 +1,244 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// PassphraseEnvVar holds the passphrase of the encrypted credentials file
+	PassphraseEnvVar = "DEX_CREDENTIAL_PASSPHRASE"
+
+	fileStoreVersion = 1
+	fileStoreKDF     = "pbkdf2-sha256"
+
+	// defaultIterations follows the OWASP recommendation for PBKDF2-HMAC-SHA256
+	defaultIterations = 600000
+
+	saltSize = 16
+	keySize  = 32 // AES-256
+)
+
+// FileStore keeps tokens in a file encrypted with AES-256-GCM under a key derived
+// from a passphrase. It is an alternative to the system keychain for machines
+// without one, such as Linux servers and WSL without a Secret Service.
+type FileStore struct {
+	path       string
+	passphrase func() (string, error)
+	iterations int
+
+	mu     sync.Mutex
+	secret string
+}
+
+// encryptedFile is the on-disk format of a FileStore
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// NewFileStore creates a store backed by the encrypted file at path. passphrase is
+// called at most once, the first time the file is read or written.
+func NewFileStore(path string, passphrase func() (string, error)) *FileStore {
+	return &FileStore{path: path, passphrase: passphrase, iterations: defaultIterations}
+}
+
+// Name implements Source
+func (s *FileStore) Name() string {
+	return fmt.Sprintf("encrypted file %s", s.path)
+}
+
+// Token implements Source
+func (s *FileStore) Token(account Account) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	token, ok := tokens[account.username()]
+	if !ok {
+		return "", ErrNoCredentials
+	}
+	return token, nil
+}
+
+// Store implements CredentialStore
+func (s *FileStore) Store(account Account, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	tokens[account.username()] = token
+	return s.write(tokens)
+}
+
+// Delete implements CredentialStore
+func (s *FileStore) Delete(account Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := tokens[account.username()]; !ok {
+		return ErrNoCredentials
+	}
+	delete(tokens, account.username())
+	return s.write(tokens)
+}
+
+// getPassphrase returns the passphrase, asking for it only once
+func (s *FileStore) getPassphrase() (string, error) {
+	if s.secret != "" {
+		return s.secret, nil
+	}
+	secret, err := s.passphrase()
+	if err != nil {
+		return "", err
+	}
+	if secret == "" {
+		return "", fmt.Errorf("a passphrase is required for the encrypted credentials file")
+	}
+	s.secret = secret
+	return secret, nil
+}
+
+// read decrypts the file into a map of keychain usernames to tokens. A missing
+// file is an empty store and does not require the passphrase.
+func (s *FileStore) read() (map[string]string, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials file %s: %w", s.path, err)
+	}
+	if file.Version != fileStoreVersion || file.KDF != fileStoreKDF || file.Iterations <= 0 {
+		return nil, fmt.Errorf("unsupported credentials file format in %s", s.path)
+	}
+
+	secret, err := s.getPassphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(secret, file.Salt, file.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt credentials file %s: wrong passphrase or corrupted file", s.path)
+	}
+
+	tokens := map[string]string{}
+	if err := json.Unmarshal(plaintext, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted credentials: %w", err)
+	}
+	return tokens, nil
+}
+
+// write encrypts tokens with a fresh salt and nonce and atomically replaces the file
+func (s *FileStore) write(tokens map[string]string) error {
+	secret, err := s.getPassphrase()
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := json.Marshal(tokens)
+	if err != nil {
+		return fmt.Errorf("failed to encode credentials: %w", err)
+	}
+
+	file := encryptedFile{
+		Version:    fileStoreVersion,
+		KDF:        fileStoreKDF,
+		Iterations: s.iterations,
+		Salt:       make([]byte, saltSize),
+	}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	gcm, err := newGCM(secret, file.Salt, file.Iterations)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	file.Ciphertext = gcm.Seal(nil, file.Nonce, plaintext, nil)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode credentials file: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create credentials directory: %w", err)
+	}
+
+	// Write to a temporary file first so a failed write never loses existing credentials
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".credentials-*")
+	if err != nil {
+		return fmt.Errorf("failed to write credentials file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write credentials file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write credentials file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write credentials file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write credentials file: %w", err)
+	}
+
+	return nil
+}
+
+// newGCM derives the file key from the passphrase and returns an AES-GCM cipher
+func newGCM(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	if len(salt) == 0 {
+		return nil, errors.New("credentials file has no salt")
+	}
+	key := pbkdf2.Key([]byte(passphrase), salt, iterations, keySize, sha256.New)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+func TestPBKDF2(t *testing.T) {
+	// Test vectors for PBKDF2-HMAC-SHA256 from RFC 7914, section 11. The earlier built-in
+	// implementation met them too, so existing credentials files still decrypt.
+	tests := []struct {
+		password   string
+		salt       string
+		iterations int
+		keyLen     int
+		expected   string
+	}{
+		{"passwd", "salt", 1, 64, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+		{"Password", "NaCl", 80000, 64, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"},
+		{"password", "salt", 4096, 20, "c5e478d59288c841aa530db6845c4c8d962893a0"},
+	}
+
+	for _, tt := range tests {
+		key := pbkdf2.Key([]byte(tt.password), []byte(tt.salt), tt.iterations, tt.keyLen, sha256.New)
+		assert.Equal(t, tt.expected, hex.EncodeToString(key))
+	}
+}
+
+// newTestFileStore creates a file store with a cheap key derivation
+func newTestFileStore(path, passphrase string) (*FileStore, *int) {
+	calls := 0
+	store := NewFileStore(path, func() (string, error) {
+		calls++
+		return passphrase, nil
+	})
+	store.iterations = 1000
+	return store, &calls
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	store, calls := newTestFileStore(path, "correct horse")
+	account := Account{Organization: "myorg"}
+
+	// Reading a missing file needs no passphrase
+	_, err := store.Token(account)
+	assert.ErrorIs(t, err, ErrNoCredentials)
+	assert.Equal(t, 0, *calls)
+
+	require.NoError(t, store.Store(account, "secret-token"))
+	require.NoError(t, store.Store(Account{Organization: "myorg", Profile: "work"}, "work-token"))
+
+	token, err := store.Token(account)
+	require.NoError(t, err)
+	assert.Equal(t, "secret-token", token)
+	assert.Equal(t, 1, *calls, "passphrase should be asked once")
+
+	// The file is private and does not contain the token in plain text
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret-token")
+
+	// A new store with the same passphrase reads the tokens
+	reopened, _ := newTestFileStore(path, "correct horse")
+	token, err = reopened.Token(Account{Organization: "myorg", Profile: "work"})
+	require.NoError(t, err)
+	assert.Equal(t, "work-token", token)
+
+	require.NoError(t, reopened.Delete(account))
+	_, err = reopened.Token(account)
+	assert.ErrorIs(t, err, ErrNoCredentials)
+	assert.ErrorIs(t, reopened.Delete(account), ErrNoCredentials)
+}
+
+func TestFileStore_WrongPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	store, _ := newTestFileStore(path, "correct horse")
+	require.NoError(t, store.Store(Account{Organization: "myorg"}, "secret-token"))
+
+	wrong, _ := newTestFileStore(path, "battery staple")
+	_, err := wrong.Token(Account{Organization: "myorg"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "wrong passphrase")
+	assert.False(t, errors.Is(err, ErrNoCredentials))
+}
+
+func TestFileStore_EmptyPassphrase(t *testing.T) {
+	store, _ := newTestFileStore(filepath.Join(t.TempDir(), "credentials.enc"), "")
+	err := store.Store(Account{Organization: "myorg"}, "secret-token")
+	assert.Error(t, err)
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	account := Account{Organization: "myorg"}
+
+	_, err := store.Token(account)
+	assert.ErrorIs(t, err, ErrNoCredentials)
+
+	require.NoError(t, store.Store(account, "token"))
+	token, err := store.Token(account)
+	require.NoError(t, err)
+	assert.Equal(t, "token", token)
+
+	require.NoError(t, store.Delete(account))
+	assert.ErrorIs(t, store.Delete(account), ErrNoCredentials)
+}
+
+func TestStoreToken(t *testing.T) {
+	store := NewMemoryStore()
+
+	assert.Error(t, StoreToken(store, Account{}, "a-sufficiently-long-token"))
+	assert.Error(t, StoreToken(store, Account{Organization: "myorg"}, ""))
+	assert.Error(t, StoreToken(store, Account{Organization: "myorg"}, "short"))
+	require.NoError(t, StoreToken(store, Account{Organization: "myorg"}, "a-sufficiently-long-token"))
+
+	require.NoError(t, DeleteToken(store, Account{Organization: "myorg"}))
+	err := DeleteToken(store, Account{Organization: "myorg"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no credentials found for organization myorg in in-memory store")
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// TokenMetadata is non-secret information about a stored token
+type TokenMetadata struct {
+	StoredAt time.Time `json:"stored_at"`
+	// ExpiresAt is the expiration date entered at login; zero when unknown
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// HasExpiry reports whether the expiration date is known
+func (m TokenMetadata) HasExpiry() bool {
+	return !m.ExpiresAt.IsZero()
+}
+
+// DaysRemaining returns the number of whole days until the token expires,
+// which is negative once it has expired
+func (m TokenMetadata) DaysRemaining(now time.Time) int {
+	return int(math.Floor(m.ExpiresAt.Sub(now).Hours() / 24))
+}
+
+// MetadataFile keeps token metadata in a JSON file, keyed like the credential store,
+// because credential stores such as the system keychain only hold the secret
+type MetadataFile struct {
+	path string
+}
+
+// NewMetadataFile creates a metadata file at path
+func NewMetadataFile(path string) *MetadataFile {
+	return &MetadataFile{path: path}
+}
+
+// Get returns the metadata for account, and false if none is recorded
+func (f *MetadataFile) Get(account Account) (TokenMetadata, bool, error) {
+	entries, err := f.read()
+	if err != nil {
+		return TokenMetadata{}, false, err
+	}
+	metadata, ok := entries[account.username()]
+	return metadata, ok, nil
+}
+
+// Set records the metadata for account
+func (f *MetadataFile) Set(account Account, metadata TokenMetadata) error {
+	entries, err := f.read()
+	if err != nil {
+		return err
+	}
+	entries[account.username()] = metadata
+	return f.write(entries)
+}
+
+// Delete removes the metadata for account, if any
+func (f *MetadataFile) Delete(account Account) error {
+	entries, err := f.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[account.username()]; !ok {
+		return nil
+	}
+	delete(entries, account.username())
+	return f.write(entries)
+}
+
+func (f *MetadataFile) read() (map[string]TokenMetadata, error) {
+	entries := map[string]TokenMetadata{}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, fmt.Errorf("failed to read token metadata: %w", err)
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse token metadata %s: %w", f.path, err)
+	}
+	return entries, nil
+}
+
+func (f *MetadataFile) write(entries map[string]TokenMetadata) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode token metadata: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(f.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write token metadata: %w", err)
+	}
+	return nil
+}
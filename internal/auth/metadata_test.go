@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataFile(t *testing.T) {
+	file := NewMetadataFile(filepath.Join(t.TempDir(), "tokens.json"))
+	account := Account{Organization: "myorg"}
+
+	_, ok, err := file.Get(account)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	expires := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	stored := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, file.Set(account, TokenMetadata{StoredAt: stored, ExpiresAt: expires}))
+	require.NoError(t, file.Set(Account{Organization: "myorg", Profile: "work"}, TokenMetadata{StoredAt: stored}))
+
+	metadata, ok, err := file.Get(account)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.True(t, metadata.ExpiresAt.Equal(expires))
+	assert.True(t, metadata.HasExpiry())
+
+	metadata, ok, err = file.Get(Account{Organization: "myorg", Profile: "work"})
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.False(t, metadata.HasExpiry())
+
+	require.NoError(t, file.Delete(account))
+	require.NoError(t, file.Delete(account))
+	_, ok, err = file.Get(account)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestTokenMetadata_DaysRemaining(t *testing.T) {
+	metadata := TokenMetadata{ExpiresAt: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)}
+
+	assert.Equal(t, 31, metadata.DaysRemaining(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 0, metadata.DaysRemaining(time.Date(2026, 10, 31, 12, 0, 0, 0, time.UTC)))
+	assert.Equal(t, -1, metadata.DaysRemaining(time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC)))
+}
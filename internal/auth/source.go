@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	// TokenEnvVar holds a PAT for dex specifically
+	TokenEnvVar = "DEX_TOKEN"
+	// AzureDevOpsTokenEnvVar is the PAT variable shared with the Azure CLI devops extension
+	AzureDevOpsTokenEnvVar = "AZURE_DEVOPS_EXT_PAT"
+)
+
+// ErrNoCredentials is returned by a Source that has no token for an account
+var ErrNoCredentials = errors.New("no credentials found")
+
+// Source provides tokens for accounts, e.g. from the environment or the system keychain
+type Source interface {
+	// Name describes where the token came from, for display in 'auth status'
+	Name() string
+	// Token returns the token for account, or an error wrapping ErrNoCredentials
+	// if the source has none
+	Token(account Account) (string, error)
+}
+
+// Credential is a token together with the name of the source that provided it
+type Credential struct {
+	Token  string
+	Source string
+}
+
+// Chain tries each source in order and uses the first token found
+type Chain []Source
+
+// DefaultChain returns the sources used by dex: the DEX_TOKEN and AZURE_DEVOPS_EXT_PAT
+// environment variables, then the given credential store
+func DefaultChain(store CredentialStore) Chain {
+	return append(EnvironmentChain(), store)
+}
+
+// EnvironmentChain returns the environment variable sources of DefaultChain. A token in
+// the environment takes precedence over any stored credentials.
+func EnvironmentChain() Chain {
+	return Chain{
+		EnvSource{Var: TokenEnvVar},
+		EnvSource{Var: AzureDevOpsTokenEnvVar},
+	}
+}
+
+// Credential returns the first token any source provides for account.
+// Errors other than missing credentials stop the search.
+func (c Chain) Credential(account Account) (*Credential, error) {
+	if err := account.validate(); err != nil {
+		return nil, err
+	}
+
+	for _, source := range c {
+		token, err := source.Token(account)
+		if err == nil {
+			return &Credential{Token: token, Source: source.Name()}, nil
+		}
+		if !errors.Is(err, ErrNoCredentials) {
+			return nil, fmt.Errorf("%s: %w", source.Name(), err)
+		}
+	}
+
+	return nil, fmt.Errorf("%w for %s. Please run 'dex-cli auth login' first or set %s", ErrNoCredentials, account, TokenEnvVar)
+}
+
+// EnvSource reads the token from an environment variable. It applies to every account.
+type EnvSource struct {
+	Var string
+}
+
+// Name implements Source
+func (s EnvSource) Name() string {
+	return fmt.Sprintf("environment variable %s", s.Var)
+}
+
+// Token implements Source
+func (s EnvSource) Token(account Account) (string, error) {
+	token := os.Getenv(s.Var)
+	if token == "" {
+		return "", ErrNoCredentials
+	}
+	return token, nil
+}
@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticSource is a Source with fixed tokens per keychain username
+type staticSource struct {
+	name   string
+	tokens map[string]string
+	err    error
+}
+
+func (s staticSource) Name() string { return s.name }
+
+func (s staticSource) Token(account Account) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	if token, ok := s.tokens[account.username()]; ok {
+		return token, nil
+	}
+	return "", ErrNoCredentials
+}
+
+func TestEnvSource(t *testing.T) {
+	source := EnvSource{Var: TokenEnvVar}
+	assert.Equal(t, "environment variable DEX_TOKEN", source.Name())
+
+	t.Setenv(TokenEnvVar, "")
+	_, err := source.Token(Account{Organization: "myorg"})
+	assert.ErrorIs(t, err, ErrNoCredentials)
+
+	t.Setenv(TokenEnvVar, "env-token")
+	token, err := source.Token(Account{Organization: "myorg"})
+	require.NoError(t, err)
+	assert.Equal(t, "env-token", token)
+}
+
+func TestChain_Credential(t *testing.T) {
+	first := staticSource{name: "first", tokens: map[string]string{"myorg": "first-token"}}
+	second := staticSource{name: "second", tokens: map[string]string{"myorg": "second-token", "profile:work": "work-token"}}
+	chain := Chain{first, second}
+
+	t.Run("first source wins", func(t *testing.T) {
+		cred, err := chain.Credential(Account{Organization: "myorg"})
+		require.NoError(t, err)
+		assert.Equal(t, &Credential{Token: "first-token", Source: "first"}, cred)
+	})
+
+	t.Run("falls through to later sources", func(t *testing.T) {
+		cred, err := chain.Credential(Account{Organization: "myorg", Profile: "work"})
+		require.NoError(t, err)
+		assert.Equal(t, &Credential{Token: "work-token", Source: "second"}, cred)
+	})
+
+	t.Run("no source has a token", func(t *testing.T) {
+		_, err := chain.Credential(Account{Organization: "otherorg"})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrNoCredentials)
+		assert.Contains(t, err.Error(), "organization otherorg")
+	})
+
+	t.Run("source failures stop the search", func(t *testing.T) {
+		broken := staticSource{name: "broken", err: errors.New("keychain locked")}
+		_, err := Chain{broken, second}.Credential(Account{Organization: "myorg"})
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrNoCredentials)
+		assert.Contains(t, err.Error(), "broken: keychain locked")
+	})
+
+	t.Run("empty account", func(t *testing.T) {
+		_, err := chain.Credential(Account{})
+		assert.Error(t, err)
+	})
+}
+
+func TestDefaultChain_EnvironmentPrecedence(t *testing.T) {
+	t.Setenv(TokenEnvVar, "dex-token")
+	t.Setenv(AzureDevOpsTokenEnvVar, "az-token")
+
+	cred, err := DefaultChain(NewMemoryStore()).Credential(Account{Organization: "myorg"})
+	require.NoError(t, err)
+	assert.Equal(t, "dex-token", cred.Token)
+	assert.Equal(t, "environment variable DEX_TOKEN", cred.Source)
+
+	t.Setenv(TokenEnvVar, "")
+	cred, err = DefaultChain(NewMemoryStore()).Credential(Account{Organization: "myorg"})
+	require.NoError(t, err)
+	assert.Equal(t, "az-token", cred.Token)
+}
+
+func TestAccount(t *testing.T) {
+	assert.Equal(t, "myorg", Account{Organization: "https://dev.azure.com/myorg/"}.username())
+	assert.Equal(t, "profile:work", Account{Organization: "myorg", Profile: "work"}.username())
+	assert.Equal(t, "organization myorg", Account{Organization: "myorg"}.String())
+	assert.Equal(t, "profile work", Account{Organization: "myorg", Profile: "work"}.String())
+
+	// Organizations on other servers are told apart by the server
+	assert.Equal(t, "myorg", Account{Organization: "myorg", Server: "https://dev.azure.com/"}.username())
+	assert.Equal(t, "https://tfs.corp|DefaultCollection", Account{Organization: "DefaultCollection", Server: "https://TFS.corp/"}.username())
+	assert.Equal(t, "organization myorg on https://tfs.corp:8080", Account{Organization: "myorg", Server: "tfs.corp:8080"}.String())
+}
+
+func TestAccount_ServerSeparatesTokens(t *testing.T) {
+	store := NewMemoryStore()
+	services := Account{Organization: "myorg"}
+	require.NoError(t, StoreToken(store, services, "dev-azure-com-token-value"))
+
+	// A collection of the same name elsewhere never gets the dev.azure.com token
+	for _, server := range []string{"https://tfs.corp", "http://localhost:8080", "https://dev.azure.com.attacker.example"} {
+		_, err := store.Token(Account{Organization: "myorg", Server: server})
+		assert.ErrorIs(t, err, ErrNoCredentials, server)
+	}
+
+	onPremises := Account{Organization: "myorg", Server: "https://tfs.corp"}
+	require.NoError(t, StoreToken(store, onPremises, "tfs-corp-token-value-123"))
+	token, err := store.Token(onPremises)
+	require.NoError(t, err)
+	assert.Equal(t, "tfs-corp-token-value-123", token)
+	token, err = store.Token(services)
+	require.NoError(t, err)
+	assert.Equal(t, "dev-azure-com-token-value", token)
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/zalando/go-keyring"
+)
+
+// CredentialStore persists tokens per account. Every store is also a Source, so it
+// can take part in a credential chain.
+type CredentialStore interface {
+	Source
+	// Store saves the token for account, replacing any existing one
+	Store(account Account, token string) error
+	// Delete removes the token for account, or returns an error wrapping
+	// ErrNoCredentials if there is none
+	Delete(account Account) error
+}
+
+// KeyringStore keeps tokens in the system keychain (macOS Keychain, Windows
+// Credential Manager or the Linux Secret Service)
+type KeyringStore struct{}
+
+// Name implements Source
+func (KeyringStore) Name() string {
+	return "system keychain"
+}
+
+// Token implements Source
+func (KeyringStore) Token(account Account) (string, error) {
+	token, err := keyring.Get(serviceName, account.username())
+	if err != nil {
+		if err == keyring.ErrNotFound {
+			return "", ErrNoCredentials
+		}
+		return "", fmt.Errorf("failed to retrieve token from keychain: %w", err)
+	}
+	return token, nil
+}
+
+// Store implements CredentialStore
+func (KeyringStore) Store(account Account, token string) error {
+	if err := keyring.Set(serviceName, account.username(), token); err != nil {
+		return fmt.Errorf("failed to store token in keychain: %w", err)
+	}
+	return nil
+}
+
+// Delete implements CredentialStore
+func (KeyringStore) Delete(account Account) error {
+	if err := keyring.Delete(serviceName, account.username()); err != nil {
+		if err == keyring.ErrNotFound {
+			return ErrNoCredentials
+		}
+		return fmt.Errorf("failed to delete token from keychain: %w", err)
+	}
+	return nil
+}
+
+// MemoryStore keeps tokens in memory for the lifetime of the process. It is meant for tests.
+type MemoryStore struct {
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tokens: map[string]string{}}
+}
+
+// Name implements Source
+func (s *MemoryStore) Name() string {
+	return "in-memory store"
+}
+
+// Token implements Source
+func (s *MemoryStore) Token(account Account) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[account.username()]
+	if !ok {
+		return "", ErrNoCredentials
+	}
+	return token, nil
+}
+
+// Store implements CredentialStore
+func (s *MemoryStore) Store(account Account, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[account.username()] = token
+	return nil
+}
+
+// Delete implements CredentialStore
+func (s *MemoryStore) Delete(account Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tokens[account.username()]; !ok {
+		return ErrNoCredentials
+	}
+	delete(s.tokens, account.username())
+	return nil
+}
@@ -0,0 +1,42 @@
+package azdo
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+)
+
+// TokenSource supplies OAuth access tokens, e.g. from a Microsoft Entra ID sign-in
+type TokenSource interface {
+	// AccessToken returns a current access token
+	AccessToken(ctx context.Context) (string, error)
+	// Refresh obtains a new access token after the server rejected the current one
+	Refresh(ctx context.Context) error
+}
+
+// SetTokenSource switches the client from PAT authentication to bearer tokens from
+// tokens. A request rejected with 401 is retried once after refreshing the token.
+func (c *Client) SetTokenSource(tokens TokenSource) {
+	c.tokens = tokens
+}
+
+// authorize sets the Authorization header of req: a bearer token when the client has
+// a token source, and otherwise Basic authentication with the PAT
+func (c *Client) authorize(ctx context.Context, req *http.Request) error {
+	if c.tokens != nil {
+		token, err := c.tokens.AccessToken(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get access token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+
+	// Format: "{username}:{PAT}" base64 encoded
+	// Username is empty for Azure DevOps PAT authentication
+	authString := fmt.Sprintf(":%s", c.token)
+	authEncoded := base64.StdEncoding.EncodeToString([]byte(authString))
+	req.Header.Set("Authorization", fmt.Sprintf("Basic %s", authEncoded))
+	return nil
+}
@@ -0,0 +1,85 @@
+package azdo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTokens is a TokenSource that switches to "fresh-token" when refreshed
+type fakeTokens struct {
+	current   string
+	refreshes int
+}
+
+func (f *fakeTokens) AccessToken(ctx context.Context) (string, error) {
+	return f.current, nil
+}
+
+func (f *fakeTokens) Refresh(ctx context.Context) error {
+	f.refreshes++
+	f.current = "fresh-token"
+	return nil
+}
+
+func TestDoRequest_BearerRefreshesOnUnauthorized(t *testing.T) {
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "Bearer fresh-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c, slept := newTestClient(t, server.URL)
+	tokens := &fakeTokens{current: "expired-token"}
+	c.SetTokenSource(tokens)
+	c.SetRetryPolicy(RetryPolicy{MaxAttempts: 1})
+
+	_, err := c.doRequest(context.Background(), http.MethodPost, server.URL, map[string]string{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bearer expired-token", "Bearer fresh-token"}, seen)
+	assert.Equal(t, 1, tokens.refreshes)
+	assert.Empty(t, *slept)
+}
+
+func TestDoRequest_BearerRefreshesOnlyOnce(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+	tokens := &fakeTokens{current: "expired-token"}
+	c.SetTokenSource(tokens)
+
+	_, err := c.doRequest(context.Background(), http.MethodGet, server.URL, nil)
+	require.Error(t, err)
+	assert.True(t, IsUnauthorized(err))
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 1, tokens.refreshes)
+}
+
+func TestDoRequest_BasicAuthIsNotRefreshed(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, "Basic OnRva2Vu", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+	_, err := c.doRequest(context.Background(), http.MethodGet, server.URL, nil)
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
@@ -2,34 +2,49 @@ package azdo
 
 import (
 	"bytes"
-	"encoding/base64"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 	"time"
+
+	"github.com/chriskievit/dex-cli/internal/logging"
 )
 
 const (
 	apiVersion = "7.0"
-	baseURL    = "https://dev.azure.com"
+	// previewAPIVersion is used for resources that are only available as a preview
+	previewAPIVersion = "7.0-preview.1"
+
+	// DefaultServerURL is the base URL of Azure DevOps Services
+	DefaultServerURL = "https://dev.azure.com"
 )
 
 // Client represents an Azure DevOps API client
 type Client struct {
+	baseURL      string
 	organization string
 	token        string
+	tokens       TokenSource
 	httpClient   *http.Client
-	debug        bool
+	logger       *slog.Logger
+	retry        RetryPolicy
+	sleep        func(context.Context, time.Duration) error
 }
 
 // NewClient creates a new Azure DevOps API client
-func NewClient(organization, token string, debug bool) *Client {
+// serverURL is the base URL under which organizations (or, for Azure DevOps Server,
+// collections) live. An empty serverURL defaults to Azure DevOps Services.
+// A nil logger discards all diagnostic output.
+func NewClient(serverURL, organization, token string, logger *slog.Logger) *Client {
+	server := NormalizeServerURL(serverURL)
+
 	// Normalize organization name - extract just the org name from URL if needed
-	org := normalizeOrganization(organization)
+	org := NormalizeOrganization(server, organization)
 
 	client := &http.Client{
 		Timeout: 30 * time.Second,
@@ -39,27 +54,57 @@ func NewClient(organization, token string, debug bool) *Client {
 	}
 
 	c := &Client{
+		baseURL:      server,
 		organization: org,
 		token:        token,
 		httpClient:   client,
-		debug:        debug,
+		logger:       logger,
+		retry:        DefaultRetryPolicy(),
+		sleep:        sleepContext,
+	}
+
+	if c.logger == nil {
+		c.logger = logging.Discard()
 	}
 
-	c.debugLog("[DEBUG] Organization (input): %q\n", organization)
-	c.debugLog("[DEBUG] Organization (normalized): %q\n", org)
+	c.logger.Debug("client created", "server", server, "organization_input", organization, "organization", org)
 
 	return c
 }
 
-// normalizeOrganization extracts the organization name from a full URL or returns it as-is
-func normalizeOrganization(org string) string {
+// NormalizeServerURL returns the server URL without trailing slashes,
+// falling back to DefaultServerURL when empty
+func NormalizeServerURL(serverURL string) string {
+	serverURL = strings.TrimSpace(serverURL)
+	if serverURL == "" {
+		return DefaultServerURL
+	}
+	if !strings.Contains(serverURL, "://") {
+		serverURL = "https://" + serverURL
+	}
+	return strings.TrimRight(serverURL, "/")
+}
+
+// NormalizeOrganization extracts the organization (or collection) name from a full URL
+// or returns it as-is. URLs below serverURL and dev.azure.com URLs are both understood.
+func NormalizeOrganization(serverURL, org string) string {
 	// Remove any protocol prefix
-	org = strings.TrimPrefix(org, "https://")
-	org = strings.TrimPrefix(org, "http://")
+	org = stripScheme(org)
 
 	// Remove trailing slashes
 	org = strings.TrimSuffix(org, "/")
 
+	// If it's a URL below the configured server, strip the server part
+	server := stripScheme(NormalizeServerURL(serverURL)) + "/"
+	if len(org) > len(server) && strings.EqualFold(org[:len(server)], server) {
+		org = org[len(server):]
+		// Remove any path after the org name
+		if idx := strings.Index(org, "/"); idx != -1 {
+			org = org[:idx]
+		}
+		return org
+	}
+
 	// If it's a full URL, extract the org name
 	if strings.Contains(org, "dev.azure.com/") {
 		parts := strings.Split(org, "dev.azure.com/")
@@ -75,78 +120,182 @@ func normalizeOrganization(org string) string {
 	return org
 }
 
-// debugLog conditionally prints debug messages if debug is enabled
-func (c *Client) debugLog(format string, args ...interface{}) {
-	if c.debug {
-		fmt.Fprintf(os.Stderr, format, args...)
-	}
+// stripScheme removes an http(s) protocol prefix
+func stripScheme(s string) string {
+	s = strings.TrimPrefix(s, "https://")
+	s = strings.TrimPrefix(s, "http://")
+	return s
 }
 
-// doRequest performs an HTTP request with authentication
-func (c *Client) doRequest(method, url string, body interface{}) ([]byte, error) {
-	c.debugLog("[DEBUG] HTTP Request: %s %s\n", method, url)
-
-	var reqBody io.Reader
+// doRequest performs an HTTP request with authentication, retrying transient
+// failures according to the client's retry policy. Cancelling ctx aborts the
+// request in flight as well as any wait between attempts.
+func (c *Client) doRequest(ctx context.Context, method, url string, body interface{}) ([]byte, error) {
+	var jsonData []byte
 	if body != nil {
-		jsonData, err := json.Marshal(body)
+		var err error
+		jsonData, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		reqBody = bytes.NewBuffer(jsonData)
 	}
+	contentType := "application/json"
+	if _, ok := body.(jsonPatch); ok {
+		contentType = jsonPatchContentType
+	}
+
+	refreshed := false
+	for attempt := 1; ; attempt++ {
+		status, header, respBody, err := c.doAttempt(ctx, method, url, contentType, jsonData)
+		if err != nil {
+			if ctx.Err() != nil || !isTransientError(err) || !c.retry.canRetry(method, attempt) {
+				return nil, fmt.Errorf("request failed: %w", err)
+			}
+			delay := c.retry.backoff(attempt)
+			c.logger.Info("retrying request", "method", method, "url", logging.RedactURL(url),
+				"attempt", attempt, "max_attempts", c.retry.MaxAttempts, "error", err, "delay", delay)
+			if err := c.sleep(ctx, delay); err != nil {
+				return nil, fmt.Errorf("request failed: %w", err)
+			}
+			continue
+		}
+
+		if status == http.StatusTooManyRequests || header.Get("X-RateLimit-Delay") != "" {
+			c.logRateLimit(status, header)
+		}
 
-	req, err := http.NewRequest(method, url, reqBody)
+		// Azure DevOps answers requests with invalid credentials with a 203 and an HTML sign-in page
+		if status == http.StatusNonAuthoritativeInfo && isHTML(header) {
+			status = http.StatusUnauthorized
+		}
+
+		// Accept 2xx status codes (including 203 Non-Authoritative Information)
+		if status >= 200 && status < 300 {
+			return respBody, nil
+		}
+
+		c.logger.Debug("request failed", "method", method, "url", logging.RedactURL(url), "status", status)
+		apiErr := newAPIError(method, url, status, header, respBody)
+
+		// An expired or revoked access token is refreshed once; the rejected request
+		// does not count as an attempt
+		if status == http.StatusUnauthorized && c.tokens != nil && !refreshed {
+			refreshed = true
+			c.logger.Info("access token rejected; refreshing", "method", method, "url", logging.RedactURL(url))
+			if err := c.tokens.Refresh(ctx); err != nil {
+				return nil, fmt.Errorf("failed to refresh access token: %w", err)
+			}
+			attempt--
+			continue
+		}
+
+		if !isRetryableStatus(status) || !c.retry.canRetry(method, attempt) {
+			return nil, apiErr
+		}
+
+		delay, fromServer := retryAfter(header, time.Now())
+		if !fromServer {
+			delay = c.retry.backoff(attempt)
+		} else if c.retry.MaxDelay > 0 && delay > c.retry.MaxDelay {
+			c.logger.Info("retry-after exceeds maximum delay; giving up",
+				"method", method, "url", logging.RedactURL(url), "retry_after", delay, "max_delay", c.retry.MaxDelay)
+			return nil, apiErr
+		}
+
+		c.logger.Info("retrying request", "method", method, "url", logging.RedactURL(url),
+			"attempt", attempt, "max_attempts", c.retry.MaxAttempts, "status", status, "delay", delay)
+		if err := c.sleep(ctx, delay); err != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+	}
+}
+
+// doAttempt sends a single HTTP request and returns the response status, headers and body.
+// Every attempt is recorded at info level with its latency and Azure DevOps activity ID.
+func (c *Client) doAttempt(ctx context.Context, method, url, contentType string, jsonData []byte) (int, http.Header, []byte, error) {
+	var reqBody io.Reader
+	if jsonData != nil {
+		reqBody = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return 0, nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Set authentication header (Basic Auth with PAT)
-	// Format: "{username}:{PAT}" base64 encoded
-	// Username is empty for Azure DevOps PAT authentication
-	authString := fmt.Sprintf(":%s", c.token)
-	authEncoded := base64.StdEncoding.EncodeToString([]byte(authString))
-	req.Header.Set("Authorization", fmt.Sprintf("Basic %s", authEncoded))
-	req.Header.Set("Content-Type", "application/json")
+	if err := c.authorize(ctx, req); err != nil {
+		return 0, nil, nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("Accept", "application/json")
 
-	c.debugLog("[DEBUG] Authorization header set (Basic Auth with empty username): %s\n", authEncoded)
+	redactedURL := logging.RedactURL(url)
+	c.logger.Debug("http request", "method", method, "url", redactedURL, "headers", logging.RedactHeaders(req.Header))
 
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		c.logger.Info("api request", "method", method, "url", redactedURL,
+			"latency_ms", time.Since(start).Milliseconds(), "error", err)
+		return 0, nil, nil, err
 	}
 	defer resp.Body.Close()
 
-	c.debugLog("[DEBUG] HTTP Response Status: %d %s\n", resp.StatusCode, resp.Status)
-	c.debugLog("[DEBUG] Response Headers: %v\n", resp.Header)
-
 	respBody, err := io.ReadAll(resp.Body)
+	latency := time.Since(start)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return 0, nil, nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	c.debugLog("[DEBUG] Response Body (first 500 chars): %s\n", truncateString(string(respBody), 500))
-	if len(respBody) > 500 {
-		c.debugLog("[DEBUG] Response Body length: %d bytes\n", len(respBody))
-	}
+	c.logger.Info("api request", "method", method, "url", redactedURL, "status", resp.StatusCode,
+		"latency_ms", latency.Milliseconds(), "activity_id", resp.Header.Get("ActivityId"))
+	c.logger.Debug("http response", "status", resp.StatusCode, "headers", logging.RedactHeaders(resp.Header),
+		"body_length", len(respBody), "body", truncateString(string(respBody), 500))
 
-	// Accept 2xx status codes (including 203 Non-Authoritative Information)
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		c.debugLog("[DEBUG] Full Response Body: %s\n", string(respBody))
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
-	}
-
-	return respBody, nil
+	return resp.StatusCode, resp.Header, respBody, nil
 }
 
-// buildURL constructs the full API URL with proper path encoding
+// buildURL constructs the full API URL with proper path encoding.
+// path may include a query string, which is kept ahead of the api-version.
 func (c *Client) buildURL(project, path string) string {
+	return c.buildVersionedURL(project, path, apiVersion)
+}
+
+// buildVersionedURL is buildURL for resources that require a different api-version,
+// such as preview APIs
+func (c *Client) buildVersionedURL(project, path, version string) string {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+
 	orgEncoded := url.PathEscape(c.organization)
 	if project != "" {
 		projectEncoded := url.PathEscape(project)
-		return fmt.Sprintf("%s/%s/%s/_apis/%s?api-version=%s", baseURL, orgEncoded, projectEncoded, path, apiVersion)
+		return fmt.Sprintf("%s/%s/%s/_apis/%s%sapi-version=%s", c.baseURL, orgEncoded, projectEncoded, path, sep, version)
 	}
-	return fmt.Sprintf("%s/%s/_apis/%s?api-version=%s", baseURL, orgEncoded, path, apiVersion)
+	return fmt.Sprintf("%s/%s/_apis/%s%sapi-version=%s", c.baseURL, orgEncoded, path, sep, version)
+}
+
+// ServerURL returns the base URL the client sends requests to
+func (c *Client) ServerURL() string {
+	return c.baseURL
+}
+
+// Organization returns the normalized organization (or collection) name
+func (c *Client) Organization() string {
+	return c.organization
+}
+
+// PullRequestWebURL returns the browser URL of a pull request
+func (c *Client) PullRequestWebURL(project, repoName string, id int) string {
+	return fmt.Sprintf("%s/%s/%s/_git/%s/pullrequest/%d", c.baseURL,
+		url.PathEscape(c.organization), url.PathEscape(project), url.PathEscape(repoName), id)
+}
+
+// WorkItemWebURL returns the browser URL of a work item
+func (c *Client) WorkItemWebURL(id int) string {
+	return fmt.Sprintf("%s/%s/_workitems/edit/%d", c.baseURL, url.PathEscape(c.organization), id)
 }
 
 // truncateString truncates a string to a maximum length
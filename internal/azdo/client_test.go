@@ -0,0 +1,217 @@
+package azdo
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chriskievit/dex-cli/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeServerURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "empty defaults to Azure DevOps Services",
+			input:    "",
+			expected: DefaultServerURL,
+		},
+		{
+			name:     "trailing slash removed",
+			input:    "https://tfs.corp/",
+			expected: "https://tfs.corp",
+		},
+		{
+			name:     "missing scheme defaults to https",
+			input:    "tfs.corp",
+			expected: "https://tfs.corp",
+		},
+		{
+			name:     "http scheme preserved",
+			input:    "http://127.0.0.1:8080",
+			expected: "http://127.0.0.1:8080",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NormalizeServerURL(tt.input))
+		})
+	}
+}
+
+func TestNormalizeOrganization(t *testing.T) {
+	tests := []struct {
+		name     string
+		server   string
+		org      string
+		expected string
+	}{
+		{
+			name:     "plain organization name",
+			org:      "myorg",
+			expected: "myorg",
+		},
+		{
+			name:     "dev.azure.com URL",
+			org:      "https://dev.azure.com/myorg/",
+			expected: "myorg",
+		},
+		{
+			name:     "dev.azure.com URL with project path",
+			org:      "https://dev.azure.com/myorg/myproject",
+			expected: "myorg",
+		},
+		{
+			name:     "collection URL below custom server",
+			server:   "https://tfs.corp",
+			org:      "https://tfs.corp/DefaultCollection",
+			expected: "DefaultCollection",
+		},
+		{
+			name:     "collection URL with project path below custom server",
+			server:   "https://tfs.corp/",
+			org:      "https://TFS.corp/DefaultCollection/proj",
+			expected: "DefaultCollection",
+		},
+		{
+			name:     "collection name with custom server",
+			server:   "https://tfs.corp",
+			org:      "DefaultCollection",
+			expected: "DefaultCollection",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NormalizeOrganization(tt.server, tt.org))
+		})
+	}
+}
+
+func TestBuildURL(t *testing.T) {
+	c := NewClient("https://tfs.corp/", "Default Collection", "token", nil)
+
+	assert.Equal(t,
+		"https://tfs.corp/Default%20Collection/_apis/wit/workitems/1?api-version="+apiVersion,
+		c.buildURL("", "wit/workitems/1"))
+	assert.Equal(t,
+		"https://tfs.corp/Default%20Collection/my%20project/_apis/git/repositories?api-version="+apiVersion,
+		c.buildURL("my project", "git/repositories"))
+	assert.Equal(t,
+		"https://tfs.corp/Default%20Collection/my%20project/_apis/git/pullrequests?$top=1&api-version="+apiVersion,
+		c.buildURL("my project", "git/pullrequests?$top=1"))
+}
+
+func TestBuildURL_DefaultServer(t *testing.T) {
+	c := NewClient("", "https://dev.azure.com/myorg", "token", nil)
+
+	assert.Equal(t, DefaultServerURL, c.ServerURL())
+	assert.Equal(t, "myorg", c.Organization())
+	assert.Equal(t, "https://dev.azure.com/myorg/_apis/wit/workitems/1?api-version="+apiVersion,
+		c.buildURL("", "wit/workitems/1"))
+}
+
+func TestWebURLs(t *testing.T) {
+	c := NewClient("https://tfs.corp", "DefaultCollection", "token", nil)
+
+	assert.Equal(t, "https://tfs.corp/DefaultCollection/proj/_git/repo/pullrequest/42",
+		c.PullRequestWebURL("proj", "repo", 42))
+	assert.Equal(t, "https://tfs.corp/DefaultCollection/_workitems/edit/7", c.WorkItemWebURL(7))
+}
+
+func TestGetWorkItem_CustomServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/DefaultCollection/_apis/wit/workitems/123", r.URL.Path)
+		assert.Equal(t, apiVersion, r.URL.Query().Get("api-version"))
+
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "", user)
+		assert.Equal(t, "secret-token", pass)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":123,"fields":{"System.Title":"Add login","System.WorkItemType":"User Story"}}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, "DefaultCollection", "secret-token", nil)
+
+	workItem, err := c.GetWorkItem(context.Background(), 123)
+	require.NoError(t, err)
+	assert.Equal(t, 123, workItem.ID)
+	assert.Equal(t, "Add login", workItem.GetTitle())
+	assert.Equal(t, "user-story", workItem.GetWorkItemType())
+}
+
+func TestDoRequest_TraceRedactsCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ActivityId", "activity-42")
+		w.Header().Set("Set-Cookie", "session=cookie-value")
+		w.Write([]byte(`{"id":1,"fields":{}}`))
+	}))
+	defer server.Close()
+
+	var console, trace bytes.Buffer
+	c := NewClient(server.URL, "org", "super-secret-token", logging.New(&console, slog.LevelDebug, &trace))
+
+	_, err := c.GetWorkItem(context.Background(), 1)
+	require.NoError(t, err)
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(":super-secret-token"))
+	for _, output := range []string{console.String(), trace.String()} {
+		assert.NotContains(t, output, "super-secret-token")
+		assert.NotContains(t, output, encoded)
+		assert.NotContains(t, output, "cookie-value")
+	}
+
+	// The trace has a machine-readable summary of the request
+	var summary map[string]any
+	for _, line := range bytes.Split(bytes.TrimSpace(trace.Bytes()), []byte("\n")) {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(line, &record))
+		if record["msg"] == "api request" {
+			summary = record
+		}
+	}
+	require.NotNil(t, summary)
+	assert.Equal(t, "GET", summary["method"])
+	assert.Equal(t, float64(200), summary["status"])
+	assert.Equal(t, "activity-42", summary["activity_id"])
+	assert.Contains(t, summary, "latency_ms")
+}
+
+func TestDoRequest_LogsTruncatedBodies(t *testing.T) {
+	body := "<html>" + strings.Repeat("x", 2000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/workitems/") {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	var console, trace bytes.Buffer
+	c := NewClient(server.URL, "org", "token", logging.New(&console, slog.LevelDebug, &trace))
+
+	// Neither failed requests nor unparseable responses are logged in full
+	_, err := c.GetWorkItem(context.Background(), 1)
+	require.Error(t, err)
+	_, err = c.GetConnectionData(context.Background())
+	require.Error(t, err)
+
+	for _, output := range []string{console.String(), trace.String()} {
+		assert.NotContains(t, output, strings.Repeat("x", 501))
+	}
+}
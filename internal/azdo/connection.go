@@ -0,0 +1,117 @@
+package azdo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// anonymousDescriptorPrefix identifies the identity Azure DevOps reports for requests
+// it did not authenticate
+const anonymousDescriptorPrefix = "System:PublicAccess"
+
+// Identity represents an Azure DevOps identity
+type Identity struct {
+	ID                  string                      `json:"id"`
+	Descriptor          string                      `json:"descriptor"`
+	SubjectDescriptor   string                      `json:"subjectDescriptor"`
+	ProviderDisplayName string                      `json:"providerDisplayName"`
+	CustomDisplayName   string                      `json:"customDisplayName"`
+	IsActive            bool                        `json:"isActive"`
+	IsContainer         bool                        `json:"isContainer"`
+	Properties          map[string]IdentityProperty `json:"properties"`
+}
+
+// IdentityProperty is a typed value in an identity's property bag
+type IdentityProperty struct {
+	Type  string      `json:"$type"`
+	Value interface{} `json:"$value"`
+}
+
+// DisplayName returns the name shown for the identity
+func (i *Identity) DisplayName() string {
+	if i.CustomDisplayName != "" {
+		return i.CustomDisplayName
+	}
+	return i.ProviderDisplayName
+}
+
+// Account returns the sign-in name (usually the email address) of the identity
+func (i *Identity) Account() string {
+	if value, ok := i.Properties["Account"].Value.(string); ok {
+		return value
+	}
+	return ""
+}
+
+// ConnectionData describes the connection made with the client's credentials
+type ConnectionData struct {
+	AuthenticatedUser Identity `json:"authenticatedUser"`
+	AuthorizedUser    Identity `json:"authorizedUser"`
+	InstanceID        string   `json:"instanceId"`
+}
+
+// IsAnonymous reports whether the server did not authenticate the request
+func (d *ConnectionData) IsAnonymous() bool {
+	return d.AuthenticatedUser.ID == "" ||
+		strings.HasPrefix(d.AuthenticatedUser.Descriptor, anonymousDescriptorPrefix)
+}
+
+// GetConnectionData returns the identity the client's token authenticates as. It works
+// with any valid token regardless of its scopes, so it is used to validate tokens.
+func (c *Client) GetConnectionData(ctx context.Context) (*ConnectionData, error) {
+	// connectionData is a preview resource, so it is requested without an api-version
+	apiURL := fmt.Sprintf("%s/%s/_apis/connectionData", c.baseURL, url.PathEscape(c.organization))
+
+	respBody, err := c.doRequest(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection data: %w", err)
+	}
+
+	var data ConnectionData
+	if err := json.Unmarshal(respBody, &data); err != nil {
+		c.logger.Debug("failed to parse connection data response", "error", err, "body", truncateString(string(respBody), 500))
+		return nil, fmt.Errorf("failed to parse connection data response: %w", err)
+	}
+
+	if data.IsAnonymous() {
+		return nil, fmt.Errorf("the token was not accepted by %s", c.baseURL)
+	}
+
+	return &data, nil
+}
+
+// Capability is an area of the API that a token's scopes may or may not grant access to
+type Capability struct {
+	Name string
+	// NeedsProject reports whether checking the capability requires a project
+	NeedsProject bool
+	// path is a cheap read-only request that succeeds only with access to the capability
+	path string
+}
+
+// Capabilities are the areas of the API used by dex
+var Capabilities = []Capability{
+	{Name: "Work items", NeedsProject: true, path: "wit/queries?$depth=0"},
+	{Name: "Code", NeedsProject: false, path: "git/repositories"},
+	{Name: "Pull requests", NeedsProject: true, path: "git/pullrequests?$top=1"},
+	{Name: "Build", NeedsProject: true, path: "build/builds?$top=1"},
+}
+
+// CheckCapability performs the capability's read-only request in project. A nil error
+// means the token has access; a 401 or 403 APIError means it does not.
+func (c *Client) CheckCapability(ctx context.Context, project string, capability Capability) error {
+	if capability.NeedsProject && project == "" {
+		return fmt.Errorf("checking %s requires a project", strings.ToLower(capability.Name))
+	}
+	if !capability.NeedsProject {
+		project = ""
+	}
+
+	if _, err := c.doRequest(ctx, "GET", c.buildURL(project, capability.path), nil); err != nil {
+		return err
+	}
+	return nil
+}
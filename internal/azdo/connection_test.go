@@ -0,0 +1,111 @@
+package azdo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetConnectionData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/org/_apis/connectionData", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"authenticatedUser": {
+				"id": "8c8c7d32-6b1b-47f4-b2e9-30b477b5ab3d",
+				"descriptor": "Microsoft.IdentityModel.Claims.ClaimsIdentity;jane@contoso.com",
+				"providerDisplayName": "Jane Doe",
+				"isActive": true,
+				"properties": {"Account": {"$type": "System.String", "$value": "jane@contoso.com"}}
+			},
+			"instanceId": "b0e5f8b4-0c7e-4d8e-9d1b-1a2b3c4d5e6f"
+		}`))
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+
+	data, err := c.GetConnectionData(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "8c8c7d32-6b1b-47f4-b2e9-30b477b5ab3d", data.AuthenticatedUser.ID)
+	assert.Equal(t, "Jane Doe", data.AuthenticatedUser.DisplayName())
+	assert.Equal(t, "jane@contoso.com", data.AuthenticatedUser.Account())
+}
+
+func TestGetConnectionData_Anonymous(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"authenticatedUser": {"id": "aa442b3b-0000-0000-0000-000000000000", "descriptor": "System:PublicAccess;aa442b3b"}}`))
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+
+	_, err := c.GetConnectionData(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not accepted")
+}
+
+func TestGetConnectionData_InvalidToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Azure DevOps answers invalid credentials with a sign-in page
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusNonAuthoritativeInfo)
+		w.Write([]byte("<html>Sign in</html>"))
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+
+	_, err := c.GetConnectionData(context.Background())
+	require.Error(t, err)
+	assert.True(t, IsUnauthorized(err))
+}
+
+func TestCheckCapability(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/org/_apis/git/repositories":
+			w.Write([]byte(`{"value": [], "count": 0}`))
+		case "/org/proj/_apis/build/builds":
+			assert.Equal(t, "1", r.URL.Query().Get("$top"))
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message": "Access denied", "typeKey": "UnauthorizedRequestException"}`))
+		default:
+			t.Errorf("unexpected request: %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+	ctx := context.Background()
+
+	capability := func(name string) Capability {
+		for _, candidate := range Capabilities {
+			if candidate.Name == name {
+				return candidate
+			}
+		}
+		t.Fatalf("unknown capability %s", name)
+		return Capability{}
+	}
+
+	// Organization-wide capabilities ignore the project
+	assert.NoError(t, c.CheckCapability(ctx, "proj", capability("Code")))
+
+	err := c.CheckCapability(ctx, "proj", capability("Build"))
+	require.Error(t, err)
+	assert.True(t, IsForbidden(err))
+	apiErr, ok := AsAPIError(err)
+	require.True(t, ok)
+	assert.Equal(t, "Build (Read)", apiErr.RequiredScope())
+
+	err = c.CheckCapability(ctx, "", capability("Pull requests"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires a project")
+}
@@ -0,0 +1,148 @@
+package azdo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// APIError describes a failed Azure DevOps API request
+type APIError struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+	// Method and URL identify the request that failed
+	Method string
+	URL    string
+	// TypeKey is the Azure DevOps exception type, e.g. "WorkItemUnauthorizedAccessException"
+	TypeKey string
+	// Message is the human readable message returned by Azure DevOps
+	Message string
+	// ActivityID identifies the request in Azure DevOps diagnostics
+	ActivityID string
+	// Body is the raw response body when it could not be parsed
+	Body string
+}
+
+// errorPayload is the JSON error body returned by Azure DevOps
+type errorPayload struct {
+	TypeKey  string `json:"typeKey"`
+	TypeName string `json:"typeName"`
+	Message  string `json:"message"`
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s (HTTP %d)", e.Message, e.StatusCode)
+	}
+	if e.Body != "" {
+		return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, truncateString(e.Body, 500))
+	}
+	return fmt.Sprintf("API request failed with status %d", e.StatusCode)
+}
+
+// newAPIError builds an APIError from a failed response
+func newAPIError(method, requestURL string, status int, header http.Header, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: status,
+		Method:     method,
+		URL:        requestURL,
+		ActivityID: header.Get("ActivityId"),
+	}
+
+	var payload errorPayload
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Message != "" {
+		apiErr.TypeKey = payload.TypeKey
+		if apiErr.TypeKey == "" {
+			apiErr.TypeKey = payload.TypeName
+		}
+		apiErr.Message = payload.Message
+		return apiErr
+	}
+
+	// HTML bodies (sign-in pages, proxy errors) are not useful in error messages
+	if !isHTML(header) {
+		apiErr.Body = strings.TrimSpace(string(body))
+	}
+
+	return apiErr
+}
+
+// isHTML reports whether a response carries an HTML body
+func isHTML(header http.Header) bool {
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	return mediaType == "text/html"
+}
+
+// RequiredScope returns the Personal Access Token scope needed for the failed request,
+// or an empty string if it cannot be determined
+func (e *APIError) RequiredScope() string {
+	path := e.URL
+	if parsed, err := url.Parse(e.URL); err == nil {
+		path = parsed.Path
+	}
+	path = strings.ToLower(path)
+
+	access := "Read"
+	if e.Method != "" && e.Method != http.MethodGet && e.Method != http.MethodHead {
+		access = "Read & Write"
+	}
+
+	switch {
+	case strings.Contains(path, "/_apis/wit/"):
+		return "Work Items (" + access + ")"
+	case strings.Contains(path, "/_apis/git/"):
+		return "Code (" + access + ")"
+	case strings.Contains(path, "/_apis/build/"):
+		return "Build (" + access + ")"
+	case strings.Contains(path, "/_apis/policy/"):
+		return "Code (Read)"
+	case strings.Contains(path, "/_apis/identities"):
+		return "Identity (Read)"
+	}
+	return ""
+}
+
+// AsAPIError returns the APIError in err's chain, if any
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// hasStatus reports whether err wraps an APIError with the given status code
+func hasStatus(err error, status int) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && apiErr.StatusCode == status
+}
+
+// IsNotFound reports whether err is a 404 Not Found API error
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsUnauthorized reports whether err is a 401 Unauthorized API error (invalid or expired credentials)
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether err is a 403 Forbidden API error (missing permission or token scope)
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsConflict reports whether err is a 409 Conflict API error
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsThrottled reports whether err is a 429 Too Many Requests API error
+func IsThrottled(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
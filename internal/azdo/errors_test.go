@@ -0,0 +1,110 @@
+package azdo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAPIError_JSONPayload(t *testing.T) {
+	header := http.Header{}
+	header.Set("ActivityId", "1234-abcd")
+	body := []byte(`{"$id":"1","innerException":null,"message":"TF401232: Work item 999 does not exist.","typeName":"Microsoft.TeamFoundation.WorkItemTracking.Server.WorkItemUnauthorizedAccessException","typeKey":"WorkItemUnauthorizedAccessException","errorCode":0,"eventId":3200}`)
+
+	apiErr := newAPIError(http.MethodGet, "https://dev.azure.com/org/_apis/wit/workitems/999", http.StatusNotFound, header, body)
+
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "WorkItemUnauthorizedAccessException", apiErr.TypeKey)
+	assert.Equal(t, "TF401232: Work item 999 does not exist.", apiErr.Message)
+	assert.Equal(t, "1234-abcd", apiErr.ActivityID)
+	assert.Equal(t, "TF401232: Work item 999 does not exist. (HTTP 404)", apiErr.Error())
+}
+
+func TestNewAPIError_NonJSONBody(t *testing.T) {
+	apiErr := newAPIError(http.MethodGet, "https://example", http.StatusBadGateway, http.Header{}, []byte("upstream unavailable\n"))
+	assert.Equal(t, "API request failed with status 502: upstream unavailable", apiErr.Error())
+
+	header := http.Header{}
+	header.Set("Content-Type", "text/html; charset=utf-8")
+	apiErr = newAPIError(http.MethodGet, "https://example", http.StatusUnauthorized, header, []byte("<html>Sign in</html>"))
+	assert.Equal(t, "API request failed with status 401", apiErr.Error())
+}
+
+func TestAPIError_RequiredScope(t *testing.T) {
+	tests := []struct {
+		method   string
+		url      string
+		expected string
+	}{
+		{http.MethodGet, "https://dev.azure.com/org/_apis/wit/workitems/1", "Work Items (Read)"},
+		{http.MethodPatch, "https://dev.azure.com/org/_apis/wit/workitems/1", "Work Items (Read & Write)"},
+		{http.MethodGet, "https://dev.azure.com/org/proj/_apis/git/repositories/repo", "Code (Read)"},
+		{http.MethodPost, "https://dev.azure.com/org/proj/_apis/git/repositories/repo/pullrequests", "Code (Read & Write)"},
+		{http.MethodGet, "https://dev.azure.com/org/proj/_apis/build/builds", "Build (Read)"},
+		{http.MethodGet, "https://dev.azure.com/org/_apis/connectionData", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.url, func(t *testing.T) {
+			apiErr := &APIError{Method: tt.method, URL: tt.url}
+			assert.Equal(t, tt.expected, apiErr.RequiredScope())
+		})
+	}
+}
+
+func TestErrorHelpers(t *testing.T) {
+	wrapped := func(status int) error {
+		return fmt.Errorf("failed: %w", &APIError{StatusCode: status})
+	}
+
+	assert.True(t, IsNotFound(wrapped(http.StatusNotFound)))
+	assert.True(t, IsUnauthorized(wrapped(http.StatusUnauthorized)))
+	assert.True(t, IsForbidden(wrapped(http.StatusForbidden)))
+	assert.True(t, IsConflict(wrapped(http.StatusConflict)))
+	assert.True(t, IsThrottled(wrapped(http.StatusTooManyRequests)))
+
+	assert.False(t, IsNotFound(wrapped(http.StatusUnauthorized)))
+	assert.False(t, IsNotFound(fmt.Errorf("plain error")))
+	assert.False(t, IsNotFound(nil))
+}
+
+func TestDoRequest_ReturnsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ActivityId", "activity-1")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"TF401232: Work item 42 does not exist.","typeKey":"WorkItemUnauthorizedAccessException"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, "org", "token", nil)
+
+	_, err := c.GetWorkItem(context.Background(), 42)
+	require.Error(t, err)
+	assert.True(t, IsNotFound(err))
+
+	apiErr, ok := AsAPIError(err)
+	require.True(t, ok)
+	assert.Equal(t, "activity-1", apiErr.ActivityID)
+	assert.Equal(t, http.MethodGet, apiErr.Method)
+}
+
+func TestDoRequest_SignInPageIsUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusNonAuthoritativeInfo)
+		w.Write([]byte("<html>Sign In</html>"))
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, "org", "token", nil)
+
+	_, err := c.GetWorkItem(context.Background(), 42)
+	require.Error(t, err)
+	assert.True(t, IsUnauthorized(err))
+}
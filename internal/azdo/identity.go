@@ -0,0 +1,51 @@
+package azdo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// identityServiceURL is where Azure DevOps Services hosts the identities API. Azure
+// DevOps Server serves it from the collection like any other API.
+const identityServiceURL = "https://vssps.dev.azure.com"
+
+// Identity search filters
+const (
+	// IdentitySearchGeneral matches display names, account names (emails) and group names
+	IdentitySearchGeneral     = "General"
+	IdentitySearchMailAddress = "MailAddress"
+	IdentitySearchAccountName = "AccountName"
+)
+
+// SearchIdentities returns the users and groups that match value with the given search
+// filter, one of the IdentitySearch constants
+func (c *Client) SearchIdentities(ctx context.Context, filter, value string) ([]Identity, error) {
+	base := c.baseURL
+	if base == DefaultServerURL {
+		base = identityServiceURL
+	}
+
+	query := url.Values{}
+	query.Set("searchFilter", filter)
+	query.Set("filterValue", value)
+	query.Set("queryMembership", "None")
+	query.Set("api-version", apiVersion)
+	apiURL := fmt.Sprintf("%s/%s/_apis/identities?%s", base, url.PathEscape(c.organization), query.Encode())
+
+	respBody, err := c.doRequest(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search identities: %w", err)
+	}
+
+	var identities struct {
+		Value []Identity `json:"value"`
+	}
+	if err := json.Unmarshal(respBody, &identities); err != nil {
+		c.logger.Debug("failed to parse identities response", "error", err, "body", truncateString(string(respBody), 500))
+		return nil, fmt.Errorf("failed to parse identities response: %w", err)
+	}
+
+	return identities.Value, nil
+}
@@ -0,0 +1,28 @@
+package azdo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchIdentities(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/org/_apis/identities", r.URL.Path)
+		assert.Equal(t, "General", r.URL.Query().Get("searchFilter"))
+		assert.Equal(t, `[proj]\Web Team`, r.URL.Query().Get("filterValue"))
+		w.Write([]byte(`{"count": 1, "value": [{"id": "t1", "providerDisplayName": "[proj]\\Web Team", "isContainer": true}]}`))
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+	identities, err := c.SearchIdentities(context.Background(), IdentitySearchGeneral, `[proj]\Web Team`)
+	require.NoError(t, err)
+	require.Len(t, identities, 1)
+	assert.Equal(t, "t1", identities[0].ID)
+	assert.True(t, identities[0].IsContainer)
+}
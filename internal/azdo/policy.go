@@ -0,0 +1,83 @@
+package azdo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// Policy evaluation statuses
+const (
+	PolicyStatusQueued        = "queued"
+	PolicyStatusRunning       = "running"
+	PolicyStatusApproved      = "approved"
+	PolicyStatusRejected      = "rejected"
+	PolicyStatusNotApplicable = "notApplicable"
+	PolicyStatusBroken        = "broken"
+)
+
+// PolicyEvaluation is the result of a branch policy for a pull request
+type PolicyEvaluation struct {
+	EvaluationID  string              `json:"evaluationId"`
+	Status        string              `json:"status"`
+	Configuration PolicyConfiguration `json:"configuration"`
+}
+
+// PolicyConfiguration is a branch policy
+type PolicyConfiguration struct {
+	ID         int                    `json:"id"`
+	IsEnabled  bool                   `json:"isEnabled"`
+	IsBlocking bool                   `json:"isBlocking"`
+	Type       PolicyType             `json:"type"`
+	Settings   map[string]interface{} `json:"settings"`
+}
+
+// PolicyType identifies the kind of a branch policy, e.g. "Minimum number of reviewers"
+type PolicyType struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+}
+
+// Name returns the policy's own display name when it has one (build policies and
+// status checks do), and otherwise the name of its type
+func (e *PolicyEvaluation) Name() string {
+	if name, ok := e.Configuration.Settings["displayName"].(string); ok && name != "" {
+		return name
+	}
+	return e.Configuration.Type.DisplayName
+}
+
+// IsBlockingFailure reports whether the evaluation prevents the pull request from completing
+func (e *PolicyEvaluation) IsBlockingFailure() bool {
+	if !e.Configuration.IsBlocking {
+		return false
+	}
+	switch e.Status {
+	case PolicyStatusApproved, PolicyStatusNotApplicable:
+		return false
+	}
+	return true
+}
+
+// GetPolicyEvaluations returns the branch policy results of a pull request. projectID
+// must be the project's ID, not its name, as it is part of the pull request's artifact ID.
+func (c *Client) GetPolicyEvaluations(ctx context.Context, projectID string, pullRequestID int) ([]PolicyEvaluation, error) {
+	artifactID := fmt.Sprintf("vstfs:///CodeReview/CodeReviewId/%s/%d", projectID, pullRequestID)
+	apiURL := c.buildVersionedURL(projectID, "policy/evaluations?artifactId="+url.QueryEscape(artifactID), previewAPIVersion)
+
+	respBody, err := c.doRequest(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policy evaluations: %w", err)
+	}
+
+	var evaluations struct {
+		Value []PolicyEvaluation `json:"value"`
+	}
+	if err := json.Unmarshal(respBody, &evaluations); err != nil {
+		c.logger.Debug("failed to parse policy evaluations response", "error", err, "body", truncateString(string(respBody), 500))
+		return nil, fmt.Errorf("failed to parse policy evaluations response: %w", err)
+	}
+
+	return evaluations.Value, nil
+}
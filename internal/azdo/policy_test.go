@@ -0,0 +1,40 @@
+package azdo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPolicyEvaluations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/org/p-1/_apis/policy/evaluations", r.URL.Path)
+		assert.Equal(t, "vstfs:///CodeReview/CodeReviewId/p-1/42", r.URL.Query().Get("artifactId"))
+		assert.Equal(t, previewAPIVersion, r.URL.Query().Get("api-version"))
+		w.Write([]byte(`{"value": [
+			{"evaluationId": "e1", "status": "approved",
+			 "configuration": {"isEnabled": true, "isBlocking": true, "type": {"displayName": "Minimum number of reviewers"}}},
+			{"evaluationId": "e2", "status": "rejected",
+			 "configuration": {"isEnabled": true, "isBlocking": true, "type": {"displayName": "Build"},
+			                   "settings": {"displayName": "CI build"}}},
+			{"evaluationId": "e3", "status": "queued",
+			 "configuration": {"isEnabled": true, "isBlocking": false, "type": {"displayName": "Status check"}}}
+		]}`))
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+	evaluations, err := c.GetPolicyEvaluations(context.Background(), "p-1", 42)
+	require.NoError(t, err)
+	require.Len(t, evaluations, 3)
+
+	assert.Equal(t, "Minimum number of reviewers", evaluations[0].Name())
+	assert.False(t, evaluations[0].IsBlockingFailure())
+	assert.Equal(t, "CI build", evaluations[1].Name())
+	assert.True(t, evaluations[1].IsBlockingFailure())
+	assert.False(t, evaluations[2].IsBlockingFailure())
+}
@@ -1,25 +1,197 @@
 package azdo
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"strings"
+	"time"
+)
+
+// pullRequestPageSize is the number of pull requests requested per page
+const pullRequestPageSize = 100
+
+// Pull request statuses accepted by PullRequestSearchCriteria
+const (
+	PullRequestStatusActive    = "active"
+	PullRequestStatusCompleted = "completed"
+	PullRequestStatusAbandoned = "abandoned"
+	PullRequestStatusAll       = "all"
+)
+
+// Merge strategies for completing a pull request
+const (
+	MergeStrategyNoFastForward = "noFastForward"
+	MergeStrategySquash        = "squash"
+	MergeStrategyRebase        = "rebase"
+	MergeStrategyRebaseMerge   = "rebaseMerge"
 )
 
 // Repository represents an Azure DevOps Git repository
 type Repository struct {
+	ID      string     `json:"id"`
+	Name    string     `json:"name"`
+	Project ProjectRef `json:"project"`
+}
+
+// ProjectRef is a reference to a project, as embedded in other resources
+type ProjectRef struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
 }
 
+// emptyIdentityID is the identity ID that clears an identity field of a resource
+const emptyIdentityID = "00000000-0000-0000-0000-000000000000"
+
+// IdentityRef is a reference to a user or group, as embedded in other resources
+type IdentityRef struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName,omitempty"`
+	UniqueName  string `json:"uniqueName,omitempty"`
+}
+
+// Reviewer votes on a pull request
+const (
+	VoteApproved                = 10
+	VoteApprovedWithSuggestions = 5
+	VoteNone                    = 0
+	VoteWaitingForAuthor        = -5
+	VoteRejected                = -10
+)
+
 // PullRequest represents an Azure DevOps pull request
 type PullRequest struct {
-	PullRequestID int    `json:"pullRequestId"`
-	Title         string `json:"title"`
-	Description   string `json:"description"`
-	SourceRefName string `json:"sourceRefName"`
-	TargetRefName string `json:"targetRefName"`
-	Status        string `json:"status"`
+	PullRequestID int         `json:"pullRequestId"`
+	Title         string      `json:"title"`
+	Description   string      `json:"description"`
+	SourceRefName string      `json:"sourceRefName"`
+	TargetRefName string      `json:"targetRefName"`
+	Status        string      `json:"status"`
+	IsDraft       bool        `json:"isDraft"`
+	CreatedBy     IdentityRef `json:"createdBy"`
+	CreationDate  time.Time   `json:"creationDate"`
+	Repository    Repository  `json:"repository"`
+	Reviewers     []Reviewer  `json:"reviewers"`
+	// MergeStatus is the result of the last test merge, e.g. "succeeded" or "conflicts"
+	MergeStatus string `json:"mergeStatus"`
+	// AutoCompleteSetBy is set when the pull request completes automatically once
+	// its policies pass
+	AutoCompleteSetBy     *IdentityRef  `json:"autoCompleteSetBy,omitempty"`
+	LastMergeCommit       *CommitRef    `json:"lastMergeCommit,omitempty"`
+	LastMergeSourceCommit *CommitRef    `json:"lastMergeSourceCommit,omitempty"`
+	WorkItemRefs          []ResourceRef `json:"workItemRefs,omitempty"`
+	// CompletionOptions are used when the pull request is completed, manually or
+	// automatically
+	CompletionOptions *CompletionOptions `json:"completionOptions,omitempty"`
+	// ForkSource is set when the source branch is in a fork of the repository
+	ForkSource *ForkRef `json:"forkSource,omitempty"`
+}
+
+// ForkRef is a ref in a fork of a repository
+type ForkRef struct {
+	Name       string     `json:"name"`
+	Repository Repository `json:"repository"`
+}
+
+// CompletionOptions control how a pull request is merged into its target branch
+type CompletionOptions struct {
+	// MergeStrategy is one of the MergeStrategy constants
+	MergeStrategy      string `json:"mergeStrategy,omitempty"`
+	DeleteSourceBranch bool   `json:"deleteSourceBranch,omitempty"`
+	// TransitionWorkItems completes the linked work items after merging
+	TransitionWorkItems bool   `json:"transitionWorkItems,omitempty"`
+	MergeCommitMessage  string `json:"mergeCommitMessage,omitempty"`
+	BypassPolicy        bool   `json:"bypassPolicy,omitempty"`
+	BypassReason        string `json:"bypassReason,omitempty"`
+}
+
+// PullRequestUpdate is the request body for updating a pull request. Only the fields
+// that are set are changed.
+type PullRequestUpdate struct {
+	Status      string  `json:"status,omitempty"`
+	Title       string  `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+	// TargetRefName retargets the pull request to another branch
+	TargetRefName string `json:"targetRefName,omitempty"`
+	IsDraft       *bool  `json:"isDraft,omitempty"`
+	// LastMergeSourceCommit must be the pull request's current source commit when
+	// completing it, so changes pushed meanwhile are not merged unreviewed
+	LastMergeSourceCommit *CommitRef         `json:"lastMergeSourceCommit,omitempty"`
+	CompletionOptions     *CompletionOptions `json:"completionOptions,omitempty"`
+	// AutoCompleteSetBy turns on auto-complete on behalf of the identity; the empty
+	// identity ID turns it off
+	AutoCompleteSetBy *IdentityRef `json:"autoCompleteSetBy,omitempty"`
+}
+
+// Reviewer is a reviewer of a pull request with their vote
+type Reviewer struct {
+	IdentityRef
+	// Vote is one of the Vote constants
+	Vote        int  `json:"vote"`
+	IsRequired  bool `json:"isRequired"`
+	HasDeclined bool `json:"hasDeclined"`
+	IsFlagged   bool `json:"isFlagged"`
+}
+
+// CommitRef is a reference to a Git commit
+type CommitRef struct {
+	CommitID string `json:"commitId"`
+}
+
+// ResourceRef is a reference to another resource, such as a linked work item
+type ResourceRef struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+// VoteDescription describes a reviewer vote
+func VoteDescription(vote int) string {
+	switch {
+	case vote >= VoteApproved:
+		return "approved"
+	case vote >= VoteApprovedWithSuggestions:
+		return "approved with suggestions"
+	case vote == VoteNone:
+		return "no vote"
+	case vote > VoteRejected:
+		return "waiting for author"
+	default:
+		return "rejected"
+	}
+}
+
+// PullRequestSearchCriteria filters the pull requests returned by ListPullRequests.
+// Empty fields do not filter.
+type PullRequestSearchCriteria struct {
+	// Status is one of the PullRequestStatus constants (empty = active)
+	Status string
+	// CreatorID and ReviewerID are identity IDs
+	CreatorID  string
+	ReviewerID string
+	// SourceRefName and TargetRefName are full ref names, e.g. refs/heads/main
+	SourceRefName string
+	TargetRefName string
+	RepositoryID  string
+}
+
+// query returns the criteria as searchCriteria query parameters
+func (sc *PullRequestSearchCriteria) query() url.Values {
+	values := url.Values{}
+	params := []struct{ name, value string }{
+		{"status", sc.Status},
+		{"creatorId", sc.CreatorID},
+		{"reviewerId", sc.ReviewerID},
+		{"sourceRefName", sc.SourceRefName},
+		{"targetRefName", sc.TargetRefName},
+		{"repositoryId", sc.RepositoryID},
+	}
+	for _, p := range params {
+		if p.value != "" {
+			values.Set("searchCriteria."+p.name, p.value)
+		}
+	}
+	return values
 }
 
 // CreatePRRequest represents the request body for creating a pull request
@@ -34,11 +206,11 @@ type CreatePRRequest struct {
 }
 
 // GetRepository retrieves repository information by name
-func (c *Client) GetRepository(project, repoName string) (*Repository, error) {
+func (c *Client) GetRepository(ctx context.Context, project, repoName string) (*Repository, error) {
 	repoNameEncoded := url.PathEscape(repoName)
 	apiURL := c.buildURL(project, fmt.Sprintf("git/repositories/%s", repoNameEncoded))
 
-	respBody, err := c.doRequest("GET", apiURL, nil)
+	respBody, err := c.doRequest(ctx, "GET", apiURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get repository: %w", err)
 	}
@@ -52,11 +224,11 @@ func (c *Client) GetRepository(project, repoName string) (*Repository, error) {
 }
 
 // CreatePullRequest creates a new pull request
-func (c *Client) CreatePullRequest(project, repoID string, req *CreatePRRequest) (*PullRequest, error) {
+func (c *Client) CreatePullRequest(ctx context.Context, project, repoID string, req *CreatePRRequest) (*PullRequest, error) {
 	repoIDEncoded := url.PathEscape(repoID)
 	apiURL := c.buildURL(project, fmt.Sprintf("git/repositories/%s/pullrequests", repoIDEncoded))
 
-	respBody, err := c.doRequest("POST", apiURL, req)
+	respBody, err := c.doRequest(ctx, "POST", apiURL, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create pull request: %w", err)
 	}
@@ -69,6 +241,164 @@ func (c *Client) CreatePullRequest(project, repoID string, req *CreatePRRequest)
 	return &pr, nil
 }
 
+// GetPullRequest retrieves a pull request in project by ID
+func (c *Client) GetPullRequest(ctx context.Context, project string, id int) (*PullRequest, error) {
+	apiURL := c.buildURL(project, fmt.Sprintf("git/pullrequests/%d", id))
+
+	respBody, err := c.doRequest(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request: %w", err)
+	}
+
+	var pr PullRequest
+	if err := json.Unmarshal(respBody, &pr); err != nil {
+		c.logger.Debug("failed to parse pull request response", "error", err, "body", truncateString(string(respBody), 500))
+		return nil, fmt.Errorf("failed to parse pull request response: %w", err)
+	}
+
+	return &pr, nil
+}
+
+// SetReviewerVote casts reviewerID's vote on a pull request, adding them as a reviewer
+// if they are not one yet
+func (c *Client) SetReviewerVote(ctx context.Context, project, repoID string, pullRequestID int, reviewerID string, vote int) (*Reviewer, error) {
+	apiURL := c.buildURL(project, fmt.Sprintf("git/repositories/%s/pullrequests/%d/reviewers/%s",
+		url.PathEscape(repoID), pullRequestID, url.PathEscape(reviewerID)))
+
+	respBody, err := c.doRequest(ctx, "PUT", apiURL, map[string]int{"vote": vote})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set vote: %w", err)
+	}
+
+	var reviewer Reviewer
+	if err := json.Unmarshal(respBody, &reviewer); err != nil {
+		return nil, fmt.Errorf("failed to parse reviewer response: %w", err)
+	}
+
+	return &reviewer, nil
+}
+
+// AddReviewer adds a reviewer to a pull request without a vote. Adding a reviewer who
+// already voted resets their vote.
+func (c *Client) AddReviewer(ctx context.Context, project, repoID string, pullRequestID int, reviewerID string, isRequired bool) (*Reviewer, error) {
+	apiURL := c.buildURL(project, fmt.Sprintf("git/repositories/%s/pullrequests/%d/reviewers/%s",
+		url.PathEscape(repoID), pullRequestID, url.PathEscape(reviewerID)))
+
+	body := map[string]interface{}{"vote": VoteNone, "isRequired": isRequired}
+	respBody, err := c.doRequest(ctx, "PUT", apiURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add reviewer: %w", err)
+	}
+
+	var reviewer Reviewer
+	if err := json.Unmarshal(respBody, &reviewer); err != nil {
+		return nil, fmt.Errorf("failed to parse reviewer response: %w", err)
+	}
+
+	return &reviewer, nil
+}
+
+// UpdatePullRequest changes a pull request and returns it as updated
+func (c *Client) UpdatePullRequest(ctx context.Context, project, repoID string, id int, update *PullRequestUpdate) (*PullRequest, error) {
+	apiURL := c.buildURL(project, fmt.Sprintf("git/repositories/%s/pullrequests/%d", url.PathEscape(repoID), id))
+
+	respBody, err := c.doRequest(ctx, "PATCH", apiURL, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update pull request: %w", err)
+	}
+
+	var pr PullRequest
+	if err := json.Unmarshal(respBody, &pr); err != nil {
+		c.logger.Debug("failed to parse pull request response", "error", err, "body", truncateString(string(respBody), 500))
+		return nil, fmt.Errorf("failed to parse pull request response: %w", err)
+	}
+
+	return &pr, nil
+}
+
+// SetAutoComplete makes a pull request complete automatically with options once its
+// policies pass. userID is the identity the pull request is completed on behalf of.
+func (c *Client) SetAutoComplete(ctx context.Context, project, repoID string, id int, userID string, options *CompletionOptions) (*PullRequest, error) {
+	return c.UpdatePullRequest(ctx, project, repoID, id, &PullRequestUpdate{
+		AutoCompleteSetBy: &IdentityRef{ID: userID},
+		CompletionOptions: options,
+	})
+}
+
+// CancelAutoComplete turns off auto-complete for a pull request
+func (c *Client) CancelAutoComplete(ctx context.Context, project, repoID string, id int) (*PullRequest, error) {
+	return c.UpdatePullRequest(ctx, project, repoID, id, &PullRequestUpdate{
+		AutoCompleteSetBy: &IdentityRef{ID: emptyIdentityID},
+	})
+}
+
+// GetPullRequestWorkItems returns the work items linked to a pull request
+func (c *Client) GetPullRequestWorkItems(ctx context.Context, project, repoID string, id int) ([]ResourceRef, error) {
+	apiURL := c.buildURL(project, fmt.Sprintf("git/repositories/%s/pullrequests/%d/workitems", url.PathEscape(repoID), id))
+
+	respBody, err := c.doRequest(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request work items: %w", err)
+	}
+
+	var refs struct {
+		Value []ResourceRef `json:"value"`
+	}
+	if err := json.Unmarshal(respBody, &refs); err != nil {
+		return nil, fmt.Errorf("failed to parse pull request work items response: %w", err)
+	}
+
+	return refs.Value, nil
+}
+
+// ListPullRequests returns the pull requests in project matching criteria, newest first.
+// Pages are requested with $top and $skip until limit pull requests are found or none
+// are left; a limit of 0 returns all of them.
+func (c *Client) ListPullRequests(ctx context.Context, project string, criteria PullRequestSearchCriteria, limit int) ([]PullRequest, error) {
+	var pullRequests []PullRequest
+	for {
+		top := pullRequestPageSize
+		if limit > 0 && limit-len(pullRequests) < top {
+			top = limit - len(pullRequests)
+		}
+
+		query := criteria.query()
+		query.Set("$top", fmt.Sprint(top))
+		query.Set("$skip", fmt.Sprint(len(pullRequests)))
+		// Keep $top and $skip readable in logs; url.Values escapes the "$"
+		encoded := strings.NewReplacer("%24", "$").Replace(query.Encode())
+		apiURL := c.buildURL(project, "git/pullrequests?"+encoded)
+
+		respBody, err := c.doRequest(ctx, "GET", apiURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull requests: %w", err)
+		}
+
+		var page struct {
+			Value []PullRequest `json:"value"`
+		}
+		if err := json.Unmarshal(respBody, &page); err != nil {
+			c.logger.Debug("failed to parse pull requests response", "error", err, "body", truncateString(string(respBody), 500))
+			return nil, fmt.Errorf("failed to parse pull requests response: %w", err)
+		}
+
+		pullRequests = append(pullRequests, page.Value...)
+		if len(page.Value) < top || (limit > 0 && len(pullRequests) >= limit) {
+			return pullRequests, nil
+		}
+	}
+}
+
+// PullRequestArtifactURL returns the artifact URL work items use to link to a pull request
+func PullRequestArtifactURL(projectID, repoID string, id int) string {
+	return fmt.Sprintf("vstfs:///Git/PullRequestId/%s%%2F%s%%2F%d", projectID, repoID, id)
+}
+
+// BranchName returns the branch name of a ref, e.g. "main" for refs/heads/main
+func BranchName(refName string) string {
+	return strings.TrimPrefix(refName, "refs/heads/")
+}
+
 // FormatRefName formats a branch name to a full ref name
 func FormatRefName(branchName string) string {
 	if !hasPrefix(branchName, "refs/") {
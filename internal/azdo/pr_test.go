@@ -0,0 +1,255 @@
+package azdo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPullRequestServer serves total pull requests from the project-level list endpoint
+func newPullRequestServer(t *testing.T, total int, requests *[]string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/org/proj/_apis/git/pullrequests", r.URL.Path)
+		*requests = append(*requests, r.URL.RawQuery)
+
+		query := r.URL.Query()
+		top, _ := strconv.Atoi(query.Get("$top"))
+		skip, _ := strconv.Atoi(query.Get("$skip"))
+
+		var page struct {
+			Value []PullRequest `json:"value"`
+		}
+		for id := total - skip; id > 0 && id > total-skip-top; id-- {
+			page.Value = append(page.Value, PullRequest{PullRequestID: id})
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestListPullRequests_SearchCriteria(t *testing.T) {
+	var requests []string
+	server := newPullRequestServer(t, 2, &requests)
+	c, _ := newTestClient(t, server.URL)
+
+	criteria := PullRequestSearchCriteria{
+		Status:        PullRequestStatusActive,
+		ReviewerID:    "8c8c7d32-6b1b-47f4-b2e9-30b477b5ab3d",
+		TargetRefName: "refs/heads/main",
+	}
+	prs, err := c.ListPullRequests(context.Background(), "proj", criteria, 50)
+	require.NoError(t, err)
+	assert.Len(t, prs, 2)
+
+	require.Len(t, requests, 1)
+	assert.Equal(t, "$skip=0&$top=50&searchCriteria.reviewerId=8c8c7d32-6b1b-47f4-b2e9-30b477b5ab3d"+
+		"&searchCriteria.status=active&searchCriteria.targetRefName=refs%2Fheads%2Fmain&api-version=7.0", requests[0])
+}
+
+func TestListPullRequests_Paging(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int
+		limit    int
+		want     int
+		requests int
+	}{
+		{name: "all pages", total: 250, limit: 0, want: 250, requests: 3},
+		{name: "limit within first page", total: 250, limit: 30, want: 30, requests: 1},
+		{name: "limit across pages", total: 250, limit: 150, want: 150, requests: 2},
+		{name: "exact page", total: 100, limit: 0, want: 100, requests: 2},
+		{name: "empty", total: 0, limit: 0, want: 0, requests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			server := newPullRequestServer(t, tt.total, &requests)
+			c, _ := newTestClient(t, server.URL)
+
+			prs, err := c.ListPullRequests(context.Background(), "proj", PullRequestSearchCriteria{}, tt.limit)
+			require.NoError(t, err)
+			assert.Len(t, prs, tt.want)
+			assert.Len(t, requests, tt.requests)
+			if tt.want > 0 {
+				assert.Equal(t, tt.total, prs[0].PullRequestID)
+				assert.Equal(t, tt.total-tt.want+1, prs[len(prs)-1].PullRequestID)
+			}
+		})
+	}
+}
+
+func TestBranchName(t *testing.T) {
+	assert.Equal(t, "main", BranchName("refs/heads/main"))
+	assert.Equal(t, "feature/123/login", BranchName("refs/heads/feature/123/login"))
+	assert.Equal(t, "refs/pull/1/merge", BranchName("refs/pull/1/merge"))
+}
+
+func TestGetPullRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/org/proj/_apis/git/pullrequests/42", r.URL.Path)
+		w.Write([]byte(`{
+			"pullRequestId": 42, "title": "Add login", "status": "active", "isDraft": false,
+			"mergeStatus": "succeeded",
+			"createdBy": {"id": "u1", "displayName": "Jane Doe", "uniqueName": "jane@contoso.com"},
+			"creationDate": "2026-10-01T09:30:00Z",
+			"repository": {"id": "repo-1", "name": "web-app", "project": {"id": "p-1", "name": "proj"}},
+			"reviewers": [
+				{"id": "u2", "displayName": "John Smith", "vote": 10, "isRequired": true},
+				{"id": "u3", "displayName": "Web Team", "vote": -5}
+			],
+			"autoCompleteSetBy": {"id": "u1", "displayName": "Jane Doe"},
+			"lastMergeCommit": {"commitId": "0123456789abcdef"}
+		}`))
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+	pr, err := c.GetPullRequest(context.Background(), "proj", 42)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Jane Doe", pr.CreatedBy.DisplayName)
+	assert.Equal(t, "p-1", pr.Repository.Project.ID)
+	assert.Equal(t, "succeeded", pr.MergeStatus)
+	require.Len(t, pr.Reviewers, 2)
+	assert.Equal(t, "John Smith", pr.Reviewers[0].DisplayName)
+	assert.Equal(t, VoteApproved, pr.Reviewers[0].Vote)
+	assert.True(t, pr.Reviewers[0].IsRequired)
+	assert.Equal(t, VoteWaitingForAuthor, pr.Reviewers[1].Vote)
+	require.NotNil(t, pr.AutoCompleteSetBy)
+	assert.Equal(t, "0123456789abcdef", pr.LastMergeCommit.CommitID)
+}
+
+func TestVoteDescription(t *testing.T) {
+	assert.Equal(t, "approved", VoteDescription(VoteApproved))
+	assert.Equal(t, "approved with suggestions", VoteDescription(VoteApprovedWithSuggestions))
+	assert.Equal(t, "no vote", VoteDescription(VoteNone))
+	assert.Equal(t, "waiting for author", VoteDescription(VoteWaitingForAuthor))
+	assert.Equal(t, "rejected", VoteDescription(VoteRejected))
+}
+
+func TestSetReviewerVote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/org/proj/_apis/git/repositories/repo-1/pullrequests/42/reviewers/u1", r.URL.Path)
+
+		var body map[string]int
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]int{"vote": VoteRejected}, body)
+
+		w.Write([]byte(`{"id": "u1", "displayName": "Jane Doe", "vote": -10}`))
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+	reviewer, err := c.SetReviewerVote(context.Background(), "proj", "repo-1", 42, "u1", VoteRejected)
+	require.NoError(t, err)
+	assert.Equal(t, VoteRejected, reviewer.Vote)
+}
+
+func TestAddReviewer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/org/proj/_apis/git/repositories/repo-1/pullrequests/42/reviewers/u1", r.URL.Path)
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]interface{}{"vote": float64(0), "isRequired": true}, body)
+
+		w.Write([]byte(`{"id": "u1", "displayName": "Jane Doe", "isRequired": true}`))
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+	reviewer, err := c.AddReviewer(context.Background(), "proj", "repo-1", 42, "u1", true)
+	require.NoError(t, err)
+	assert.True(t, reviewer.IsRequired)
+}
+
+func TestUpdatePullRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		assert.Equal(t, "/org/proj/_apis/git/repositories/repo-1/pullrequests/42", r.URL.Path)
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]interface{}{
+			"status":                "completed",
+			"lastMergeSourceCommit": map[string]interface{}{"commitId": "abc123"},
+			"completionOptions":     map[string]interface{}{"mergeStrategy": "squash", "deleteSourceBranch": true},
+		}, body)
+
+		w.Write([]byte(`{"pullRequestId": 42, "status": "completed"}`))
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+	pr, err := c.UpdatePullRequest(context.Background(), "proj", "repo-1", 42, &PullRequestUpdate{
+		Status:                PullRequestStatusCompleted,
+		LastMergeSourceCommit: &CommitRef{CommitID: "abc123"},
+		CompletionOptions:     &CompletionOptions{MergeStrategy: MergeStrategySquash, DeleteSourceBranch: true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, PullRequestStatusCompleted, pr.Status)
+}
+
+func TestAutoComplete(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		assert.Equal(t, "/org/proj/_apis/git/repositories/repo-1/pullrequests/42", r.URL.Path)
+		body = nil
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Write([]byte(`{"pullRequestId": 42}`))
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+	_, err := c.SetAutoComplete(context.Background(), "proj", "repo-1", 42, "u1", &CompletionOptions{MergeStrategy: MergeStrategyRebase})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"autoCompleteSetBy": map[string]interface{}{"id": "u1"},
+		"completionOptions": map[string]interface{}{"mergeStrategy": "rebase"},
+	}, body)
+
+	_, err = c.CancelAutoComplete(context.Background(), "proj", "repo-1", 42)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"autoCompleteSetBy": map[string]interface{}{"id": "00000000-0000-0000-0000-000000000000"},
+	}, body)
+}
+
+func TestUpdatePullRequest_Fields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		// An empty description is sent, so the description can be cleared
+		assert.Equal(t, map[string]interface{}{
+			"title":         "New title",
+			"description":   "",
+			"targetRefName": "refs/heads/release",
+			"isDraft":       false,
+		}, body)
+		w.Write([]byte(`{"pullRequestId": 42}`))
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+	description, draft := "", false
+	_, err := c.UpdatePullRequest(context.Background(), "proj", "repo-1", 42, &PullRequestUpdate{
+		Title:         "New title",
+		Description:   &description,
+		TargetRefName: "refs/heads/release",
+		IsDraft:       &draft,
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,148 @@
+package azdo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how failed API requests are retried
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below 1 are treated as 1 (no retries).
+	MaxAttempts int
+	// BaseDelay is the initial backoff delay, doubled after every attempt
+	BaseDelay time.Duration
+	// MaxDelay caps a single wait. A Retry-After longer than MaxDelay is not waited for.
+	MaxDelay time.Duration
+	// RetryNonIdempotent also retries POST and PATCH requests
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns the retry policy used by new clients
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+// rateLimitHeaders are the headers Azure DevOps uses to report throttling
+var rateLimitHeaders = []string{
+	"Retry-After",
+	"X-RateLimit-Resource",
+	"X-RateLimit-Delay",
+	"X-RateLimit-Limit",
+	"X-RateLimit-Remaining",
+	"X-RateLimit-Reset",
+}
+
+// SetRetryPolicy replaces the client's retry policy
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+// canRetry reports whether a request with the given method may be sent again
+func (p RetryPolicy) canRetry(method string, attempt int) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+	return p.RetryNonIdempotent || isIdempotent(method)
+}
+
+// backoff returns a jittered exponential delay for the given attempt (1-based)
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	// Equal jitter: wait at least half the delay, plus a random share of the rest
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// isIdempotent reports whether repeating a request with this method is safe
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isRetryableStatus reports whether a response status indicates a transient failure
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isTransientError reports whether a transport error is worth retrying
+func isTransientError(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// sleepContext waits for d, returning early with ctx's error if it is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryAfter returns the server-requested delay from Retry-After or X-RateLimit-Reset
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return max(at.Sub(now), 0), true
+		}
+	}
+	if value := header.Get("X-RateLimit-Reset"); value != "" {
+		if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return max(time.Unix(epoch, 0).Sub(now), 0), true
+		}
+	}
+	return 0, false
+}
+
+// logRateLimit reports throttling headers
+func (c *Client) logRateLimit(status int, header http.Header) {
+	attrs := []any{"status", status}
+	for _, name := range rateLimitHeaders {
+		if value := header.Get(name); value != "" {
+			attrs = append(attrs, strings.ToLower(name), value)
+		}
+	}
+	c.logger.Info("request throttled", attrs...)
+}
@@ -0,0 +1,258 @@
+package azdo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient returns a client pointed at server that records sleeps instead of waiting
+func newTestClient(t *testing.T, serverURL string) (*Client, *[]time.Duration) {
+	t.Helper()
+
+	var slept []time.Duration
+	c := NewClient(serverURL, "org", "token", nil)
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return ctx.Err()
+	}
+	return c, &slept
+}
+
+func TestDoRequest_RetriesThrottledGET(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.Header().Set("X-RateLimit-Resource", "Core")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer server.Close()
+
+	c, slept := newTestClient(t, server.URL)
+
+	body, err := c.doRequest(context.Background(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":1}`, string(body))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Equal(t, []time.Duration{2 * time.Second}, *slept)
+}
+
+func TestDoRequest_GivesUpAfterMaxAttempts(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c, slept := newTestClient(t, server.URL)
+	c.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: 10 * time.Millisecond, MaxDelay: time.Second})
+
+	_, err := c.doRequest(context.Background(), http.MethodGet, server.URL, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 503")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Len(t, *slept, 2)
+}
+
+func TestDoRequest_DoesNotRetryPOSTByDefault(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c, slept := newTestClient(t, server.URL)
+
+	_, err := c.doRequest(context.Background(), http.MethodPost, server.URL, map[string]string{"a": "b"})
+	require.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Empty(t, *slept)
+}
+
+func TestDoRequest_RetriesPOSTWhenEnabled(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+	policy := DefaultRetryPolicy()
+	policy.RetryNonIdempotent = true
+	c.SetRetryPolicy(policy)
+
+	_, err := c.doRequest(context.Background(), http.MethodPost, server.URL, map[string]string{"a": "b"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestDoRequest_RetryAfterBeyondMaxDelay(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	c, slept := newTestClient(t, server.URL)
+
+	_, err := c.doRequest(context.Background(), http.MethodGet, server.URL, nil)
+	require.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Empty(t, *slept)
+}
+
+func TestDoRequest_DoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+
+	_, err := c.doRequest(context.Background(), http.MethodGet, server.URL, nil)
+	require.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestDoRequest_RetriesNetworkErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serverURL := server.URL
+	server.Close()
+
+	c, slept := newTestClient(t, serverURL)
+	c.SetRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Second})
+
+	_, err := c.doRequest(context.Background(), http.MethodGet, serverURL, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "request failed")
+	assert.Len(t, *slept, 1)
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		expected time.Duration
+		ok       bool
+	}{
+		{
+			name:     "seconds",
+			header:   http.Header{"Retry-After": {"5"}},
+			expected: 5 * time.Second,
+			ok:       true,
+		},
+		{
+			name:     "HTTP date",
+			header:   http.Header{"Retry-After": {now.Add(10 * time.Second).Format(http.TimeFormat)}},
+			expected: 10 * time.Second,
+			ok:       true,
+		},
+		{
+			name:     "rate limit reset",
+			header:   http.Header{"X-Ratelimit-Reset": {"1704110420"}},
+			expected: 20 * time.Second,
+			ok:       true,
+		},
+		{
+			name:   "no header",
+			header: http.Header{},
+			ok:     false,
+		},
+		{
+			name:   "invalid value",
+			header: http.Header{"Retry-After": {"soon"}},
+			ok:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, ok := retryAfter(tt.header, now)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, delay)
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for attempt := 1; attempt <= 8; attempt++ {
+		delay := policy.backoff(attempt)
+		assert.LessOrEqual(t, delay, time.Second)
+		assert.GreaterOrEqual(t, delay, 50*time.Millisecond)
+	}
+
+	// Later attempts wait at least half the capped delay
+	assert.GreaterOrEqual(t, policy.backoff(8), 500*time.Millisecond)
+}
+
+func TestDoRequest_CancelledContextStopsRetrying(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewClient(server.URL, "org", "token", nil)
+	c.sleep = func(context.Context, time.Duration) error {
+		// Simulate Ctrl-C while waiting between attempts
+		cancel()
+		return context.Canceled
+	}
+
+	_, err := c.doRequest(ctx, http.MethodGet, server.URL, nil)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestDoRequest_CancelledContextAbortsRequest(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	c := NewClient(server.URL, "org", "token", nil)
+
+	_, err := c.doRequest(ctx, http.MethodGet, server.URL, nil)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestSleepContext(t *testing.T) {
+	assert.NoError(t, sleepContext(context.Background(), time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, sleepContext(ctx, time.Hour), context.Canceled)
+}
@@ -0,0 +1,177 @@
+package azdo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Comment thread statuses
+const (
+	ThreadStatusActive   = "active"
+	ThreadStatusFixed    = "fixed"
+	ThreadStatusWontFix  = "wontFix"
+	ThreadStatusClosed   = "closed"
+	ThreadStatusByDesign = "byDesign"
+	ThreadStatusPending  = "pending"
+)
+
+// Comment types. Azure DevOps adds system comments for events such as votes and pushes.
+const (
+	CommentTypeText   = "text"
+	CommentTypeSystem = "system"
+)
+
+// Thread is a comment thread on a pull request
+type Thread struct {
+	ID            int            `json:"id"`
+	Status        string         `json:"status"`
+	ThreadContext *ThreadContext `json:"threadContext,omitempty"`
+	Comments      []Comment      `json:"comments"`
+	IsDeleted     bool           `json:"isDeleted"`
+}
+
+// Comment is a comment in a pull request thread
+type Comment struct {
+	ID              int         `json:"id"`
+	ParentCommentID int         `json:"parentCommentId"`
+	Content         string      `json:"content"`
+	CommentType     string      `json:"commentType"`
+	Author          IdentityRef `json:"author"`
+	PublishedDate   time.Time   `json:"publishedDate"`
+	IsDeleted       bool        `json:"isDeleted"`
+}
+
+// ThreadContext places a thread on lines of a file in the pull request. Lines on the
+// right side are lines of the source branch version of the file.
+type ThreadContext struct {
+	// FilePath is the path from the repository root, starting with "/"
+	FilePath       string        `json:"filePath"`
+	RightFileStart *FilePosition `json:"rightFileStart,omitempty"`
+	RightFileEnd   *FilePosition `json:"rightFileEnd,omitempty"`
+}
+
+// FilePosition is a position in a file; lines and offsets start at 1
+type FilePosition struct {
+	Line   int `json:"line"`
+	Offset int `json:"offset"`
+}
+
+// Line returns the first line the thread is on, or 0 for threads on a whole file or
+// on the pull request itself
+func (t *Thread) Line() int {
+	if t.ThreadContext == nil || t.ThreadContext.RightFileStart == nil {
+		return 0
+	}
+	return t.ThreadContext.RightFileStart.Line
+}
+
+// FilePath returns the path of the file the thread is on, or "" for threads on the
+// pull request itself
+func (t *Thread) FilePath() string {
+	if t.ThreadContext == nil {
+		return ""
+	}
+	return t.ThreadContext.FilePath
+}
+
+// CreateThreadRequest represents the request body for creating a comment thread
+type CreateThreadRequest struct {
+	Status        string                 `json:"status"`
+	ThreadContext *ThreadContext         `json:"threadContext,omitempty"`
+	Comments      []CreateCommentRequest `json:"comments"`
+}
+
+// CreateCommentRequest represents the request body for adding a comment
+type CreateCommentRequest struct {
+	// ParentCommentID is the comment replied to (0 for the first comment of a thread)
+	ParentCommentID int    `json:"parentCommentId,omitempty"`
+	Content         string `json:"content"`
+	CommentType     string `json:"commentType"`
+}
+
+// NewCommentThread returns a request for an active thread with a single text comment
+func NewCommentThread(content string) *CreateThreadRequest {
+	return &CreateThreadRequest{
+		Status:   ThreadStatusActive,
+		Comments: []CreateCommentRequest{{Content: content, CommentType: CommentTypeText}},
+	}
+}
+
+// threadsPath returns the API path of the threads of a pull request
+func threadsPath(repoID string, pullRequestID int) string {
+	return fmt.Sprintf("git/repositories/%s/pullrequests/%d/threads", url.PathEscape(repoID), pullRequestID)
+}
+
+// ListThreads returns the comment threads of a pull request, including system threads
+func (c *Client) ListThreads(ctx context.Context, project, repoID string, pullRequestID int) ([]Thread, error) {
+	apiURL := c.buildURL(project, threadsPath(repoID, pullRequestID))
+
+	respBody, err := c.doRequest(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list comment threads: %w", err)
+	}
+
+	var threads struct {
+		Value []Thread `json:"value"`
+	}
+	if err := json.Unmarshal(respBody, &threads); err != nil {
+		c.logger.Debug("failed to parse comment threads response", "error", err, "body", truncateString(string(respBody), 500))
+		return nil, fmt.Errorf("failed to parse comment threads response: %w", err)
+	}
+
+	return threads.Value, nil
+}
+
+// CreateThread starts a new comment thread on a pull request
+func (c *Client) CreateThread(ctx context.Context, project, repoID string, pullRequestID int, thread *CreateThreadRequest) (*Thread, error) {
+	apiURL := c.buildURL(project, threadsPath(repoID, pullRequestID))
+
+	respBody, err := c.doRequest(ctx, "POST", apiURL, thread)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create comment thread: %w", err)
+	}
+
+	var created Thread
+	if err := json.Unmarshal(respBody, &created); err != nil {
+		return nil, fmt.Errorf("failed to parse comment thread response: %w", err)
+	}
+
+	return &created, nil
+}
+
+// AddComment adds a comment to an existing thread
+func (c *Client) AddComment(ctx context.Context, project, repoID string, pullRequestID, threadID int, comment *CreateCommentRequest) (*Comment, error) {
+	apiURL := c.buildURL(project, fmt.Sprintf("%s/%d/comments", threadsPath(repoID, pullRequestID), threadID))
+
+	respBody, err := c.doRequest(ctx, "POST", apiURL, comment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add comment: %w", err)
+	}
+
+	var created Comment
+	if err := json.Unmarshal(respBody, &created); err != nil {
+		return nil, fmt.Errorf("failed to parse comment response: %w", err)
+	}
+
+	return &created, nil
+}
+
+// SetThreadStatus changes the status of a thread, e.g. to resolve it
+func (c *Client) SetThreadStatus(ctx context.Context, project, repoID string, pullRequestID, threadID int, status string) (*Thread, error) {
+	apiURL := c.buildURL(project, fmt.Sprintf("%s/%d", threadsPath(repoID, pullRequestID), threadID))
+
+	respBody, err := c.doRequest(ctx, "PATCH", apiURL, map[string]string{"status": status})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update comment thread: %w", err)
+	}
+
+	var thread Thread
+	if err := json.Unmarshal(respBody, &thread); err != nil {
+		return nil, fmt.Errorf("failed to parse comment thread response: %w", err)
+	}
+
+	return &thread, nil
+}
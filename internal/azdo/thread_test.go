@@ -0,0 +1,95 @@
+package azdo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateThread(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/org/proj/_apis/git/repositories/repo-1/pullrequests/42/threads", r.URL.Path)
+
+		var thread Thread
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&thread))
+		assert.Equal(t, ThreadStatusActive, thread.Status)
+		require.Len(t, thread.Comments, 1)
+		assert.Equal(t, "Looks good", thread.Comments[0].Content)
+		assert.Equal(t, CommentTypeText, thread.Comments[0].CommentType)
+
+		thread.ID = 7
+		json.NewEncoder(w).Encode(thread)
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+	thread, err := c.CreateThread(context.Background(), "proj", "repo-1", 42, NewCommentThread("Looks good"))
+	require.NoError(t, err)
+	assert.Equal(t, 7, thread.ID)
+}
+
+func TestListThreads(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/org/proj/_apis/git/repositories/repo-1/pullrequests/42/threads", r.URL.Path)
+		w.Write([]byte(`{"value": [
+			{"id": 1, "status": "active", "threadContext": {"filePath": "/src/login.go", "rightFileStart": {"line": 42, "offset": 1}},
+				"comments": [{"id": 1, "content": "Handle the error", "commentType": "text", "author": {"displayName": "Jane Doe"}}]},
+			{"id": 2, "comments": [{"id": 1, "content": "Jane Doe voted 10", "commentType": "system"}]}]}`))
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+	threads, err := c.ListThreads(context.Background(), "proj", "repo-1", 42)
+	require.NoError(t, err)
+	require.Len(t, threads, 2)
+	assert.Equal(t, "/src/login.go", threads[0].FilePath())
+	assert.Equal(t, 42, threads[0].Line())
+	assert.Equal(t, "Jane Doe", threads[0].Comments[0].Author.DisplayName)
+	assert.Equal(t, "", threads[1].FilePath())
+	assert.Equal(t, 0, threads[1].Line())
+}
+
+func TestAddComment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/org/proj/_apis/git/repositories/repo-1/pullrequests/42/threads/7/comments", r.URL.Path)
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]interface{}{"parentCommentId": float64(1), "content": "Done", "commentType": "text"}, body)
+
+		w.Write([]byte(`{"id": 2, "parentCommentId": 1, "content": "Done"}`))
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+	comment, err := c.AddComment(context.Background(), "proj", "repo-1", 42, 7,
+		&CreateCommentRequest{ParentCommentID: 1, Content: "Done", CommentType: CommentTypeText})
+	require.NoError(t, err)
+	assert.Equal(t, 2, comment.ID)
+}
+
+func TestSetThreadStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		assert.Equal(t, "/org/proj/_apis/git/repositories/repo-1/pullrequests/42/threads/7", r.URL.Path)
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]string{"status": "fixed"}, body)
+
+		w.Write([]byte(`{"id": 7, "status": "fixed"}`))
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+	thread, err := c.SetThreadStatus(context.Background(), "proj", "repo-1", 42, 7, ThreadStatusFixed)
+	require.NoError(t, err)
+	assert.Equal(t, ThreadStatusFixed, thread.Status)
+}
@@ -1,47 +1,106 @@
 package azdo
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/url"
 	"strings"
 )
 
+// jsonPatchContentType is the content type of JSON Patch documents, which the work
+// item API requires for updates
+const jsonPatchContentType = "application/json-patch+json"
+
+// jsonPatch is a JSON Patch document; doRequest sends it with jsonPatchContentType
+type jsonPatch []patchOperation
+
+// patchOperation is a single JSON Patch operation
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// RelationArtifactLink is the relation type of links from work items to other artifacts,
+// such as pull requests
+const RelationArtifactLink = "ArtifactLink"
+
 // WorkItem represents an Azure DevOps work item
 type WorkItem struct {
-	ID     int                    `json:"id"`
-	Fields map[string]interface{} `json:"fields"`
+	ID        int                    `json:"id"`
+	Rev       int                    `json:"rev"`
+	Fields    map[string]interface{} `json:"fields"`
+	Relations []WorkItemRelation     `json:"relations,omitempty"`
 }
 
-// GetWorkItem retrieves a work item by ID
-func (c *Client) GetWorkItem(id int) (*WorkItem, error) {
-	// Properly encode the organization name in the URL path
-	orgEncoded := url.PathEscape(c.organization)
-	apiURL := fmt.Sprintf("%s/%s/_apis/wit/workitems/%d?api-version=%s", baseURL, orgEncoded, id, apiVersion)
+// WorkItemRelation is a link from a work item to another work item or artifact
+type WorkItemRelation struct {
+	Rel        string                 `json:"rel"`
+	URL        string                 `json:"url"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
 
-	c.debugLog("[DEBUG] Organization (original): %q\n", c.organization)
-	c.debugLog("[DEBUG] Organization (encoded): %q\n", orgEncoded)
-	c.debugLog("[DEBUG] Constructed URL: %s\n", apiURL)
+// GetWorkItem retrieves a work item by ID
+func (c *Client) GetWorkItem(ctx context.Context, id int) (*WorkItem, error) {
+	apiURL := c.buildURL("", fmt.Sprintf("wit/workitems/%d", id))
 
-	respBody, err := c.doRequest("GET", apiURL, nil)
+	respBody, err := c.doRequest(ctx, "GET", apiURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get work item: %w", err)
 	}
 
-	c.debugLog("[DEBUG] Attempting to unmarshal JSON response (length: %d bytes)\n", len(respBody))
-	
 	var workItem WorkItem
 	if err := json.Unmarshal(respBody, &workItem); err != nil {
-		c.debugLog("[DEBUG] JSON Unmarshal error: %v\n", err)
-		c.debugLog("[DEBUG] Response body that failed to parse: %s\n", string(respBody))
+		c.logger.Debug("failed to parse work item response", "error", err, "body", truncateString(string(respBody), 500))
 		return nil, fmt.Errorf("failed to parse work item response: %w", err)
 	}
-	
-	c.debugLog("[DEBUG] Successfully unmarshaled work item: ID=%d\n", workItem.ID)
 
 	return &workItem, nil
 }
 
+// AddWorkItemRelation adds a link to a work item
+func (c *Client) AddWorkItemRelation(ctx context.Context, id int, relation WorkItemRelation) error {
+	apiURL := c.buildURL("", fmt.Sprintf("wit/workitems/%d", id))
+
+	patch := jsonPatch{{Op: "add", Path: "/relations/-", Value: relation}}
+	if _, err := c.doRequest(ctx, "PATCH", apiURL, patch); err != nil {
+		return fmt.Errorf("failed to link work item %d: %w", id, err)
+	}
+	return nil
+}
+
+// RemoveWorkItemRelation removes the link to artifactURL from a work item. It fails if the
+// work item has no such link, or if the work item changes in the meantime.
+func (c *Client) RemoveWorkItemRelation(ctx context.Context, id int, artifactURL string) error {
+	apiURL := c.buildURL("", fmt.Sprintf("wit/workitems/%d?$expand=relations", id))
+	respBody, err := c.doRequest(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get work item: %w", err)
+	}
+
+	var workItem WorkItem
+	if err := json.Unmarshal(respBody, &workItem); err != nil {
+		return fmt.Errorf("failed to parse work item response: %w", err)
+	}
+
+	for i, relation := range workItem.Relations {
+		if !strings.EqualFold(relation.URL, artifactURL) {
+			continue
+		}
+		// Relations are removed by index, so the revision guards against concurrent changes
+		patch := jsonPatch{
+			{Op: "test", Path: "/rev", Value: workItem.Rev},
+			{Op: "remove", Path: fmt.Sprintf("/relations/%d", i)},
+		}
+		if _, err := c.doRequest(ctx, "PATCH", c.buildURL("", fmt.Sprintf("wit/workitems/%d", id)), patch); err != nil {
+			return fmt.Errorf("failed to unlink work item %d: %w", id, err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("work item %d has no link to %s", id, artifactURL)
+}
+
 // GetWorkItemType returns the work item type (User Story, Bug, Task, etc.)
 func (wi *WorkItem) GetWorkItemType() string {
 	if workItemType, ok := wi.Fields["System.WorkItemType"].(string); ok {
@@ -0,0 +1,78 @@
+package azdo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testArtifactURL = "vstfs:///Git/PullRequestId/p-1%2Frepo-1%2F42"
+
+func TestPullRequestArtifactURL(t *testing.T) {
+	assert.Equal(t, testArtifactURL, PullRequestArtifactURL("p-1", "repo-1", 42))
+}
+
+func TestAddWorkItemRelation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		assert.Equal(t, "/org/_apis/wit/workitems/123", r.URL.Path)
+		assert.Equal(t, "application/json-patch+json", r.Header.Get("Content-Type"))
+
+		var patch []map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&patch))
+		assert.Equal(t, []map[string]interface{}{{
+			"op":   "add",
+			"path": "/relations/-",
+			"value": map[string]interface{}{
+				"rel":        "ArtifactLink",
+				"url":        testArtifactURL,
+				"attributes": map[string]interface{}{"name": "Pull Request"},
+			},
+		}}, patch)
+
+		w.Write([]byte(`{"id": 123}`))
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+	err := c.AddWorkItemRelation(context.Background(), 123, WorkItemRelation{
+		Rel:        RelationArtifactLink,
+		URL:        testArtifactURL,
+		Attributes: map[string]interface{}{"name": "Pull Request"},
+	})
+	require.NoError(t, err)
+}
+
+func TestRemoveWorkItemRelation(t *testing.T) {
+	var patch []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/org/_apis/wit/workitems/123", r.URL.Path)
+		if r.Method == http.MethodGet {
+			assert.Equal(t, "relations", r.URL.Query().Get("$expand"))
+			w.Write([]byte(`{"id": 123, "rev": 7, "relations": [
+				{"rel": "System.LinkTypes.Hierarchy-Reverse", "url": "https://dev.azure.com/org/_apis/wit/workItems/100"},
+				{"rel": "ArtifactLink", "url": "` + testArtifactURL + `"}]}`))
+			return
+		}
+		assert.Equal(t, "application/json-patch+json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&patch))
+		w.Write([]byte(`{"id": 123}`))
+	}))
+	defer server.Close()
+
+	c, _ := newTestClient(t, server.URL)
+	require.NoError(t, c.RemoveWorkItemRelation(context.Background(), 123, testArtifactURL))
+	assert.Equal(t, []map[string]interface{}{
+		{"op": "test", "path": "/rev", "value": float64(7)},
+		{"op": "remove", "path": "/relations/1"},
+	}, patch)
+
+	err := c.RemoveWorkItemRelation(context.Background(), 123, "vstfs:///Git/PullRequestId/p-1%2Frepo-1%2F43")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has no link")
+}
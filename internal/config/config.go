@@ -14,6 +14,47 @@ type Config struct {
 	Project         string `mapstructure:"project"`
 	Repository      string `mapstructure:"repository"`
 	DefaultReviewer string `mapstructure:"default_reviewer"`
+	ServerURL       string `mapstructure:"server_url"`
+
+	// RetryMaxAttempts is the total number of attempts for a failed API request (0 = default)
+	RetryMaxAttempts int `mapstructure:"retry_max_attempts"`
+	// RetryMaxDelay is the longest single wait between attempts, e.g. "30s" (empty = default)
+	RetryMaxDelay string `mapstructure:"retry_max_delay"`
+
+	// RequiredReviewers is a comma-separated list of reviewers added to new pull requests
+	// as required reviewers; DefaultReviewer lists optional ones the same way
+	RequiredReviewers string `mapstructure:"required_reviewers"`
+
+	// TargetBranch is the default target branch for pull requests
+	TargetBranch string `mapstructure:"target_branch"`
+	// BranchFormat is the branch naming convention, using the {type}, {id} and
+	// {description} placeholders (empty = "{type}/{id}/{description}")
+	BranchFormat string `mapstructure:"branch_format"`
+	// TitleFormat is the title of a pull request created without --title for a work item,
+	// using the {id}, {type} and {title} placeholders (empty = "#{id} {title}")
+	TitleFormat string `mapstructure:"title_format"`
+
+	// CredentialStore selects where tokens are stored: "keyring" (default) or "file"
+	// for a passphrase-encrypted file in the config directory
+	CredentialStore string `mapstructure:"credential_store"`
+
+	// ExpiryWarningDays is how many days before a token expires commands start warning
+	// about it (0 = default of 7 days, negative = never warn)
+	ExpiryWarningDays int `mapstructure:"expiry_warning_days"`
+
+	// EntraAuthority is the Microsoft Entra ID authority used by 'auth login --entra',
+	// e.g. https://login.microsoftonline.com/<tenant> (empty = any work or school account)
+	EntraAuthority string `mapstructure:"entra_authority"`
+	// EntraClientID is the application ID used to sign in (empty = the Azure CLI's)
+	EntraClientID string `mapstructure:"entra_client_id"`
+
+	// Profile is the name of the selected profile (see profiles.yaml), empty for none
+	Profile string `mapstructure:"profile"`
+
+	// ProfileOrganization and ProfileServerURL are the organization and server of the
+	// selected profile, set by Resolve. The profile's credentials only apply to them.
+	ProfileOrganization string `mapstructure:"-"`
+	ProfileServerURL    string `mapstructure:"-"`
 }
 
 var (
@@ -38,6 +79,18 @@ func Load() (*Config, error) {
 	viper.SetDefault("project", "")
 	viper.SetDefault("repository", "")
 	viper.SetDefault("default_reviewer", "")
+	viper.SetDefault("server_url", "")
+	viper.SetDefault("retry_max_attempts", 0)
+	viper.SetDefault("retry_max_delay", "")
+	viper.SetDefault("required_reviewers", "")
+	viper.SetDefault("target_branch", "")
+	viper.SetDefault("branch_format", "")
+	viper.SetDefault("title_format", "")
+	viper.SetDefault("credential_store", "")
+	viper.SetDefault("expiry_warning_days", 0)
+	viper.SetDefault("entra_authority", "")
+	viper.SetDefault("entra_client_id", "")
+	viper.SetDefault("profile", "")
 
 	// Create config directory if it doesn't exist
 	if err := os.MkdirAll(configDir, 0700); err != nil {
@@ -74,6 +127,18 @@ func Save(cfg *Config) error {
 	viper.Set("project", cfg.Project)
 	viper.Set("repository", cfg.Repository)
 	viper.Set("default_reviewer", cfg.DefaultReviewer)
+	viper.Set("server_url", cfg.ServerURL)
+	viper.Set("retry_max_attempts", cfg.RetryMaxAttempts)
+	viper.Set("retry_max_delay", cfg.RetryMaxDelay)
+	viper.Set("required_reviewers", cfg.RequiredReviewers)
+	viper.Set("target_branch", cfg.TargetBranch)
+	viper.Set("branch_format", cfg.BranchFormat)
+	viper.Set("title_format", cfg.TitleFormat)
+	viper.Set("credential_store", cfg.CredentialStore)
+	viper.Set("expiry_warning_days", cfg.ExpiryWarningDays)
+	viper.Set("entra_authority", cfg.EntraAuthority)
+	viper.Set("entra_client_id", cfg.EntraClientID)
+	viper.Set("profile", cfg.Profile)
 
 	if err := viper.WriteConfig(); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
@@ -82,6 +147,11 @@ func Save(cfg *Config) error {
 	return nil
 }
 
+// GetConfigFile returns the global configuration file path
+func GetConfigFile() string {
+	return configFile
+}
+
 // GetConfigDir returns the configuration directory path
 func GetConfigDir() string {
 	return configDir
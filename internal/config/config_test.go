@@ -209,3 +209,25 @@ func TestLoad_CreatesConfigDirectory(t *testing.T) {
 	_, err = os.Stat(configDir)
 	assert.NoError(t, err)
 }
+
+func TestSave_ServerURL(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, ".dex-cli")
+
+	// Save original config dir and restore after test
+	originalConfigDir := GetConfigDir()
+	defer SetConfigDir(originalConfigDir)
+
+	SetConfigDir(configDir)
+
+	_, err := Load()
+	require.NoError(t, err)
+
+	err = Save(&Config{Organization: "DefaultCollection", ServerURL: "https://tfs.corp"})
+	require.NoError(t, err)
+
+	loadedCfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "DefaultCollection", loadedCfg.Organization)
+	assert.Equal(t, "https://tfs.corp", loadedCfg.ServerURL)
+}
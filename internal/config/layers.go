@@ -0,0 +1,349 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/chriskievit/dex-cli/internal/git"
+	"github.com/spf13/viper"
+)
+
+const (
+	// RepoConfigFile is the committed, team-wide configuration file at the git root
+	RepoConfigFile = ".dex.yaml"
+	// RepoLocalConfigFile is the uncommitted, personal override file at the git root
+	RepoLocalConfigFile = ".dex.local.yaml"
+
+	// envPrefix is prepended to the upper-cased key to form an environment variable name,
+	// e.g. DEX_PROJECT for "project"
+	envPrefix = "DEX_"
+)
+
+// Source identifies the layer a configuration value came from
+type Source string
+
+// Configuration layers, from lowest to highest precedence
+const (
+	SourceDefault   Source = "default"
+	SourceRemote    Source = "git remote"
+	SourceGlobal    Source = "global"
+	SourceProfile   Source = "profile"
+	SourceRepo      Source = "repo"
+	SourceRepoLocal Source = "repo-local"
+	SourceEnv       Source = "env"
+	SourceFlag      Source = "flag"
+)
+
+// Origin describes where a configuration value came from
+type Origin struct {
+	Source Source
+	// Location is the file path, environment variable or flag that set the value
+	Location string
+}
+
+// String formats the origin for display, e.g. "repo (/src/app/.dex.yaml)"
+func (o Origin) String() string {
+	if o.Location == "" {
+		return string(o.Source)
+	}
+	return fmt.Sprintf("%s (%s)", o.Source, o.Location)
+}
+
+// Resolved is the effective configuration after layering, with the origin of every value
+type Resolved struct {
+	*Config
+	// Origins maps configuration keys (e.g. "project") to the layer that set them
+	Origins map[string]Origin
+	// Files lists the configuration files that were read, lowest precedence first
+	Files []string
+	// Warnings describes configuration files that were skipped
+	Warnings []string
+}
+
+// NewResolved wraps a configuration whose values all come from defaults
+func NewResolved(cfg *Config) *Resolved {
+	return &Resolved{Config: cfg, Origins: map[string]Origin{}}
+}
+
+// Origin returns where the value for key came from
+func (r *Resolved) Origin(key string) Origin {
+	if origin, ok := r.Origins[key]; ok {
+		return origin
+	}
+	return Origin{Source: SourceDefault}
+}
+
+// Set overrides the value for key and records its origin
+func (r *Resolved) Set(key string, value interface{}, origin Origin) error {
+	field, ok := fieldByKey(r.Config, key)
+	if !ok {
+		return fmt.Errorf("unknown configuration key: %s", key)
+	}
+	v := reflect.ValueOf(value)
+	if !v.Type().AssignableTo(field.Type()) {
+		return fmt.Errorf("invalid value for %s: %v", key, value)
+	}
+	field.Set(v)
+	r.Origins[key] = origin
+	return nil
+}
+
+// Keys returns all configuration keys in declaration order
+func Keys() []string {
+	t := reflect.TypeOf(Config{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if key := t.Field(i).Tag.Get("mapstructure"); key != "" && key != "-" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// EnvVar returns the environment variable that overrides key, e.g. DEX_PROJECT
+func EnvVar(key string) string {
+	return envPrefix + strings.ToUpper(key)
+}
+
+// credentialKeys choose where credentials are read from and sent to. They are never read
+// from repository files, which come with a clone and so may be written by anyone.
+var credentialKeys = map[string]bool{
+	"server_url":       true,
+	"profile":          true,
+	"credential_store": true,
+	"entra_authority":  true,
+	"entra_client_id":  true,
+}
+
+// repoKeys are the keys read from the committed repository file. They are team defaults,
+// so besides the credential keys it cannot change e.g. the organization or retry policy.
+var repoKeys = map[string]bool{
+	"project":            true,
+	"repository":         true,
+	"target_branch":      true,
+	"branch_format":      true,
+	"title_format":       true,
+	"default_reviewer":   true,
+	"required_reviewers": true,
+}
+
+// repoLocalKeys are the keys read from the local repository file: all but credentialKeys
+func repoLocalKeys() map[string]bool {
+	keys := map[string]bool{}
+	for _, key := range Keys() {
+		if !credentialKeys[key] {
+			keys[key] = true
+		}
+	}
+	return keys
+}
+
+// fileLayer is a configuration file applied during Resolve
+type fileLayer struct {
+	path   string
+	source Source
+	// keys limits the keys read from the file (nil = all keys)
+	keys map[string]bool
+}
+
+// layer is a set of configuration values applied during Resolve
+type layer struct {
+	values map[string]interface{}
+	origin func(key string) Origin
+}
+
+// Resolve builds the effective configuration. Layers are applied from lowest to
+// highest precedence:
+//
+//  1. the global file (~/.dex-cli/config.yaml)
+//  2. the selected profile (~/.dex-cli/profiles.yaml)
+//  3. the repository file (.dex.yaml at repoRoot, usually committed), limited to the
+//     team defaults in repoKeys
+//  4. the local repository file (.dex.local.yaml at repoRoot), without credentialKeys.
+//     It is skipped with a warning when git tracks it, as it is then no longer personal.
+//  5. the organization and server of the selected profile
+//  6. DEX_* environment variables (e.g. DEX_PROJECT)
+//
+// The profile is the given one (from the --profile flag), or else the highest "profile"
+// value of the global file and environment, so DEX_PROFILE can select a profile too. The profile's organization and server take precedence over the repository
+// files, since its credentials belong to them.
+// Other command-line flags take precedence over all layers and are applied by the caller
+// with Set. Empty values never override lower layers. repoRoot may be empty when not
+// running inside a git repository.
+func Resolve(ctx context.Context, repoRoot, profile string) (*Resolved, error) {
+	// Load ensures the global config file and directory exist
+	if _, err := Load(); err != nil {
+		return nil, err
+	}
+
+	resolved := &Resolved{Origins: map[string]Origin{}}
+
+	files := []fileLayer{
+		{configFile, SourceGlobal, nil},
+	}
+	if repoRoot != "" {
+		files = append(files, fileLayer{filepath.Join(repoRoot, RepoConfigFile), SourceRepo, repoKeys})
+
+		localFile := filepath.Join(repoRoot, RepoLocalConfigFile)
+		tracked, err := git.IsTracked(ctx, repoRoot, RepoLocalConfigFile)
+		switch {
+		case err != nil:
+			if _, statErr := os.Stat(localFile); statErr == nil {
+				resolved.Warnings = append(resolved.Warnings, fmt.Sprintf("ignoring %s: %v", localFile, err))
+			}
+		case tracked:
+			resolved.Warnings = append(resolved.Warnings, fmt.Sprintf(
+				"ignoring %s because it is committed to the repository. Remove it from git and add it to .gitignore", localFile))
+		default:
+			files = append(files, fileLayer{localFile, SourceRepoLocal, repoLocalKeys()})
+		}
+	}
+
+	var layers []layer
+	for _, file := range files {
+		values, err := readLayer(file.path, file.keys)
+		if err != nil {
+			return nil, err
+		}
+		if values == nil {
+			continue
+		}
+		resolved.Files = append(resolved.Files, file.path)
+		origin := Origin{Source: file.source, Location: file.path}
+		layers = append(layers, layer{values, func(string) Origin { return origin }})
+	}
+
+	env := map[string]interface{}{}
+	for _, key := range Keys() {
+		if value := os.Getenv(EnvVar(key)); value != "" {
+			env[key] = value
+		}
+	}
+	layers = append(layers, layer{env, func(key string) Origin {
+		return Origin{Source: SourceEnv, Location: EnvVar(key)}
+	}})
+
+	// The selected profile slots in right above the global file
+	profileOrigin := Origin{Source: SourceFlag, Location: "--profile"}
+	if profile == "" {
+		for _, l := range layers {
+			if name, ok := l.values["profile"]; ok {
+				profile, profileOrigin = fmt.Sprint(name), l.origin("profile")
+			}
+		}
+	}
+	var profileOrganization, profileServerURL string
+	if profile != "" {
+		profiles, err := LoadProfiles()
+		if err != nil {
+			return nil, err
+		}
+		p, ok := profiles[profile]
+		if !ok {
+			return nil, fmt.Errorf("profile %q not found (selected by %s)", profile, profileOrigin)
+		}
+
+		origin := Origin{Source: SourceProfile, Location: profile}
+		values, target := p.values(), map[string]interface{}{}
+		for _, key := range []string{"organization", "server_url"} {
+			if value, ok := values[key]; ok {
+				target[key] = value
+				delete(values, key)
+			}
+		}
+		profileLayer := layer{values, func(string) Origin { return origin }}
+		targetLayer := layer{target, func(string) Origin { return origin }}
+
+		// The environment is the last layer
+		layers = slices.Insert(layers, len(layers)-1, targetLayer)
+		layers = slices.Insert(layers, 1, profileLayer)
+		profileOrganization, profileServerURL = p.Organization, p.ServerURL
+	}
+
+	values := map[string]interface{}{}
+	for _, l := range layers {
+		for key, value := range l.values {
+			values[key] = value
+			resolved.Origins[key] = l.origin(key)
+		}
+	}
+	if profile != "" {
+		values["profile"] = profile
+		resolved.Origins["profile"] = profileOrigin
+	}
+
+	v := viper.New()
+	if err := v.MergeConfigMap(values); err != nil {
+		return nil, fmt.Errorf("failed to merge config: %w", err)
+	}
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	cfg.ProfileOrganization, cfg.ProfileServerURL = profileOrganization, profileServerURL
+	resolved.Config = &cfg
+
+	return resolved, nil
+}
+
+// readLayer reads the non-empty known keys of a YAML config file, only those in keys
+// unless keys is nil. It returns nil without an error if the file does not exist.
+func readLayer(path string, keys map[string]bool) (map[string]interface{}, error) {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	values := map[string]interface{}{}
+	for _, key := range Keys() {
+		if keys != nil && !keys[key] {
+			continue
+		}
+		value := v.Get(key)
+		if isEmpty(value) {
+			continue
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// isEmpty reports whether a config value should be treated as not set
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// fieldByKey returns the settable struct field tagged with key
+func fieldByKey(cfg *Config, key string) (reflect.Value, bool) {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("mapstructure") == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
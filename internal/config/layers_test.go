@@ -0,0 +1,194 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/chriskievit/dex-cli/internal/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupLayers points the global config at a temp dir and returns it with a repo root
+func setupLayers(t *testing.T, global string) string {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, ".dex-cli")
+
+	originalConfigDir := GetConfigDir()
+	t.Cleanup(func() { SetConfigDir(originalConfigDir) })
+	SetConfigDir(configDir)
+
+	require.NoError(t, os.MkdirAll(configDir, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(global), 0600))
+
+	for _, key := range Keys() {
+		t.Setenv(EnvVar(key), "")
+	}
+
+	repoRoot, _ := testhelpers.SetupTempGitRepo(t)
+	return repoRoot
+}
+
+func TestResolve_GlobalOnly(t *testing.T) {
+	repoRoot := setupLayers(t, "organization: globalorg\nproject: globalproject\n")
+
+	resolved, err := Resolve(context.Background(), repoRoot, "")
+	require.NoError(t, err)
+	assert.Equal(t, "globalorg", resolved.Organization)
+	assert.Equal(t, "globalproject", resolved.Project)
+	assert.Equal(t, SourceGlobal, resolved.Origin("organization").Source)
+	assert.Equal(t, SourceDefault, resolved.Origin("repository").Source)
+	assert.Equal(t, []string{GetConfigFile()}, resolved.Files)
+}
+
+func TestResolve_Precedence(t *testing.T) {
+	repoRoot := setupLayers(t, `organization: globalorg
+project: globalproject
+repository: globalrepo
+default_reviewer: global@example.com
+target_branch: main
+`)
+
+	repoFile := filepath.Join(repoRoot, RepoConfigFile)
+	require.NoError(t, os.WriteFile(repoFile, []byte(`project: repoproject
+repository: reporepo
+target_branch: develop
+branch_format: "{type}/{id}-{description}"
+`), 0644))
+
+	localFile := filepath.Join(repoRoot, RepoLocalConfigFile)
+	require.NoError(t, os.WriteFile(localFile, []byte(`repository: localrepo
+target_branch: ""
+`), 0644))
+
+	t.Setenv("DEX_DEFAULT_REVIEWER", "env@example.com")
+	t.Setenv("DEX_RETRY_MAX_ATTEMPTS", "7")
+
+	resolved, err := Resolve(context.Background(), repoRoot, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "globalorg", resolved.Organization)
+	assert.Equal(t, Origin{SourceGlobal, GetConfigFile()}, resolved.Origin("organization"))
+
+	assert.Equal(t, "repoproject", resolved.Project)
+	assert.Equal(t, Origin{SourceRepo, repoFile}, resolved.Origin("project"))
+
+	assert.Equal(t, "localrepo", resolved.Repository)
+	assert.Equal(t, Origin{SourceRepoLocal, localFile}, resolved.Origin("repository"))
+
+	// Empty values do not override lower layers
+	assert.Equal(t, "develop", resolved.TargetBranch)
+	assert.Equal(t, SourceRepo, resolved.Origin("target_branch").Source)
+
+	assert.Equal(t, "env@example.com", resolved.DefaultReviewer)
+	assert.Equal(t, Origin{SourceEnv, "DEX_DEFAULT_REVIEWER"}, resolved.Origin("default_reviewer"))
+
+	assert.Equal(t, 7, resolved.RetryMaxAttempts)
+	assert.Equal(t, "{type}/{id}-{description}", resolved.BranchFormat)
+
+	assert.Equal(t, []string{GetConfigFile(), repoFile, localFile}, resolved.Files)
+}
+
+func TestResolve_RepoFileTeamDefaultsOnly(t *testing.T) {
+	repoRoot := setupLayers(t, "organization: globalorg\n")
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, RepoConfigFile), []byte(`organization: evilorg
+server_url: https://attacker.example
+credential_store: file
+entra_authority: https://login.attacker.example
+profile: someone-elses-profile
+retry_max_attempts: 100
+project: repoproject
+`), 0644))
+
+	// A cloned repository must not redirect credentials to another server
+	resolved, err := Resolve(context.Background(), repoRoot, "")
+	require.NoError(t, err)
+	assert.Equal(t, "globalorg", resolved.Organization)
+	assert.Empty(t, resolved.ServerURL)
+	assert.Equal(t, SourceDefault, resolved.Origin("server_url").Source)
+	assert.Empty(t, resolved.CredentialStore)
+	assert.Empty(t, resolved.EntraAuthority)
+	assert.Empty(t, resolved.Profile)
+	assert.Zero(t, resolved.RetryMaxAttempts)
+	assert.Equal(t, "repoproject", resolved.Project)
+
+	// Neither may the local file, which can come with the clone too
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, RepoLocalConfigFile), []byte(`server_url: https://attacker.example
+profile: someone-elses-profile
+credential_store: file
+entra_client_id: attacker-app
+retry_max_attempts: 3
+`), 0644))
+	resolved, err = Resolve(context.Background(), repoRoot, "")
+	require.NoError(t, err)
+	assert.Empty(t, resolved.ServerURL)
+	assert.Empty(t, resolved.Profile)
+	assert.Empty(t, resolved.CredentialStore)
+	assert.Empty(t, resolved.EntraClientID)
+	assert.Equal(t, 3, resolved.RetryMaxAttempts)
+	assert.Empty(t, resolved.Warnings)
+}
+
+func TestResolve_CommittedLocalFile(t *testing.T) {
+	repoRoot := setupLayers(t, "project: globalproject\n")
+	localFile := filepath.Join(repoRoot, RepoLocalConfigFile)
+	require.NoError(t, os.WriteFile(localFile, []byte("project: localproject\n"), 0644))
+
+	resolved, err := Resolve(context.Background(), repoRoot, "")
+	require.NoError(t, err)
+	assert.Equal(t, "localproject", resolved.Project)
+
+	// A committed local file is the repository's, not the user's, and is skipped
+	cmd := exec.Command("git", "add", RepoLocalConfigFile)
+	cmd.Dir = repoRoot
+	require.NoError(t, cmd.Run())
+	testhelpers.CreateCommit(t, repoRoot, "Add local config")
+
+	resolved, err = Resolve(context.Background(), repoRoot, "")
+	require.NoError(t, err)
+	assert.Equal(t, "globalproject", resolved.Project)
+	assert.NotContains(t, resolved.Files, localFile)
+	require.Len(t, resolved.Warnings, 1)
+	assert.Contains(t, resolved.Warnings[0], "committed to the repository")
+}
+
+func TestResolve_NoRepoRoot(t *testing.T) {
+	setupLayers(t, "organization: globalorg\n")
+
+	resolved, err := Resolve(context.Background(), "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "globalorg", resolved.Organization)
+}
+
+func TestResolve_InvalidRepoFile(t *testing.T) {
+	repoRoot := setupLayers(t, "")
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, RepoConfigFile), []byte("project: [invalid"), 0644))
+
+	_, err := Resolve(context.Background(), repoRoot, "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), RepoConfigFile)
+}
+
+func TestResolved_Set(t *testing.T) {
+	resolved := NewResolved(&Config{Organization: "org"})
+
+	err := resolved.Set("organization", "flagorg", Origin{SourceFlag, "--org"})
+	require.NoError(t, err)
+	assert.Equal(t, "flagorg", resolved.Organization)
+	assert.Equal(t, "flag (--org)", resolved.Origin("organization").String())
+
+	assert.Error(t, resolved.Set("unknown", "value", Origin{Source: SourceFlag}))
+	assert.Error(t, resolved.Set("organization", 42, Origin{Source: SourceFlag}))
+}
+
+func TestKeys(t *testing.T) {
+	keys := Keys()
+	assert.Equal(t, "organization", keys[0])
+	assert.Contains(t, keys, "target_branch")
+	assert.Contains(t, keys, "branch_format")
+}
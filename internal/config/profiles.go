@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/spf13/viper"
+)
+
+// profilesFileName is the file in the config directory that holds named profiles
+const profilesFileName = "profiles.yaml"
+
+// profileNamePattern restricts profile names to characters that are safe as YAML
+// keys and keychain account names
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Profile holds the settings of a named profile. A selected profile is layered
+// between the global config file and the repository config files.
+type Profile struct {
+	Organization    string `mapstructure:"organization"`
+	Project         string `mapstructure:"project"`
+	Repository      string `mapstructure:"repository"`
+	DefaultReviewer string `mapstructure:"default_reviewer"`
+	ServerURL       string `mapstructure:"server_url"`
+}
+
+// values returns the non-empty profile settings keyed like the Config fields
+func (p Profile) values() map[string]interface{} {
+	values := map[string]interface{}{}
+	for key, value := range map[string]string{
+		"organization":     p.Organization,
+		"project":          p.Project,
+		"repository":       p.Repository,
+		"default_reviewer": p.DefaultReviewer,
+		"server_url":       p.ServerURL,
+	} {
+		if value != "" {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// ValidateProfileName checks that name can be used as a profile name
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use lowercase letters, numbers, '-' and '_'", name)
+	}
+	return nil
+}
+
+// GetProfilesFile returns the path of the profiles file
+func GetProfilesFile() string {
+	return filepath.Join(configDir, profilesFileName)
+}
+
+// LoadProfiles reads all named profiles. It returns an empty map if no profiles exist.
+func LoadProfiles() (map[string]Profile, error) {
+	profiles := map[string]Profile{}
+
+	path := GetProfilesFile()
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return profiles, nil
+		}
+		return nil, fmt.Errorf("failed to read profiles file: %w", err)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read profiles file: %w", err)
+	}
+
+	if err := v.UnmarshalKey("profiles", &profiles); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal profiles: %w", err)
+	}
+
+	return profiles, nil
+}
+
+// SaveProfiles writes all named profiles, replacing the profiles file
+func SaveProfiles(profiles map[string]Profile) error {
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	entries := map[string]interface{}{}
+	for name, profile := range profiles {
+		if err := ValidateProfileName(name); err != nil {
+			return err
+		}
+		entries[name] = map[string]interface{}{
+			"organization":     profile.Organization,
+			"project":          profile.Project,
+			"repository":       profile.Repository,
+			"default_reviewer": profile.DefaultReviewer,
+			"server_url":       profile.ServerURL,
+		}
+	}
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.Set("profiles", entries)
+	if err := v.WriteConfigAs(GetProfilesFile()); err != nil {
+		return fmt.Errorf("failed to write profiles: %w", err)
+	}
+
+	return nil
+}
+
+// ProfileNames returns the names of the given profiles in sorted order
+func ProfileNames(profiles map[string]Profile) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfiles_SaveAndLoad(t *testing.T) {
+	setupLayers(t, "")
+
+	profiles, err := LoadProfiles()
+	require.NoError(t, err)
+	assert.Empty(t, profiles)
+
+	err = SaveProfiles(map[string]Profile{
+		"client-a": {Organization: "orga", Project: "proja", DefaultReviewer: "lead@a.example"},
+		"client-b": {Organization: "orgb", ServerURL: "https://tfs.corp"},
+	})
+	require.NoError(t, err)
+
+	profiles, err = LoadProfiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"client-a", "client-b"}, ProfileNames(profiles))
+	assert.Equal(t, Profile{Organization: "orga", Project: "proja", DefaultReviewer: "lead@a.example"}, profiles["client-a"])
+	assert.Equal(t, "https://tfs.corp", profiles["client-b"].ServerURL)
+}
+
+func TestSaveProfiles_InvalidName(t *testing.T) {
+	setupLayers(t, "")
+
+	err := SaveProfiles(map[string]Profile{"Client A": {Organization: "orga"}})
+	assert.Error(t, err)
+}
+
+func TestValidateProfileName(t *testing.T) {
+	assert.NoError(t, ValidateProfileName("client-a"))
+	assert.NoError(t, ValidateProfileName("work_2"))
+	assert.Error(t, ValidateProfileName(""))
+	assert.Error(t, ValidateProfileName("Client"))
+	assert.Error(t, ValidateProfileName("client.a"))
+	assert.Error(t, ValidateProfileName("-client"))
+}
+
+func TestResolve_Profile(t *testing.T) {
+	repoRoot := setupLayers(t, "organization: globalorg\nproject: globalproject\nrepository: globalrepo\nprofile: client-a\n")
+	require.NoError(t, SaveProfiles(map[string]Profile{
+		"client-a": {Organization: "orga", Project: "proja"},
+		"client-b": {Organization: "orgb"},
+	}))
+
+	t.Run("profile selected in global config", func(t *testing.T) {
+		resolved, err := Resolve(context.Background(), repoRoot, "")
+		require.NoError(t, err)
+		assert.Equal(t, "client-a", resolved.Profile)
+		assert.Equal(t, SourceGlobal, resolved.Origin("profile").Source)
+		assert.Equal(t, "orga", resolved.Organization)
+		assert.Equal(t, Origin{SourceProfile, "client-a"}, resolved.Origin("organization"))
+		assert.Equal(t, "proja", resolved.Project)
+		// Values the profile does not set come from lower layers
+		assert.Equal(t, "globalrepo", resolved.Repository)
+	})
+
+	t.Run("repository files override the profile", func(t *testing.T) {
+		repoFile := filepath.Join(repoRoot, RepoConfigFile)
+		require.NoError(t, os.WriteFile(repoFile, []byte("project: repoproject\n"), 0644))
+		defer os.Remove(repoFile)
+
+		resolved, err := Resolve(context.Background(), repoRoot, "")
+		require.NoError(t, err)
+		assert.Equal(t, "orga", resolved.Organization)
+		assert.Equal(t, "repoproject", resolved.Project)
+	})
+
+	t.Run("the profile's organization and server override the local repository file", func(t *testing.T) {
+		localFile := filepath.Join(repoRoot, RepoLocalConfigFile)
+		require.NoError(t, os.WriteFile(localFile, []byte("organization: orgb\nproject: localproject\n"), 0644))
+		defer os.Remove(localFile)
+
+		resolved, err := Resolve(context.Background(), repoRoot, "")
+		require.NoError(t, err)
+		assert.Equal(t, "orga", resolved.Organization)
+		assert.Equal(t, "orga", resolved.ProfileOrganization)
+		assert.Equal(t, "localproject", resolved.Project)
+
+		// The environment still overrides it, e.g. for a one-off command
+		t.Setenv("DEX_ORGANIZATION", "orgc")
+		resolved, err = Resolve(context.Background(), repoRoot, "")
+		require.NoError(t, err)
+		assert.Equal(t, "orgc", resolved.Organization)
+		assert.Equal(t, "orga", resolved.ProfileOrganization)
+	})
+
+	t.Run("DEX_PROFILE selects a profile", func(t *testing.T) {
+		t.Setenv("DEX_PROFILE", "client-b")
+
+		resolved, err := Resolve(context.Background(), repoRoot, "")
+		require.NoError(t, err)
+		assert.Equal(t, "client-b", resolved.Profile)
+		assert.Equal(t, Origin{SourceEnv, "DEX_PROFILE"}, resolved.Origin("profile"))
+		assert.Equal(t, "orgb", resolved.Organization)
+		assert.Equal(t, "globalproject", resolved.Project)
+	})
+
+	t.Run("flag takes precedence", func(t *testing.T) {
+		t.Setenv("DEX_PROFILE", "client-a")
+
+		resolved, err := Resolve(context.Background(), repoRoot, "client-b")
+		require.NoError(t, err)
+		assert.Equal(t, "client-b", resolved.Profile)
+		assert.Equal(t, Origin{SourceFlag, "--profile"}, resolved.Origin("profile"))
+		assert.Equal(t, "orgb", resolved.Organization)
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		_, err := Resolve(context.Background(), repoRoot, "missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `profile "missing" not found`)
+	})
+}
@@ -0,0 +1,149 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// Credential is the description of a credential exchanged with a git credential
+// helper (see gitcredentials(7)). Attributes dex does not use are ignored.
+type Credential struct {
+	Protocol string
+	Host     string
+	Path     string
+	Username string
+	Password string
+}
+
+// ReadCredential parses the "key=value" lines git sends to a credential helper, up to
+// a blank line or the end of input. A "url" attribute is split into its components.
+func ReadCredential(r io.Reader) (*Credential, error) {
+	cred := &Credential{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			break
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid credential attribute %q", line)
+		}
+		switch key {
+		case "protocol":
+			cred.Protocol = value
+		case "host":
+			cred.Host = value
+		case "path":
+			cred.Path = value
+		case "username":
+			cred.Username = value
+		case "password":
+			cred.Password = value
+		case "url":
+			parsed, err := url.Parse(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid credential url %q: %w", value, err)
+			}
+			cred.Protocol = parsed.Scheme
+			cred.Host = parsed.Host
+			cred.Path = strings.TrimPrefix(parsed.Path, "/")
+			if parsed.User != nil {
+				cred.Username = parsed.User.Username()
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read credential request: %w", err)
+	}
+	return cred, nil
+}
+
+// Write sends the credential to git in the credential helper format
+func (c *Credential) Write(w io.Writer) error {
+	attributes := []struct{ key, value string }{
+		{"protocol", c.Protocol},
+		{"host", c.Host},
+		{"path", c.Path},
+		{"username", c.Username},
+		{"password", c.Password},
+	}
+	for _, a := range attributes {
+		if a.value == "" {
+			continue
+		}
+		if strings.ContainsAny(a.value, "\n\x00") {
+			return fmt.Errorf("credential %s contains an invalid character", a.key)
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", a.key, a.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetGlobalConfig sets key to value in the user's global git config, replacing all
+// existing values of key
+func SetGlobalConfig(ctx context.Context, key, value string) error {
+	cmd := command(ctx, "", "config", "--global", "--replace-all", key, value)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to set git config %s: %w: %s", key, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// AddGlobalConfig adds value to the values of key in the user's global git config
+func AddGlobalConfig(ctx context.Context, key, value string) error {
+	cmd := command(ctx, "", "config", "--global", "--add", key, value)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to add git config %s: %w: %s", key, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// UnsetGlobalConfig removes all values of key from the user's global git config.
+// It is not an error if key is not set.
+func UnsetGlobalConfig(ctx context.Context, key string) error {
+	cmd := command(ctx, "", "config", "--global", "--unset-all", key)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		// Exit code 5 means the key was not set
+		if cmd.ProcessState != nil && cmd.ProcessState.ExitCode() == 5 {
+			return nil
+		}
+		return fmt.Errorf("failed to unset git config %s: %w: %s", key, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// GetGlobalConfig returns the last value of key in the user's global git config, or an
+// empty string if it is not set
+func GetGlobalConfig(ctx context.Context, key string) (string, error) {
+	cmd := command(ctx, "", "config", "--global", "--get", key)
+	output, err := cmd.Output()
+	if err != nil {
+		if cmd.ProcessState != nil && cmd.ProcessState.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get git config %s: %w", key, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetAllGlobalConfig returns all values of key in the user's global git config in order,
+// or nil if it is not set
+func GetAllGlobalConfig(ctx context.Context, key string) ([]string, error) {
+	cmd := command(ctx, "", "config", "--global", "--get-all", key)
+	output, err := cmd.Output()
+	if err != nil {
+		if cmd.ProcessState != nil && cmd.ProcessState.ExitCode() == 1 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get git config %s: %w", key, err)
+	}
+	return strings.Split(strings.TrimSuffix(string(output), "\n"), "\n"), nil
+}
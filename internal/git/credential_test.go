@@ -0,0 +1,77 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCredential(t *testing.T) {
+	input := "protocol=https\nhost=dev.azure.com\npath=myorg/myproject/_git/myrepo\nusername=myorg\ncapability[]=authtype\n\nignored=after blank line\n"
+
+	cred, err := ReadCredential(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, &Credential{
+		Protocol: "https",
+		Host:     "dev.azure.com",
+		Path:     "myorg/myproject/_git/myrepo",
+		Username: "myorg",
+	}, cred)
+
+	cred, err = ReadCredential(strings.NewReader("url=https://me@contoso.visualstudio.com/Web/_git/app\n"))
+	require.NoError(t, err)
+	assert.Equal(t, &Credential{
+		Protocol: "https",
+		Host:     "contoso.visualstudio.com",
+		Path:     "Web/_git/app",
+		Username: "me",
+	}, cred)
+
+	_, err = ReadCredential(strings.NewReader("not an attribute\n"))
+	assert.Error(t, err)
+}
+
+func TestCredential_Write(t *testing.T) {
+	var out bytes.Buffer
+	cred := &Credential{Protocol: "https", Host: "dev.azure.com", Username: "myorg", Password: "secret"}
+	require.NoError(t, cred.Write(&out))
+	assert.Equal(t, "protocol=https\nhost=dev.azure.com\nusername=myorg\npassword=secret\n", out.String())
+
+	cred.Password = "multi\nline"
+	assert.Error(t, cred.Write(&out))
+}
+
+func TestGlobalConfig(t *testing.T) {
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(t.TempDir(), "gitconfig"))
+	ctx := context.Background()
+	key := "credential.https://dev.azure.com.helper"
+
+	value, err := GetGlobalConfig(ctx, key)
+	require.NoError(t, err)
+	assert.Empty(t, value)
+	require.NoError(t, UnsetGlobalConfig(ctx, key))
+
+	require.NoError(t, SetGlobalConfig(ctx, key, "first"))
+	require.NoError(t, SetGlobalConfig(ctx, key, "!'/usr/bin/dex' auth git-credential"))
+	value, err = GetGlobalConfig(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, "!'/usr/bin/dex' auth git-credential", value)
+
+	require.NoError(t, AddGlobalConfig(ctx, key, "second"))
+	values, err := GetAllGlobalConfig(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"!'/usr/bin/dex' auth git-credential", "second"}, values)
+
+	require.NoError(t, UnsetGlobalConfig(ctx, key))
+	value, err = GetGlobalConfig(ctx, key)
+	require.NoError(t, err)
+	assert.Empty(t, value)
+	values, err = GetAllGlobalConfig(ctx, key)
+	require.NoError(t, err)
+	assert.Nil(t, values)
+}
@@ -1,23 +1,44 @@
 package git
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
+	"time"
 )
 
-// IsGitRepository checks if the current directory is a git repository
-func IsGitRepository(dir string) bool {
-	cmd := exec.Command("git", "rev-parse", "--git-dir")
+// interruptGracePeriod is how long git gets to exit after being interrupted
+// before it is killed
+const interruptGracePeriod = 5 * time.Second
+
+// command creates a git command in dir. When ctx is cancelled the process is
+// interrupted first, like pressing Ctrl-C, and only killed if it does not exit in time.
+func command(ctx context.Context, dir string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = dir
+	cmd.Cancel = func() error {
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			// Interrupts are not supported on every platform (e.g. Windows)
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = interruptGracePeriod
+	return cmd
+}
+
+// IsGitRepository checks if the current directory is a git repository
+func IsGitRepository(ctx context.Context, dir string) bool {
+	cmd := command(ctx, dir, "rev-parse", "--git-dir")
 	err := cmd.Run()
 	return err == nil
 }
 
 // GetCurrentBranch returns the name of the current branch
-func GetCurrentBranch(dir string) (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	cmd.Dir = dir
+func GetCurrentBranch(ctx context.Context, dir string) (string, error) {
+	cmd := command(ctx, dir, "rev-parse", "--abbrev-ref", "HEAD")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get current branch: %w", err)
@@ -26,10 +47,9 @@ func GetCurrentBranch(dir string) (string, error) {
 }
 
 // GetDefaultBranch returns the default branch (main or master)
-func GetDefaultBranch(dir string) (string, error) {
+func GetDefaultBranch(ctx context.Context, dir string) (string, error) {
 	// Try to get the default branch from remote
-	cmd := exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD")
-	cmd.Dir = dir
+	cmd := command(ctx, dir, "symbolic-ref", "refs/remotes/origin/HEAD")
 	output, err := cmd.Output()
 	if err == nil {
 		// Extract branch name from refs/remotes/origin/main
@@ -41,34 +61,33 @@ func GetDefaultBranch(dir string) (string, error) {
 	}
 
 	// Fallback: check if main exists
-	cmd = exec.Command("git", "rev-parse", "--verify", "main")
-	cmd.Dir = dir
+	cmd = command(ctx, dir, "rev-parse", "--verify", "main")
 	if err := cmd.Run(); err == nil {
 		return "main", nil
 	}
 
 	// Fallback: check if master exists
-	cmd = exec.Command("git", "rev-parse", "--verify", "master")
-	cmd.Dir = dir
+	cmd = command(ctx, dir, "rev-parse", "--verify", "master")
 	if err := cmd.Run(); err == nil {
 		return "master", nil
 	}
 
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
 	return "", fmt.Errorf("could not determine default branch")
 }
 
 // CreateBranch creates a new branch from the specified base branch
-func CreateBranch(dir, branchName, baseBranch string) error {
+func CreateBranch(ctx context.Context, dir, branchName, baseBranch string) error {
 	// First, ensure we're on the base branch or it exists
-	cmd := exec.Command("git", "checkout", baseBranch)
-	cmd.Dir = dir
+	cmd := command(ctx, dir, "checkout", baseBranch)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to checkout base branch %s: %w", baseBranch, err)
 	}
 
 	// Create and checkout the new branch
-	cmd = exec.Command("git", "checkout", "-b", branchName)
-	cmd.Dir = dir
+	cmd = command(ctx, dir, "checkout", "-b", branchName)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to create branch %s: %w", branchName, err)
 	}
@@ -77,9 +96,8 @@ func CreateBranch(dir, branchName, baseBranch string) error {
 }
 
 // GetRemoteURL returns the remote URL for origin
-func GetRemoteURL(dir string) (string, error) {
-	cmd := exec.Command("git", "config", "--get", "remote.origin.url")
-	cmd.Dir = dir
+func GetRemoteURL(ctx context.Context, dir string) (string, error) {
+	cmd := command(ctx, dir, "config", "--get", "remote.origin.url")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get remote URL: %w", err)
@@ -88,11 +106,13 @@ func GetRemoteURL(dir string) (string, error) {
 }
 
 // BranchExists checks if a branch exists locally
-func BranchExists(dir, branchName string) (bool, error) {
-	cmd := exec.Command("git", "rev-parse", "--verify", branchName)
-	cmd.Dir = dir
+func BranchExists(ctx context.Context, dir, branchName string) (bool, error) {
+	cmd := command(ctx, dir, "rev-parse", "--verify", branchName)
 	err := cmd.Run()
 	if err != nil {
+		if ctx.Err() != nil {
+			return false, fmt.Errorf("failed to check if branch exists: %w", ctx.Err())
+		}
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			// Exit codes 1 and 128 both indicate the branch doesn't exist
 			exitCode := exitErr.ExitCode()
@@ -106,9 +126,8 @@ func BranchExists(dir, branchName string) (bool, error) {
 }
 
 // CheckoutBranch checks out an existing branch
-func CheckoutBranch(dir, branchName string) error {
-	cmd := exec.Command("git", "checkout", branchName)
-	cmd.Dir = dir
+func CheckoutBranch(ctx context.Context, dir, branchName string) error {
+	cmd := command(ctx, dir, "checkout", branchName)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to checkout branch %s: %w", branchName, err)
 	}
@@ -116,9 +135,8 @@ func CheckoutBranch(dir, branchName string) error {
 }
 
 // PushBranch pushes a branch to the remote
-func PushBranch(dir, branchName string) error {
-	cmd := exec.Command("git", "push", "-u", "origin", branchName)
-	cmd.Dir = dir
+func PushBranch(ctx context.Context, dir, branchName string) error {
+	cmd := command(ctx, dir, "push", "-u", "origin", branchName)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to push branch %s: %w", branchName, err)
 	}
@@ -126,11 +144,130 @@ func PushBranch(dir, branchName string) error {
 }
 
 // CreateCommit creates a commit with the given message
-func CreateCommit(dir, message string) error {
-	cmd := exec.Command("git", "commit", "--allow-empty", "-m", message)
-	cmd.Dir = dir
+func CreateCommit(ctx context.Context, dir, message string) error {
+	cmd := command(ctx, dir, "commit", "--allow-empty", "-m", message)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to create commit: %w", err)
 	}
 	return nil
 }
+
+// GetRepositoryRoot returns the top-level directory of the working tree containing dir
+func GetRepositoryRoot(ctx context.Context, dir string) (string, error) {
+	cmd := command(ctx, dir, "rev-parse", "--show-toplevel")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get repository root: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// HasUncommittedChanges reports whether tracked files in the working tree or index of
+// dir differ from HEAD. Untracked files are ignored.
+func HasUncommittedChanges(ctx context.Context, dir string) (bool, error) {
+	cmd := command(ctx, dir, "status", "--porcelain", "--untracked-files=no")
+	output, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to get working tree status: %w", err)
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// IsTracked reports whether path, relative to dir, is tracked by git
+func IsTracked(ctx context.Context, dir, path string) (bool, error) {
+	cmd := command(ctx, dir, "ls-files", "--error-unmatch", "--", path)
+	err := cmd.Run()
+	if err == nil {
+		return true, nil
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 && ctx.Err() == nil {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to check whether %s is tracked: %w", path, err)
+}
+
+// Fetch fetches refspecs from remote. Git's progress and errors go to stderr.
+func Fetch(ctx context.Context, dir, remote string, refspecs ...string) error {
+	cmd := command(ctx, dir, append([]string{"fetch", remote}, refspecs...)...)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to fetch from %s: %w", remote, err)
+	}
+	return nil
+}
+
+// CreateTrackingBranch creates and checks out branchName at startPoint. With track set,
+// startPoint must be a remote-tracking branch, which becomes the branch's upstream.
+func CreateTrackingBranch(ctx context.Context, dir, branchName, startPoint string, track bool) error {
+	trackFlag := "--no-track"
+	if track {
+		trackFlag = "--track"
+	}
+	cmd := command(ctx, dir, "checkout", trackFlag, "-b", branchName, startPoint)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", branchName, err)
+	}
+	return nil
+}
+
+// ResetBranch checks out branchName, creating it or moving it to startPoint, without
+// tracking. Commits only on the old branchName are no longer reachable from it.
+func ResetBranch(ctx context.Context, dir, branchName, startPoint string) error {
+	cmd := command(ctx, dir, "checkout", "--no-track", "-B", branchName, startPoint)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to reset branch %s to %s: %w", branchName, startPoint, err)
+	}
+	return nil
+}
+
+// IsAncestor reports whether ancestor is reachable from descendant, i.e. whether
+// descendant is a fast-forward of ancestor
+func IsAncestor(ctx context.Context, dir, ancestor, descendant string) (bool, error) {
+	cmd := command(ctx, dir, "merge-base", "--is-ancestor", ancestor, descendant)
+	err := cmd.Run()
+	if err == nil {
+		return true, nil
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 && ctx.Err() == nil {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to compare %s with %s: %w", ancestor, descendant, err)
+}
+
+// FastForward advances the current branch to ref, failing if that is not a fast-forward
+func FastForward(ctx context.Context, dir, ref string) error {
+	cmd := command(ctx, dir, "merge", "--ff-only", ref)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to fast-forward to %s: %w", ref, err)
+	}
+	return nil
+}
+
+// Editor returns the editor git uses for messages in dir, as configured by GIT_EDITOR,
+// core.editor, VISUAL or EDITOR, falling back to git's default editor
+func Editor(ctx context.Context, dir string) (string, error) {
+	cmd := command(ctx, dir, "var", "GIT_EDITOR")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine editor: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CommitSubjects returns the subjects of the commits reachable from head but not from
+// base, oldest first
+func CommitSubjects(ctx context.Context, dir, base, head string) ([]string, error) {
+	cmd := command(ctx, dir, "log", "--reverse", "--format=%s", base+".."+head)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits from %s to %s: %w", base, head, err)
+	}
+
+	var subjects []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			subjects = append(subjects, line)
+		}
+	}
+	return subjects, nil
+}
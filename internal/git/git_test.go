@@ -1,7 +1,10 @@
 package git
 
 import (
+	"context"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/chriskievit/dex-cli/internal/testhelpers"
@@ -42,7 +45,7 @@ func TestIsGitRepository(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			dir := tt.setup(t)
-			result := IsGitRepository(dir)
+			result := IsGitRepository(context.Background(), dir)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -52,7 +55,7 @@ func TestGetCurrentBranch(t *testing.T) {
 	repoDir, cleanup := testhelpers.SetupTempGitRepo(t)
 	defer cleanup()
 
-	branch, err := GetCurrentBranch(repoDir)
+	branch, err := GetCurrentBranch(context.Background(), repoDir)
 	require.NoError(t, err)
 	// Git version determines default branch name (main or master)
 	assert.Contains(t, []string{"main", "master"}, branch)
@@ -61,7 +64,7 @@ func TestGetCurrentBranch(t *testing.T) {
 func TestGetCurrentBranch_NotGitRepo(t *testing.T) {
 	tmpDir := t.TempDir()
 
-	_, err := GetCurrentBranch(tmpDir)
+	_, err := GetCurrentBranch(context.Background(), tmpDir)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to get current branch")
 }
@@ -73,7 +76,7 @@ func TestGetDefaultBranch_Main(t *testing.T) {
 	// Get current branch (could be main or master)
 	currentBranch := testhelpers.GetCurrentBranch(t, repoDir)
 
-	branch, err := GetDefaultBranch(repoDir)
+	branch, err := GetDefaultBranch(context.Background(), repoDir)
 	require.NoError(t, err)
 	// Should return the current default branch
 	assert.Contains(t, []string{"main", "master"}, branch)
@@ -101,7 +104,7 @@ func TestGetDefaultBranch_Master(t *testing.T) {
 	cmd.Dir = repoDir
 	require.NoError(t, cmd.Run())
 
-	branch, err := GetDefaultBranch(repoDir)
+	branch, err := GetDefaultBranch(context.Background(), repoDir)
 	require.NoError(t, err)
 	assert.Equal(t, "master", branch)
 }
@@ -124,7 +127,7 @@ func TestGetDefaultBranch_NoDefaultBranch(t *testing.T) {
 	cmd.Run() // Ignore error if already deleted
 
 	// Try to get default branch - should fail
-	_, err := GetDefaultBranch(repoDir)
+	_, err := GetDefaultBranch(context.Background(), repoDir)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "could not determine default branch")
 }
@@ -137,7 +140,7 @@ func TestCreateBranch(t *testing.T) {
 	currentBranch := testhelpers.GetCurrentBranch(t, repoDir)
 
 	branchName := "feature/test-branch"
-	err := CreateBranch(repoDir, branchName, currentBranch)
+	err := CreateBranch(context.Background(), repoDir, branchName, currentBranch)
 	require.NoError(t, err)
 
 	// Verify branch was created
@@ -152,7 +155,7 @@ func TestCreateBranch_InvalidBaseBranch(t *testing.T) {
 	defer cleanup()
 
 	branchName := "feature/test-branch"
-	err := CreateBranch(repoDir, branchName, "non-existent-base")
+	err := CreateBranch(context.Background(), repoDir, branchName, "non-existent-base")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to checkout base branch")
 }
@@ -188,7 +191,7 @@ func TestBranchExists(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			exists, err := BranchExists(repoDir, tt.branch)
+			exists, err := BranchExists(context.Background(), repoDir, tt.branch)
 			require.NoError(t, err)
 			assert.Equal(t, tt.expected, exists)
 		})
@@ -200,7 +203,7 @@ func TestBranchExists_NotGitRepo(t *testing.T) {
 
 	// BranchExists may return false without error for non-git directories
 	// depending on how git rev-parse behaves
-	exists, err := BranchExists(tmpDir, "main")
+	exists, err := BranchExists(context.Background(), tmpDir, "main")
 	// Either should return false, or an error
 	if err != nil {
 		assert.Contains(t, err.Error(), "failed to check if branch exists")
@@ -217,7 +220,7 @@ func TestCheckoutBranch(t *testing.T) {
 	testhelpers.CreateBranch(t, repoDir, "test-branch")
 
 	// Checkout the branch
-	err := CheckoutBranch(repoDir, "test-branch")
+	err := CheckoutBranch(context.Background(), repoDir, "test-branch")
 	require.NoError(t, err)
 
 	// Verify we're on the branch
@@ -229,7 +232,7 @@ func TestCheckoutBranch_NonExistentBranch(t *testing.T) {
 	repoDir, cleanup := testhelpers.SetupTempGitRepo(t)
 	defer cleanup()
 
-	err := CheckoutBranch(repoDir, "non-existent-branch")
+	err := CheckoutBranch(context.Background(), repoDir, "non-existent-branch")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to checkout branch")
 }
@@ -239,7 +242,7 @@ func TestCreateCommit(t *testing.T) {
 	defer cleanup()
 
 	message := "Test commit message"
-	err := CreateCommit(repoDir, message)
+	err := CreateCommit(context.Background(), repoDir, message)
 	require.NoError(t, err)
 
 	// Verify commit was created
@@ -253,7 +256,7 @@ func TestCreateCommit(t *testing.T) {
 func TestCreateCommit_NotGitRepo(t *testing.T) {
 	tmpDir := t.TempDir()
 
-	err := CreateCommit(tmpDir, "Test message")
+	err := CreateCommit(context.Background(), tmpDir, "Test message")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to create commit")
 }
@@ -267,7 +270,7 @@ func TestGetRemoteURL(t *testing.T) {
 	cmd.Dir = repoDir
 	require.NoError(t, cmd.Run())
 
-	url, err := GetRemoteURL(repoDir)
+	url, err := GetRemoteURL(context.Background(), repoDir)
 	require.NoError(t, err)
 	assert.Equal(t, "https://github.com/test/repo.git", url)
 }
@@ -276,7 +279,7 @@ func TestGetRemoteURL_NoRemote(t *testing.T) {
 	repoDir, cleanup := testhelpers.SetupTempGitRepo(t)
 	defer cleanup()
 
-	_, err := GetRemoteURL(repoDir)
+	_, err := GetRemoteURL(context.Background(), repoDir)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to get remote URL")
 }
@@ -291,7 +294,7 @@ func TestPushBranch(t *testing.T) {
 	testhelpers.CreateBranch(t, repoDir, "test-branch")
 
 	// Push should fail without a remote
-	err := PushBranch(repoDir, "test-branch")
+	err := PushBranch(context.Background(), repoDir, "test-branch")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to push branch")
 }
@@ -305,7 +308,7 @@ func TestCreateBranch_FromCurrentBranch(t *testing.T) {
 
 	// Create another branch from the feature branch
 	branchName := "feature/child"
-	err := CreateBranch(repoDir, branchName, "feature/base")
+	err := CreateBranch(context.Background(), repoDir, branchName, "feature/base")
 	require.NoError(t, err)
 
 	// Verify branch was created
@@ -338,7 +341,170 @@ func TestGetDefaultBranch_RemoteHEAD(t *testing.T) {
 	cmd.Run() // May fail, that's okay - we'll fall back to main/master check
 
 	// Should still work with fallback
-	branch, err := GetDefaultBranch(repoDir)
+	branch, err := GetDefaultBranch(context.Background(), repoDir)
 	require.NoError(t, err)
 	assert.Contains(t, []string{"main", "master"}, branch)
 }
+
+func TestCommand_CancelledContext(t *testing.T) {
+	repoDir, cleanup := testhelpers.SetupTempGitRepo(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := CreateCommit(ctx, repoDir, "should not be created")
+	assert.Error(t, err)
+
+	_, err = GetCurrentBranch(ctx, repoDir)
+	assert.Error(t, err)
+}
+
+func TestGetRepositoryRoot(t *testing.T) {
+	repoDir, cleanup := testhelpers.SetupTempGitRepo(t)
+	defer cleanup()
+
+	subDir := filepath.Join(repoDir, "sub", "dir")
+	require.NoError(t, os.MkdirAll(subDir, 0755))
+
+	root, err := GetRepositoryRoot(context.Background(), subDir)
+	require.NoError(t, err)
+
+	// Resolve symlinks (e.g. /tmp on macOS) before comparing
+	expected, err := filepath.EvalSymlinks(repoDir)
+	require.NoError(t, err)
+	actual, err := filepath.EvalSymlinks(root)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestGetRepositoryRoot_NotGitRepo(t *testing.T) {
+	_, err := GetRepositoryRoot(context.Background(), t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestHasUncommittedChanges(t *testing.T) {
+	repoDir, cleanup := testhelpers.SetupTempGitRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	dirty, err := HasUncommittedChanges(ctx, repoDir)
+	require.NoError(t, err)
+	assert.False(t, dirty)
+
+	// Untracked files are not at risk when switching branches
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "notes.txt"), []byte("notes"), 0644))
+	dirty, err = HasUncommittedChanges(ctx, repoDir)
+	require.NoError(t, err)
+	assert.False(t, dirty)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("changed"), 0644))
+	dirty, err = HasUncommittedChanges(ctx, repoDir)
+	require.NoError(t, err)
+	assert.True(t, dirty)
+}
+
+func TestFetchAndFastForward(t *testing.T) {
+	originDir, cleanup := testhelpers.SetupTempGitRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	cloneDir := filepath.Join(t.TempDir(), "clone")
+	cmd := exec.Command("git", "clone", "--quiet", originDir, cloneDir)
+	require.NoError(t, cmd.Run())
+	for _, setting := range [][]string{{"user.name", "Test User"}, {"user.email", "test@example.com"}} {
+		cmd = exec.Command("git", "config", setting[0], setting[1])
+		cmd.Dir = cloneDir
+		require.NoError(t, cmd.Run())
+	}
+
+	testhelpers.CreateBranch(t, originDir, "feature")
+	testhelpers.CreateCommit(t, originDir, "Add feature")
+
+	require.NoError(t, Fetch(ctx, cloneDir, "origin", "+refs/heads/feature:refs/remotes/origin/feature"))
+	require.NoError(t, CreateTrackingBranch(ctx, cloneDir, "feature", "origin/feature", true))
+	assert.Equal(t, "feature", testhelpers.GetCurrentBranch(t, cloneDir))
+
+	testhelpers.CreateCommit(t, originDir, "Improve feature")
+	require.NoError(t, Fetch(ctx, cloneDir, "origin", "+refs/heads/feature:refs/remotes/origin/feature"))
+	require.NoError(t, FastForward(ctx, cloneDir, "origin/feature"))
+
+	testhelpers.CreateCommit(t, cloneDir, "Local change")
+	testhelpers.CreateCommit(t, originDir, "Remote change")
+	require.NoError(t, Fetch(ctx, cloneDir, "origin", "+refs/heads/feature:refs/remotes/origin/feature"))
+	assert.Error(t, FastForward(ctx, cloneDir, "origin/feature"))
+
+	assert.Error(t, Fetch(ctx, cloneDir, "origin", "refs/heads/missing:refs/remotes/origin/missing"))
+}
+
+func TestIsAncestorAndResetBranch(t *testing.T) {
+	repoDir, cleanup := testhelpers.SetupTempGitRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	testhelpers.CreateBranch(t, repoDir, "feature")
+	testhelpers.CreateCommit(t, repoDir, "Add feature")
+
+	ancestor, err := IsAncestor(ctx, repoDir, "main", "feature")
+	require.NoError(t, err)
+	assert.True(t, ancestor)
+	ancestor, err = IsAncestor(ctx, repoDir, "feature", "main")
+	require.NoError(t, err)
+	assert.False(t, ancestor)
+	_, err = IsAncestor(ctx, repoDir, "missing", "main")
+	assert.Error(t, err)
+
+	// The current branch is moved back, discarding its commit
+	require.NoError(t, ResetBranch(ctx, repoDir, "feature", "main"))
+	assert.Equal(t, "feature", testhelpers.GetCurrentBranch(t, repoDir))
+	ancestor, err = IsAncestor(ctx, repoDir, "feature", "main")
+	require.NoError(t, err)
+	assert.True(t, ancestor)
+
+	require.NoError(t, ResetBranch(ctx, repoDir, "other", "main"))
+	assert.Equal(t, "other", testhelpers.GetCurrentBranch(t, repoDir))
+	assert.Error(t, ResetBranch(ctx, repoDir, "other", "missing"))
+}
+
+func TestEditor(t *testing.T) {
+	repoDir, cleanup := testhelpers.SetupTempGitRepo(t)
+	defer cleanup()
+	// Git takes an empty variable as the editor, so unset them (t.Setenv restores them)
+	for _, name := range []string{"GIT_EDITOR", "VISUAL", "EDITOR"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+
+	cmd := exec.Command("git", "config", "core.editor", "code --wait")
+	cmd.Dir = repoDir
+	require.NoError(t, cmd.Run())
+
+	editor, err := Editor(context.Background(), repoDir)
+	require.NoError(t, err)
+	assert.Equal(t, "code --wait", editor)
+
+	t.Setenv("GIT_EDITOR", "vim")
+	editor, err = Editor(context.Background(), repoDir)
+	require.NoError(t, err)
+	assert.Equal(t, "vim", editor)
+}
+
+func TestCommitSubjects(t *testing.T) {
+	repoDir, cleanup := testhelpers.SetupTempGitRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	testhelpers.CreateBranch(t, repoDir, "feature")
+	subjects, err := CommitSubjects(ctx, repoDir, "main", "feature")
+	require.NoError(t, err)
+	assert.Empty(t, subjects)
+
+	testhelpers.CreateCommit(t, repoDir, "Add login form")
+	testhelpers.CreateCommit(t, repoDir, "Validate the password")
+	subjects, err = CommitSubjects(ctx, repoDir, "main", "feature")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Add login form", "Validate the password"}, subjects)
+
+	_, err = CommitSubjects(ctx, repoDir, "main", "missing")
+	assert.Error(t, err)
+}
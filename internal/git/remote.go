@@ -0,0 +1,189 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// azureDevOpsServicesURL is the server URL used for dev.azure.com and visualstudio.com remotes
+const azureDevOpsServicesURL = "https://dev.azure.com"
+
+// RemoteInfo identifies an Azure DevOps repository
+type RemoteInfo struct {
+	// ServerURL is the base URL organizations (or collections) live under
+	ServerURL    string
+	Organization string
+	Project      string
+	Repository   string
+}
+
+// ParseAzureDevOpsRemote extracts the organization, project and repository from an
+// Azure DevOps remote URL. Supported formats:
+//
+//	https://dev.azure.com/{org}/{project}/_git/{repo}
+//	https://{user}@dev.azure.com/{org}/{project}/_git/{repo}
+//	git@ssh.dev.azure.com:v3/{org}/{project}/{repo}
+//	https://{org}.visualstudio.com/[DefaultCollection/]{project}/_git/{repo}
+//	{org}@vs-ssh.visualstudio.com:v3/{org}/{project}/{repo}
+//	http(s)://{server}[:{port}]/[{path}/]{collection}/{project}/_git/{repo} (Azure DevOps Server)
+//	ssh://{server}[:{port}]/[{path}/]{collection}/{project}/_git/{repo} (Azure DevOps Server)
+func ParseAzureDevOpsRemote(remoteURL string) (*RemoteInfo, error) {
+	remoteURL = strings.TrimSpace(remoteURL)
+	if remoteURL == "" {
+		return nil, fmt.Errorf("remote URL is empty")
+	}
+
+	scheme, host, path, ok := splitRemote(remoteURL)
+	if !ok {
+		return nil, fmt.Errorf("unrecognized remote URL: %s", remoteURL)
+	}
+
+	segments, err := pathSegments(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote URL %s: %w", remoteURL, err)
+	}
+
+	host = strings.ToLower(host)
+	hostname := host
+	if idx := strings.LastIndex(hostname, ":"); idx != -1 {
+		hostname = hostname[:idx]
+	}
+
+	var info *RemoteInfo
+	switch {
+	case hostname == "ssh.dev.azure.com" || hostname == "vs-ssh.visualstudio.com":
+		// v3/{org}/{project}/{repo}
+		if len(segments) == 4 && segments[0] == "v3" {
+			info = &RemoteInfo{
+				ServerURL:    azureDevOpsServicesURL,
+				Organization: segments[1],
+				Project:      segments[2],
+				Repository:   segments[3],
+			}
+		}
+	case hostname == "dev.azure.com":
+		// {org}/{project}/_git/{repo}
+		if before, repo, ok := splitGitPath(segments); ok && (len(before) == 1 || len(before) == 2) {
+			info = &RemoteInfo{
+				ServerURL:    azureDevOpsServicesURL,
+				Organization: before[0],
+				Project:      projectOrRepo(before[1:], repo),
+				Repository:   repo,
+			}
+		}
+	case strings.HasSuffix(hostname, ".visualstudio.com"):
+		// [DefaultCollection/]{project}/_git/{repo}
+		if before, repo, ok := splitGitPath(segments); ok && len(before) <= 2 {
+			if len(before) == 2 {
+				before = before[1:]
+			}
+			info = &RemoteInfo{
+				ServerURL:    azureDevOpsServicesURL,
+				Organization: strings.TrimSuffix(hostname, ".visualstudio.com"),
+				Project:      projectOrRepo(before, repo),
+				Repository:   repo,
+			}
+		}
+	default:
+		// Azure DevOps Server: [{path}/]{collection}/{project}/_git/{repo}
+		if before, repo, ok := splitGitPath(segments); ok && len(before) >= 1 {
+			collection, project := before[0], []string(nil)
+			var serverPath []string
+			if len(before) >= 2 {
+				serverPath = before[:len(before)-2]
+				collection, project = before[len(before)-2], before[len(before)-1:]
+			}
+
+			// The web interface of an SSH remote is served over HTTPS on the default port
+			serverURL := "https://" + hostname
+			if scheme == "http" || scheme == "https" {
+				serverURL = scheme + "://" + host
+			}
+			if len(serverPath) > 0 {
+				serverURL += "/" + strings.Join(serverPath, "/")
+			}
+			info = &RemoteInfo{
+				ServerURL:    serverURL,
+				Organization: collection,
+				Project:      projectOrRepo(project, repo),
+				Repository:   repo,
+			}
+		}
+	}
+
+	if info == nil {
+		return nil, fmt.Errorf("not an Azure DevOps remote URL: %s", remoteURL)
+	}
+	return info, nil
+}
+
+// GetAzureDevOpsRemote returns the Azure DevOps repository the origin remote points to
+func GetAzureDevOpsRemote(ctx context.Context, dir string) (*RemoteInfo, error) {
+	remoteURL, err := GetRemoteURL(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	return ParseAzureDevOpsRemote(remoteURL)
+}
+
+// splitRemote splits a URL or scp-like remote (user@host:path) into its lower-cased
+// scheme, host and path. scp-like remotes have the scheme "ssh".
+func splitRemote(remoteURL string) (string, string, string, bool) {
+	if strings.Contains(remoteURL, "://") {
+		parsed, err := url.Parse(remoteURL)
+		if err != nil || parsed.Host == "" {
+			return "", "", "", false
+		}
+		return strings.ToLower(parsed.Scheme), parsed.Host, parsed.EscapedPath(), true
+	}
+
+	// scp-like syntax: [user@]host:path
+	colon := strings.Index(remoteURL, ":")
+	if colon == -1 {
+		return "", "", "", false
+	}
+	host := remoteURL[:colon]
+	if at := strings.LastIndex(host, "@"); at != -1 {
+		host = host[at+1:]
+	}
+	return "ssh", host, remoteURL[colon+1:], host != ""
+}
+
+// pathSegments splits a URL path into unescaped, non-empty segments
+func pathSegments(path string) ([]string, error) {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, unescaped)
+	}
+	if n := len(segments); n > 0 {
+		segments[n-1] = strings.TrimSuffix(segments[n-1], ".git")
+	}
+	return segments, nil
+}
+
+// splitGitPath splits {before...}/_git/{repo} into the segments before "_git" and the repository name
+func splitGitPath(segments []string) ([]string, string, bool) {
+	n := len(segments)
+	if n < 2 || segments[n-2] != "_git" {
+		return nil, "", false
+	}
+	return segments[:n-2], segments[n-1], true
+}
+
+// projectOrRepo returns the single project segment, or the repository name when the
+// project was omitted from the URL (Azure DevOps allows this when both names match)
+func projectOrRepo(project []string, repo string) string {
+	if len(project) == 1 {
+		return project[0]
+	}
+	return repo
+}
@@ -0,0 +1,140 @@
+package git
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/chriskievit/dex-cli/internal/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAzureDevOpsRemote(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected *RemoteInfo
+	}{
+		{
+			name:     "dev.azure.com HTTPS",
+			url:      "https://dev.azure.com/myorg/myproject/_git/myrepo",
+			expected: &RemoteInfo{"https://dev.azure.com", "myorg", "myproject", "myrepo"},
+		},
+		{
+			name:     "dev.azure.com HTTPS with user",
+			url:      "https://myorg@dev.azure.com/myorg/myproject/_git/myrepo",
+			expected: &RemoteInfo{"https://dev.azure.com", "myorg", "myproject", "myrepo"},
+		},
+		{
+			name:     "dev.azure.com HTTPS with encoded project name",
+			url:      "https://dev.azure.com/myorg/My%20Project/_git/My%20Repo",
+			expected: &RemoteInfo{"https://dev.azure.com", "myorg", "My Project", "My Repo"},
+		},
+		{
+			name:     "dev.azure.com HTTPS without project",
+			url:      "https://dev.azure.com/myorg/_git/myrepo",
+			expected: &RemoteInfo{"https://dev.azure.com", "myorg", "myrepo", "myrepo"},
+		},
+		{
+			name:     "dev.azure.com SSH",
+			url:      "git@ssh.dev.azure.com:v3/myorg/myproject/myrepo",
+			expected: &RemoteInfo{"https://dev.azure.com", "myorg", "myproject", "myrepo"},
+		},
+		{
+			name:     "dev.azure.com SSH URL syntax",
+			url:      "ssh://git@ssh.dev.azure.com/v3/myorg/myproject/myrepo",
+			expected: &RemoteInfo{"https://dev.azure.com", "myorg", "myproject", "myrepo"},
+		},
+		{
+			name:     "legacy visualstudio.com",
+			url:      "https://myorg.visualstudio.com/myproject/_git/myrepo",
+			expected: &RemoteInfo{"https://dev.azure.com", "myorg", "myproject", "myrepo"},
+		},
+		{
+			name:     "legacy visualstudio.com with DefaultCollection",
+			url:      "https://myorg.visualstudio.com/DefaultCollection/myproject/_git/myrepo",
+			expected: &RemoteInfo{"https://dev.azure.com", "myorg", "myproject", "myrepo"},
+		},
+		{
+			name:     "legacy visualstudio.com SSH",
+			url:      "myorg@vs-ssh.visualstudio.com:v3/myorg/myproject/myrepo",
+			expected: &RemoteInfo{"https://dev.azure.com", "myorg", "myproject", "myrepo"},
+		},
+		{
+			name:     "Azure DevOps Server",
+			url:      "https://tfs.corp/DefaultCollection/myproject/_git/myrepo",
+			expected: &RemoteInfo{"https://tfs.corp", "DefaultCollection", "myproject", "myrepo"},
+		},
+		{
+			name:     "Azure DevOps Server with virtual directory",
+			url:      "https://tfs.corp/tfs/DefaultCollection/myproject/_git/myrepo",
+			expected: &RemoteInfo{"https://tfs.corp/tfs", "DefaultCollection", "myproject", "myrepo"},
+		},
+		{
+			name:     "Azure DevOps Server on another port",
+			url:      "https://tfs.corp:8080/tfs/DefaultCollection/myproject/_git/myrepo",
+			expected: &RemoteInfo{"https://tfs.corp:8080/tfs", "DefaultCollection", "myproject", "myrepo"},
+		},
+		{
+			name:     "Azure DevOps Server over HTTP",
+			url:      "http://tfs.corp/DefaultCollection/myproject/_git/myrepo",
+			expected: &RemoteInfo{"http://tfs.corp", "DefaultCollection", "myproject", "myrepo"},
+		},
+		{
+			name:     "Azure DevOps Server SSH",
+			url:      "ssh://tfs.corp:22/DefaultCollection/myproject/_git/myrepo",
+			expected: &RemoteInfo{"https://tfs.corp", "DefaultCollection", "myproject", "myrepo"},
+		},
+		{
+			name:     "trailing .git suffix",
+			url:      "https://dev.azure.com/myorg/myproject/_git/myrepo.git",
+			expected: &RemoteInfo{"https://dev.azure.com", "myorg", "myproject", "myrepo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ParseAzureDevOpsRemote(tt.url)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, info)
+		})
+	}
+}
+
+func TestParseAzureDevOpsRemote_Invalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"https://github.com/owner/repo.git",
+		"git@github.com:owner/repo.git",
+		"https://dev.azure.com/myorg",
+		"git@ssh.dev.azure.com:v3/myorg/myproject",
+		"/local/path/to/repo",
+	}
+
+	for _, url := range invalid {
+		t.Run(url, func(t *testing.T) {
+			_, err := ParseAzureDevOpsRemote(url)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestGetAzureDevOpsRemote(t *testing.T) {
+	repoDir, cleanup := testhelpers.SetupTempGitRepo(t)
+	defer cleanup()
+
+	// No origin remote yet
+	_, err := GetAzureDevOpsRemote(context.Background(), repoDir)
+	assert.Error(t, err)
+
+	cmd := exec.Command("git", "remote", "add", "origin", "git@ssh.dev.azure.com:v3/myorg/myproject/myrepo")
+	cmd.Dir = repoDir
+	require.NoError(t, cmd.Run())
+
+	info, err := GetAzureDevOpsRemote(context.Background(), repoDir)
+	require.NoError(t, err)
+	assert.Equal(t, "myorg", info.Organization)
+	assert.Equal(t, "myproject", info.Project)
+	assert.Equal(t, "myrepo", info.Repository)
+}